@@ -14,7 +14,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -36,6 +38,8 @@ const (
 	recoveryTokenBytes  = 24
 	challengeIDBytes    = 24
 	sessionIDTokenBytes = 24
+	// maxSessionUserAgent bounds the User-Agent kept with each session.
+	maxSessionUserAgent = 256
 )
 
 var (
@@ -44,20 +48,23 @@ var (
 	// ErrChallengeNotFound indicates the challenge was missing, expired, or already used.
 	ErrChallengeNotFound                  = errors.New("auth challenge not found")
 	errConfigMissingRPID                  = errors.New("auth config missing RPID")
-	errConfigMissingRPOrigin              = errors.New("auth config missing RPOrigin")
+	errConfigMissingRPOrigin              = errors.New("auth config missing RPOrigins")
+	errConfigInvalidRPOrigin              = errors.New("auth config has invalid RP origin")
 	errInvalidPasskeyUserType             = errors.New("invalid passkey user type")
 	errMissingPasskeyCredentialID         = errors.New("passkey assertion missing credential id")
 	errRegistrationChallengeMissingUserID = errors.New("registration challenge missing user id")
 )
 
-// Config controls the passkey authentication service.
+// Config controls the passkey authentication service. RPOrigins lists every
+// origin the site is reached at; each must be RPID or a subdomain of it,
+// since passkeys are bound to the RPID rather than to one origin.
 type Config struct {
 	RPID         string
-	RPOrigin     string
 	RPName       string
 	CookieName   string
 	SessionTTL   time.Duration
 	ChallengeTTL time.Duration
+	RPOrigins    []string
 	CookieSecure bool
 }
 
@@ -76,6 +83,21 @@ type SessionIssue struct {
 	UserID      int64
 }
 
+// SessionClient identifies the client a session is issued to.
+type SessionClient struct {
+	IP        string
+	UserAgent string
+}
+
+// SessionInfo describes an active session for the security page.
+type SessionInfo struct {
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	SessionID  string
+	IP         string
+	UserAgent  string
+}
+
 // LoginBeginResult contains WebAuthn options plus challenge handle.
 type LoginBeginResult struct {
 	Assertion   *protocol.CredentialAssertion
@@ -118,8 +140,9 @@ func NewManager(db *sql.DB, cfg *Config) (*Manager, error) {
 		return nil, errConfigMissingRPID
 	}
 
-	if strings.TrimSpace(cfg.RPOrigin) == "" {
-		return nil, errConfigMissingRPOrigin
+	origins, err := validateRPOrigins(cfg.RPID, cfg.RPOrigins)
+	if err != nil {
+		return nil, err
 	}
 
 	selection := protocol.AuthenticatorSelection{
@@ -132,7 +155,7 @@ func NewManager(db *sql.DB, cfg *Config) (*Manager, error) {
 	webAuthnConfig := new(webauthn.Config)
 	webAuthnConfig.RPID = cfg.RPID
 	webAuthnConfig.RPDisplayName = cfg.RPName
-	webAuthnConfig.RPOrigins = []string{cfg.RPOrigin}
+	webAuthnConfig.RPOrigins = origins
 	webAuthnConfig.AttestationPreference = protocol.PreferNoAttestation
 	webAuthnConfig.AuthenticatorSelection = selection
 
@@ -149,6 +172,63 @@ func NewManager(db *sql.DB, cfg *Config) (*Manager, error) {
 	}, nil
 }
 
+// validateRPOrigins trims the configured origins and checks each is a bare
+// https origin (http only for localhost) whose host is rpID or a subdomain of
+// it, which browsers require before they will use a passkey for rpID there.
+func validateRPOrigins(rpID string, rawOrigins []string) ([]string, error) {
+	rpID = strings.ToLower(strings.TrimSpace(rpID))
+	origins := make([]string, 0, len(rawOrigins))
+
+	for _, raw := range rawOrigins {
+		origin := strings.TrimSpace(raw)
+		if origin == "" {
+			continue
+		}
+
+		parsed, err := url.Parse(origin)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", errConfigInvalidRPOrigin, origin, err)
+		}
+
+		host := strings.ToLower(parsed.Hostname())
+		secure := parsed.Scheme == "https" || (parsed.Scheme == "http" && isLoopbackHost(host))
+		bare := parsed.User == nil && strings.TrimSuffix(parsed.Path, "/") == "" &&
+			parsed.RawQuery == "" && parsed.Fragment == ""
+
+		if !secure || !bare || host == "" {
+			return nil, fmt.Errorf(
+				"%w %q: must be a scheme and host such as https://%s", errConfigInvalidRPOrigin, origin, rpID,
+			)
+		}
+
+		if host != rpID && !strings.HasSuffix(host, "."+rpID) {
+			return nil, fmt.Errorf(
+				"%w %q: host is not %s or a subdomain of it", errConfigInvalidRPOrigin, origin, rpID,
+			)
+		}
+
+		origins = append(origins, strings.TrimSuffix(origin, "/"))
+	}
+
+	if len(origins) == 0 {
+		return nil, errConfigMissingRPOrigin
+	}
+
+	return origins, nil
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP literal,
+// which browsers treat as a secure context over plain http.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
 // CredentialCount returns the registered passkey count.
 func (m *Manager) CredentialCount(ctx context.Context) (int, error) {
 	count, err := store.AuthCredentialCount(ctx, m.db)
@@ -210,6 +290,7 @@ func (m *Manager) FinishDiscoverableLogin(
 	ctx context.Context,
 	challengeID string,
 	r *http.Request,
+	client SessionClient,
 ) (SessionIssue, error) {
 	sessionData, _, err := m.consumeChallenge(ctx, challengeID, challengeFlowLogin)
 	if err != nil {
@@ -261,7 +342,7 @@ func (m *Manager) FinishDiscoverableLogin(
 		return SessionIssue{}, fmt.Errorf("update credential sign count: %w", updateErr)
 	}
 
-	return m.createSession(ctx, user.id, now)
+	return m.createSession(ctx, user.id, client, now)
 }
 
 // BeginRegistration starts a passkey registration ceremony for a known user.
@@ -349,8 +430,44 @@ func (m *Manager) FinishRegistration(
 }
 
 // CreateSessionForUser creates a new authenticated browser session.
-func (m *Manager) CreateSessionForUser(ctx context.Context, userID int64) (SessionIssue, error) {
-	return m.createSession(ctx, userID, time.Now().UTC())
+func (m *Manager) CreateSessionForUser(ctx context.Context, userID int64, client SessionClient) (SessionIssue, error) {
+	return m.createSession(ctx, userID, client, time.Now().UTC())
+}
+
+// ListSessions returns the user's active sessions, most recently seen first.
+func (m *Manager) ListSessions(ctx context.Context, userID int64) ([]SessionInfo, error) {
+	records, err := store.ListAuthSessions(ctx, m.db, userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list auth sessions: %w", err)
+	}
+
+	sessions := make([]SessionInfo, 0, len(records))
+	for _, record := range records {
+		sessions = append(sessions, SessionInfo{
+			CreatedAt:  record.CreatedAt,
+			LastSeenAt: record.LastSeenAt,
+			SessionID:  record.SessionID,
+			IP:         record.IP,
+			UserAgent:  record.UserAgent,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs out one of the user's sessions by ID. It returns
+// ErrInvalidSession when the user has no active session with that ID.
+func (m *Manager) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	revoked, err := store.RevokeUserAuthSession(ctx, m.db, userID, sessionID)
+	if err != nil {
+		return fmt.Errorf("revoke auth session: %w", err)
+	}
+
+	if !revoked {
+		return ErrInvalidSession
+	}
+
+	return nil
 }
 
 // ValidateSessionCookie validates and rolls forward an active session.
@@ -408,13 +525,18 @@ func (m *Manager) RevokeSessionCookie(ctx context.Context, cookieValue string) e
 }
 
 // RotateSession revokes an old session and issues a new one.
-func (m *Manager) RotateSession(ctx context.Context, oldCookieValue string, userID int64) (SessionIssue, error) {
+func (m *Manager) RotateSession(
+	ctx context.Context,
+	oldCookieValue string,
+	userID int64,
+	client SessionClient,
+) (SessionIssue, error) {
 	err := m.RevokeSessionCookie(ctx, oldCookieValue)
 	if err != nil {
 		return SessionIssue{}, err
 	}
 
-	return m.CreateSessionForUser(ctx, userID)
+	return m.CreateSessionForUser(ctx, userID, client)
 }
 
 // GenerateRecoveryCode issues a new single-use recovery code.
@@ -519,7 +641,12 @@ func (m *Manager) resolveLoginUser(ctx context.Context, rawID, userHandle []byte
 	return m.loadUserByID(ctx, credential.UserID)
 }
 
-func (m *Manager) createSession(ctx context.Context, userID int64, now time.Time) (SessionIssue, error) {
+func (m *Manager) createSession(
+	ctx context.Context,
+	userID int64,
+	client SessionClient,
+	now time.Time,
+) (SessionIssue, error) {
 	sessionID, err := randomToken(sessionIDTokenBytes)
 	if err != nil {
 		return SessionIssue{}, fmt.Errorf("generate session id: %w", err)
@@ -544,6 +671,8 @@ func (m *Manager) createSession(ctx context.Context, userID int64, now time.Time
 		CSRFToken:        csrfToken,
 		SessionTokenHash: sha256Bytes([]byte(token)),
 		UserID:           userID,
+		IP:               client.IP,
+		UserAgent:        truncateRunes(strings.TrimSpace(client.UserAgent), maxSessionUserAgent),
 	}
 
 	err = store.CreateAuthSession(ctx, m.db, &record)
@@ -826,6 +955,15 @@ func randomBytes(size int) ([]byte, error) {
 	return buf, nil
 }
 
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+
+	return string(runes[:limit])
+}
+
 func randomToken(size int) (string, error) {
 	raw, err := randomBytes(size)
 	if err != nil {
@@ -4,7 +4,9 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -41,9 +43,9 @@ func newTestManager(t *testing.T) *Manager {
 
 	manager, err := NewManager(db, &Config{
 		RPID:         testRPID,
-		RPOrigin:     testRPOrigin,
 		RPName:       testRPName,
 		CookieName:   "",
+		RPOrigins:    []string{testRPOrigin},
 		SessionTTL:   0,
 		ChallengeTTL: 0,
 		CookieSecure: false,
@@ -86,6 +88,60 @@ func seedOwnerCredential(t *testing.T, manager *Manager) (store.AuthUserRecord,
 	return owner, credentialID
 }
 
+func TestValidateRPOrigins(t *testing.T) {
+	t.Parallel()
+
+	origins, err := validateRPOrigins(testRPID, []string{
+		" https://example.com/ ", "https://rss.example.com:8443", "",
+	})
+	if err != nil {
+		t.Fatalf("validateRPOrigins: %v", err)
+	}
+
+	want := []string{"https://example.com", "https://rss.example.com:8443"}
+	if !slices.Equal(origins, want) {
+		t.Fatalf("expected trimmed origins %v, got %v", want, origins)
+	}
+
+	for _, origin := range []string{
+		"http://example.com",
+		"https://example.com/login",
+		"https://rss.tailnet.ts.net",
+		"https://notexample.com",
+	} {
+		_, err = validateRPOrigins(testRPID, []string{testRPOrigin, origin})
+		if !errors.Is(err, errConfigInvalidRPOrigin) {
+			t.Fatalf("expected %q to be rejected, got %v", origin, err)
+		}
+	}
+
+	_, err = validateRPOrigins(testRPID, []string{" "})
+	if !errors.Is(err, errConfigMissingRPOrigin) {
+		t.Fatalf("expected missing origins error, got %v", err)
+	}
+}
+
+func TestValidateRPOriginsAcceptsLoopbackHTTP(t *testing.T) {
+	t.Parallel()
+
+	for rpID, origin := range map[string]string{
+		"localhost": "http://localhost:8080",
+		"127.0.0.1": "http://127.0.0.1:8080",
+		"127.0.0.2": "http://127.0.0.2",
+		"::1":       "http://[::1]:8080",
+	} {
+		origins, err := validateRPOrigins(rpID, []string{origin})
+		if err != nil || len(origins) != 1 || origins[0] != origin {
+			t.Fatalf("expected loopback origin %q to be accepted, got %v (%v)", origin, origins, err)
+		}
+	}
+
+	_, err := validateRPOrigins("10.0.0.5", []string{"http://10.0.0.5:8080"})
+	if !errors.Is(err, errConfigInvalidRPOrigin) {
+		t.Fatalf("expected a plain-http LAN origin to be rejected, got %v", err)
+	}
+}
+
 func TestResolveLoginUserByHandle(t *testing.T) {
 	t.Parallel()
 
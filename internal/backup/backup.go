@@ -0,0 +1,235 @@
+// Package backup reads and writes the JSON document used to back up and
+// restore the whole reader: every feed with its settings, and every item with
+// its read state.
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+)
+
+// Version is the document format written by Write and accepted by Read.
+const Version = 1
+
+// Feed is one subscription with the settings a restore should reapply.
+type Feed struct {
+	URL                     string   `json:"url"`
+	Title                   string   `json:"title"`
+	CustomTitle             string   `json:"custom_title,omitempty"`
+	Description             string   `json:"description,omitempty"`
+	SiteURL                 string   `json:"site_url,omitempty"`
+	Language                string   `json:"language,omitempty"`
+	Tags                    []string `json:"tags,omitempty"`
+	UserAgent               string   `json:"user_agent,omitempty"`
+	HTTPProxy               string   `json:"http_proxy,omitempty"`
+	HTTPSOnly               bool     `json:"https_only,omitempty"`
+	SuppressDuplicateTitles bool     `json:"suppress_duplicate_titles,omitempty"`
+	SummarizeInList         bool     `json:"summarize_in_list,omitempty"`
+	StripLeadingImage       bool     `json:"strip_leading_image,omitempty"`
+	ImagesOnly              bool     `json:"images_only,omitempty"`
+	CacheEnclosures         bool     `json:"cache_enclosures,omitempty"`
+	SkipTombstones          bool     `json:"skip_tombstones,omitempty"`
+	SecretURL               bool     `json:"secret_url,omitempty"`
+	StableGUIDs             bool     `json:"stable_guids,omitempty"`
+	DigestItems             bool     `json:"digest_items,omitempty"`
+	Archived                bool     `json:"archived,omitempty"`
+	MinAgeMinutes           int      `json:"min_age_minutes,omitempty"`
+	SortByUpdated           bool     `json:"sort_by_updated,omitempty"`
+	ImageReferrer           string   `json:"image_referrer,omitempty"`
+	IngestToken             string   `json:"ingest_token,omitempty"`
+	Color                   string   `json:"color,omitempty"`
+	Timezone                string   `json:"timezone,omitempty"`
+}
+
+// Item is one stored entry, keyed for restore by its feed URL and GUID.
+type Item struct {
+	FeedURL     string     `json:"feed_url"`
+	GUID        string     `json:"guid"`
+	Title       string     `json:"title"`
+	Link        string     `json:"link"`
+	Summary     string     `json:"summary,omitempty"`
+	Content     string     `json:"content,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+}
+
+// ErrInvalidDocument reports input that is not a backup document this
+// version understands.
+var ErrInvalidDocument = errors.New("invalid backup document")
+
+var errUnsupportedVersion = fmt.Errorf("%w: unsupported version", ErrInvalidDocument)
+
+// Write streams a backup document to w. Items are encoded one at a time as
+// the sequence yields them, so callers can page through a large database
+// without holding every item in memory. An error yielded by items stops the
+// write and is returned.
+func Write(w io.Writer, exportedAt time.Time, feeds []Feed, items iter.Seq2[Item, error]) error {
+	header, err := json.Marshal(exportedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("encode backup time: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "{\"version\":%d,\"exported_at\":%s,\"feeds\":[", Version, header)
+	if err != nil {
+		return fmt.Errorf("write backup header: %w", err)
+	}
+
+	for idx, feed := range feeds {
+		err = writeElement(w, idx, feed)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "],\"items\":[")
+	if err != nil {
+		return fmt.Errorf("write backup items: %w", err)
+	}
+
+	idx := 0
+
+	for item, itemErr := range items {
+		if itemErr != nil {
+			return itemErr
+		}
+
+		err = writeElement(w, idx, item)
+		if err != nil {
+			return err
+		}
+
+		idx++
+	}
+
+	_, err = io.WriteString(w, "]}\n")
+	if err != nil {
+		return fmt.Errorf("write backup trailer: %w", err)
+	}
+
+	return nil
+}
+
+func writeElement(w io.Writer, idx int, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode backup entry: %w", err)
+	}
+
+	if idx > 0 {
+		encoded = append([]byte{','}, encoded...)
+	}
+
+	encoded = append(encoded, '\n')
+
+	_, err = w.Write(encoded)
+	if err != nil {
+		return fmt.Errorf("write backup entry: %w", err)
+	}
+
+	return nil
+}
+
+// Read decodes a backup document from r, calling onFeed and onItem for each
+// entry in document order without buffering the arrays. The version must come
+// first so nothing is restored from a format this package does not know, and
+// documents written by Write list every feed before any item. Errors returned
+// by the callbacks stop the read and are returned unchanged.
+func Read(r io.Reader, onFeed func(*Feed) error, onItem func(*Item) error) error {
+	decoder := json.NewDecoder(r)
+
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return err
+	}
+
+	sawVersion := false
+
+	for decoder.More() {
+		key, tokenErr := decoder.Token()
+		if tokenErr != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidDocument, tokenErr)
+		}
+
+		if key != "version" && !sawVersion {
+			return errUnsupportedVersion
+		}
+
+		switch key {
+		case "version":
+			var version int
+
+			err = decoder.Decode(&version)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+			}
+
+			if version != Version {
+				return errUnsupportedVersion
+			}
+
+			sawVersion = true
+		case "feeds":
+			err = readArray(decoder, onFeed)
+		case "items":
+			err = readArray(decoder, onItem)
+		default:
+			var skipped json.RawMessage
+
+			err = decoder.Decode(&skipped)
+			if err != nil {
+				err = fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if !sawVersion {
+		return errUnsupportedVersion
+	}
+
+	return expectDelim(decoder, '}')
+}
+
+func readArray[T any](decoder *json.Decoder, handle func(*T) error) error {
+	err := expectDelim(decoder, '[')
+	if err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		var entry T
+
+		err = decoder.Decode(&entry)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+		}
+
+		err = handle(&entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+
+	if token != want {
+		return fmt.Errorf("%w: expected %q", ErrInvalidDocument, want)
+	}
+
+	return nil
+}
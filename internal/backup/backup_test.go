@@ -0,0 +1,99 @@
+package backup_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"rss/internal/backup"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	readAt := time.Date(2026, time.March, 2, 9, 30, 0, 0, time.UTC)
+	feeds := []backup.Feed{
+		{URL: "https://example.com/a.xml", Title: "A", Tags: []string{"news"}, HTTPSOnly: true},
+		{URL: "mailbox:token", Title: "Letters", IngestToken: "token"},
+	}
+	items := []backup.Item{
+		{FeedURL: "https://example.com/a.xml", GUID: "a-1", Title: "One", Link: "https://example.com/1", ReadAt: &readAt},
+		{FeedURL: "mailbox:token", GUID: "m-1", Title: "Issue", Content: "<p>Hi</p>"},
+	}
+
+	var buf bytes.Buffer
+
+	err := backup.Write(&buf, readAt, feeds, func(yield func(backup.Item, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var (
+		gotFeeds []backup.Feed
+		gotItems []backup.Item
+	)
+
+	err = backup.Read(&buf, func(feed *backup.Feed) error {
+		gotFeeds = append(gotFeeds, *feed)
+
+		return nil
+	}, func(item *backup.Item) error {
+		gotItems = append(gotItems, *item)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if len(gotFeeds) != len(feeds) || gotFeeds[1].IngestToken != "token" || !gotFeeds[0].HTTPSOnly {
+		t.Fatalf("unexpected feeds %+v", gotFeeds)
+	}
+
+	if !slices.Equal(gotFeeds[0].Tags, []string{"news"}) {
+		t.Fatalf("expected tags to round trip, got %v", gotFeeds[0].Tags)
+	}
+
+	if len(gotItems) != len(items) || gotItems[1].Content != "<p>Hi</p>" {
+		t.Fatalf("unexpected items %+v", gotItems)
+	}
+
+	if gotItems[0].ReadAt == nil || !gotItems[0].ReadAt.Equal(readAt) || gotItems[1].ReadAt != nil {
+		t.Fatalf("expected read state to round trip, got %v and %v", gotItems[0].ReadAt, gotItems[1].ReadAt)
+	}
+}
+
+func TestReadRejectsUnknownDocuments(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"not json":        "<opml/>",
+		"missing version": `{"feeds":[{"url":"https://example.com/a.xml"}]}`,
+		"future version":  `{"version":99,"feeds":[]}`,
+		"truncated":       `{"version":1,"feeds":[{"url":"https://example.com/a.xml"}`,
+	}
+
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := backup.Read(strings.NewReader(input), func(*backup.Feed) error {
+				return nil
+			}, func(*backup.Item) error {
+				return nil
+			})
+			if !errors.Is(err, backup.ErrInvalidDocument) {
+				t.Fatalf("expected ErrInvalidDocument, got %v", err)
+			}
+		})
+	}
+}
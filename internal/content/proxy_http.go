@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 )
@@ -35,6 +36,29 @@ func NewHTTPClient() *http.Client {
 	return client
 }
 
+// NewHTTPSUpgradeClient returns the client for trying an http image over
+// https first. It gives up on connecting and the TLS handshake after
+// ImageProxyUpgradeDialTimeout rather than the whole proxy timeout.
+func NewHTTPSUpgradeClient() *http.Client {
+	dialer := new(net.Dialer)
+	dialer.Timeout = ImageProxyUpgradeDialTimeout
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if ok {
+		transport = transport.Clone()
+	} else {
+		transport = new(http.Transport)
+	}
+
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = ImageProxyUpgradeDialTimeout
+
+	client := NewHTTPClient()
+	client.Transport = transport
+
+	return client
+}
+
 // BuildImageProxyRequest builds an image-proxy request for a target URL.
 func BuildImageProxyRequest(
 	ctx context.Context,
@@ -53,8 +77,18 @@ func BuildImageProxyRequest(
 	req.Header.Set("User-Agent", ImageProxyUserAgent)
 	req.Header.Set(
 		"Accept",
-		"image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8",
+		"image/avif,image/webp,image/png,image/jpeg,image/gif,*/*;q=0.5",
 	)
 
 	return req, nil
 }
+
+// CopyConditionalHeaders forwards a client's cache validators to an upstream
+// image request so the upstream can answer with 304 Not Modified.
+func CopyConditionalHeaders(dst, src http.Header) {
+	for _, name := range []string{"If-None-Match", "If-Modified-Since"} {
+		if value := src.Get(name); value != "" {
+			dst.Set(name, value)
+		}
+	}
+}
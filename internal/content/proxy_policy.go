@@ -1,17 +1,40 @@
 package content
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"net"
 	"net/url"
 	"strings"
 )
 
+const (
+	webpHeaderBytes = 12
+	isoBrandBytes   = 4
+	isoFtypMinBytes = 16
+)
+
+//nolint:gochecknoglobals // Fixed magic-byte signatures for allowlisted raster formats.
+var (
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	jpegSignature = []byte{0xFF, 0xD8, 0xFF}
+	gif87aMagic   = []byte("GIF87a")
+	gif89aMagic   = []byte("GIF89a")
+	riffMagic     = []byte("RIFF")
+	webpMagic     = []byte("WEBP")
+	ftypMagic     = []byte("ftyp")
+)
+
 // LookupIPAddrFunc resolves a host name to one or more IP addresses.
 type LookupIPAddrFunc func(context.Context, string) ([]net.IPAddr, error)
 
 // ProxyImageURL rewrites a URL to the local image-proxy endpoint when allowed.
 func ProxyImageURL(rawURL string, base *url.URL) (string, bool) {
+	return proxyImageURL(rawURL, base, ImageReferrerNone)
+}
+
+func proxyImageURL(rawURL string, base *url.URL, referrer string) (string, bool) {
 	parsed, ok := parseProxyURL(rawURL, base)
 	if !ok {
 		return rawURL, false
@@ -25,7 +48,78 @@ func ProxyImageURL(rawURL string, base *url.URL) (string, bool) {
 		return rawURL, false
 	}
 
-	return ImageProxyPath + "?url=" + url.QueryEscape(parsed.String()), true
+	target := parsed.String()
+
+	return ImageProxyPath + "?url=" + url.QueryEscape(target) + imageProxyQuery(referrer, target), true
+}
+
+// UpgradeToHTTPS returns an https copy of a plain-http URL, dropping an explicit
+// default port. It reports false for URLs that are not plain http.
+func UpgradeToHTTPS(target *url.URL) (*url.URL, bool) {
+	if target == nil || target.Scheme != "http" {
+		return nil, false
+	}
+
+	upgraded := *target
+	upgraded.Scheme = "https"
+
+	if upgraded.Port() == "80" {
+		upgraded.Host = strings.TrimSuffix(upgraded.Host, ":80")
+	}
+
+	return &upgraded, true
+}
+
+// DetectImageContentType identifies an allowlisted raster image format from its
+// leading bytes. SVG and anything else without a known raster signature is
+// rejected, since proxied SVG could carry script.
+func DetectImageContentType(sniff []byte) (string, bool) {
+	switch {
+	case bytes.HasPrefix(sniff, pngSignature):
+		return "image/png", true
+	case bytes.HasPrefix(sniff, jpegSignature):
+		return "image/jpeg", true
+	case bytes.HasPrefix(sniff, gif87aMagic), bytes.HasPrefix(sniff, gif89aMagic):
+		return "image/gif", true
+	case isWebP(sniff):
+		return "image/webp", true
+	case isAVIF(sniff):
+		return "image/avif", true
+	default:
+		return "", false
+	}
+}
+
+func isWebP(sniff []byte) bool {
+	return len(sniff) >= webpHeaderBytes &&
+		bytes.HasPrefix(sniff, riffMagic) &&
+		bytes.Equal(sniff[8:webpHeaderBytes], webpMagic)
+}
+
+func isAVIF(sniff []byte) bool {
+	if len(sniff) < isoFtypMinBytes || !bytes.Equal(sniff[4:8], ftypMagic) {
+		return false
+	}
+
+	boxSize := int(binary.BigEndian.Uint32(sniff[:4]))
+	boxSize = min(max(boxSize, isoFtypMinBytes), len(sniff))
+
+	if isAVIFBrand(sniff[8:12]) {
+		return true
+	}
+
+	// Compatible brands follow the major brand and minor version.
+	for offset := isoFtypMinBytes; offset+isoBrandBytes <= boxSize; offset += isoBrandBytes {
+		if isAVIFBrand(sniff[offset : offset+isoBrandBytes]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAVIFBrand(brand []byte) bool {
+	return string(brand) == "avif" || string(brand) == "avis"
 }
 
 // IsAllowedProxyURL reports whether a URL is safe for image proxying.
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net"
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -96,6 +97,53 @@ func TestIsAllowedResolvedProxyURL(t *testing.T) {
 	}
 }
 
+func TestDetectImageContentType(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		sniff  []byte
+		want   string
+		wantOK bool
+	}{
+		{name: "png", sniff: []byte("\x89PNG\r\n\x1a\nrest"), want: "image/png", wantOK: true},
+		{name: "jpeg", sniff: []byte{0xFF, 0xD8, 0xFF, 0xE0}, want: "image/jpeg", wantOK: true},
+		{name: "gif", sniff: []byte("GIF89a..."), want: "image/gif", wantOK: true},
+		{name: "webp", sniff: []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), want: "image/webp", wantOK: true},
+		{
+			name:   "avif major brand",
+			sniff:  []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf"),
+			want:   "image/avif",
+			wantOK: true,
+		},
+		{
+			name:   "avif compatible brand",
+			sniff:  []byte("\x00\x00\x00\x1cftypmif1\x00\x00\x00\x00mif1avifmiaf"),
+			want:   "image/avif",
+			wantOK: true,
+		},
+		{name: "heic", sniff: []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic"), wantOK: false},
+		{
+			name:   "svg",
+			sniff:  []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`),
+			wantOK: false,
+		},
+		{name: "html", sniff: []byte("<!doctype html><html></html>"), wantOK: false},
+		{name: "empty", sniff: nil, wantOK: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := DetectImageContentType(tc.sniff)
+			if ok != tc.wantOK || got != tc.want {
+				t.Fatalf("DetectImageContentType() = (%q, %v), want (%q, %v)", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
 func TestProxyImageURL(t *testing.T) {
 	t.Parallel()
 
@@ -110,8 +158,8 @@ func TestProxyImageURL(t *testing.T) {
 	}
 
 	want := "/image-proxy?url=https%3A%2F%2Fexample.com%2Fposts%2Fimages" +
-		"%2Fa.jpg"
-	if got != want {
+		"%2Fa.jpg&sig="
+	if !strings.HasPrefix(got, want) {
 		t.Fatalf("got %q, want %q", got, want)
 	}
 
@@ -174,3 +222,39 @@ func assertProxyURLBlocked(t *testing.T, raw string) {
 		)
 	}
 }
+
+func TestUpgradeToHTTPS(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"http://example.com/image.png":    exampleImageURL,
+		"http://example.com:80/image.png": exampleImageURL,
+		"http://example.com:8080/a.png":   "https://example.com:8080/a.png",
+		"http://[2001:db8::1]:80/a.png":   "https://[2001:db8::1]/a.png",
+	}
+
+	for raw, want := range cases {
+		target, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("url.Parse(%q): %v", raw, err)
+		}
+
+		upgraded, ok := UpgradeToHTTPS(target)
+		if !ok || upgraded.String() != want {
+			t.Fatalf("UpgradeToHTTPS(%q) = %v, %v; want %q", raw, upgraded, ok, want)
+		}
+
+		if target.Scheme != "http" {
+			t.Fatalf("UpgradeToHTTPS(%q) mutated its input", raw)
+		}
+	}
+
+	secure, err := url.Parse(exampleImageURL)
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+
+	if _, ok := UpgradeToHTTPS(secure); ok {
+		t.Fatal("expected https URL not to be upgraded")
+	}
+}
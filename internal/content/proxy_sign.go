@@ -0,0 +1,91 @@
+package content
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sync/atomic"
+)
+
+const (
+	imageReferrerParam  = "referrer"
+	imageSignatureParam = "sig"
+)
+
+// imageProxyKey signs the target URL and referrer policy of every proxy URL
+// the rewriter produces, so the proxy only fetches images this server linked
+// to and cannot be used as an open relay. Without SetImageProxySecret the key
+// is random per process, and URLs signed by an earlier process are refused.
+//
+//nolint:gochecknoglobals // Signing key shared by URL rewriting and the proxy handler.
+var imageProxyKey = newImageProxyKey()
+
+func newImageProxyKey() *atomic.Pointer[[]byte] {
+	key := make([]byte, sha256.Size)
+
+	_, err := rand.Read(key)
+	if err != nil {
+		panic("content: read image proxy key: " + err.Error())
+	}
+
+	pointer := new(atomic.Pointer[[]byte])
+	pointer.Store(&key)
+
+	return pointer
+}
+
+// SetImageProxySecret derives the image proxy signing key from secret, so
+// proxy URLs stay valid across restarts. An empty secret keeps the current key.
+func SetImageProxySecret(secret string) {
+	if secret == "" {
+		return
+	}
+
+	sum := sha256.Sum256([]byte("image-proxy\n" + secret))
+	key := sum[:]
+	imageProxyKey.Store(&key)
+}
+
+// VerifyImageProxyQuery checks the signature on an image proxy query for
+// rawURL and returns the referrer policy signed with it. It reports false
+// when the query is unsigned, its signature does not match, or its policy is
+// unknown.
+func VerifyImageProxyQuery(query url.Values, rawURL string) (string, bool) {
+	policy, ok := NormalizeImageReferrer(query.Get(imageReferrerParam))
+	if !ok {
+		return "", false
+	}
+
+	signature, err := hex.DecodeString(query.Get(imageSignatureParam))
+	if err != nil || !hmac.Equal(signature, signImageProxyURL(policy, rawURL)) {
+		return "", false
+	}
+
+	return policy, true
+}
+
+// imageProxyQuery returns the query parameters after url= for a proxy URL:
+// the referrer policy, when it sends one, and the signature.
+func imageProxyQuery(policy, rawURL string) string {
+	query := ""
+	if policy != ImageReferrerOrigin && policy != ImageReferrerFull {
+		policy = ImageReferrerNone
+	} else {
+		query = "&" + imageReferrerParam + "=" + policy
+	}
+
+	return query + "&" + imageSignatureParam + "=" + hex.EncodeToString(signImageProxyURL(policy, rawURL))
+}
+
+func signImageProxyURL(policy, rawURL string) []byte {
+	mac := hmac.New(sha256.New, *imageProxyKey.Load())
+
+	_, err := mac.Write([]byte(policy + "\n" + rawURL))
+	if err != nil {
+		return nil
+	}
+
+	return mac.Sum(nil)
+}
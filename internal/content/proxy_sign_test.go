@@ -0,0 +1,64 @@
+//nolint:testpackage // Content tests exercise package-internal helpers directly.
+package content
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRewriteSummaryHTMLSignsImageProxyURL(t *testing.T) {
+	t.Parallel()
+
+	query := rewrittenImageProxyQuery(t, ImageReferrerOrigin)
+	if got, ok := VerifyImageProxyQuery(query, query.Get("url")); !ok || got != ImageReferrerOrigin {
+		t.Fatalf("expected signed origin policy, got %q, %v", got, ok)
+	}
+
+	tampered := rewrittenImageProxyQuery(t, ImageReferrerOrigin)
+	tampered.Set(imageReferrerParam, ImageReferrerFull)
+
+	if _, ok := VerifyImageProxyQuery(tampered, tampered.Get("url")); ok {
+		t.Fatal("expected tampered policy to be rejected")
+	}
+
+	if _, ok := VerifyImageProxyQuery(query, "https://example.com/other.png"); ok {
+		t.Fatal("expected signature bound to the image url")
+	}
+
+	query = rewrittenImageProxyQuery(t, ImageReferrerNone)
+	if query.Has(imageReferrerParam) {
+		t.Fatalf("expected no referrer policy in %v", query)
+	}
+
+	if got, ok := VerifyImageProxyQuery(query, query.Get("url")); !ok || got != ImageReferrerNone {
+		t.Fatalf("expected signed url without a referrer policy, got %q, %v", got, ok)
+	}
+
+	query.Del(imageSignatureParam)
+
+	if _, ok := VerifyImageProxyQuery(query, query.Get("url")); ok {
+		t.Fatal("expected unsigned url to be rejected")
+	}
+}
+
+func rewrittenImageProxyQuery(t *testing.T, referrer string) url.Values {
+	t.Helper()
+
+	output := RewriteSummaryHTML(`<img src="`+exampleImageURL+`">`, "", referrer)
+
+	_, src, ok := strings.Cut(output, `src="`)
+	if !ok {
+		t.Fatalf("expected proxied image, got %q", output)
+	}
+
+	src, _, _ = strings.Cut(src, `"`)
+
+	proxied, err := url.Parse(html.UnescapeString(src))
+	if err != nil {
+		t.Fatalf("parse proxied src: %v", err)
+	}
+
+	return proxied.Query()
+}
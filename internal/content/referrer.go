@@ -0,0 +1,45 @@
+package content
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Image referrer policies a feed can choose for its proxied images. The proxy
+// sends no Referer under ImageReferrerNone, the default; ImageReferrerOrigin
+// sends the image's scheme://host/ and ImageReferrerFull its whole URL, for
+// CDNs that refuse to serve images without one.
+const (
+	ImageReferrerNone   = "none"
+	ImageReferrerOrigin = "origin"
+	ImageReferrerFull   = "full"
+)
+
+// NormalizeImageReferrer reports whether raw names an image referrer policy,
+// returning ImageReferrerNone for an empty value.
+func NormalizeImageReferrer(raw string) (string, bool) {
+	switch policy := strings.ToLower(strings.TrimSpace(raw)); policy {
+	case "", ImageReferrerNone:
+		return ImageReferrerNone, true
+	case ImageReferrerOrigin, ImageReferrerFull:
+		return policy, true
+	default:
+		return "", false
+	}
+}
+
+// SetImageReferrer sets the Referer header policy calls for on an upstream
+// image request for target.
+func SetImageReferrer(header http.Header, target *url.URL, policy string) {
+	switch policy {
+	case ImageReferrerOrigin:
+		header.Set("Referer", target.Scheme+"://"+target.Host+"/")
+	case ImageReferrerFull:
+		referer := *target
+		referer.User = nil
+		referer.Fragment = ""
+		header.Set("Referer", referer.String())
+	default:
+	}
+}
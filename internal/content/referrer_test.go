@@ -0,0 +1,46 @@
+//nolint:testpackage // Content tests exercise package-internal helpers directly.
+package content
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestSetImageReferrer(t *testing.T) {
+	t.Parallel()
+
+	target, err := url.Parse("https://user@cdn.example.com/images/a.png?w=100#top")
+	if err != nil {
+		t.Fatalf("parse target: %v", err)
+	}
+
+	cases := map[string]string{
+		ImageReferrerNone:   "",
+		ImageReferrerOrigin: "https://cdn.example.com/",
+		ImageReferrerFull:   "https://cdn.example.com/images/a.png?w=100",
+	}
+
+	for policy, expected := range cases {
+		header := http.Header{}
+		SetImageReferrer(header, target, policy)
+
+		if got := header.Get("Referer"); got != expected {
+			t.Fatalf("policy %q: expected Referer %q, got %q", policy, expected, got)
+		}
+	}
+}
+
+func TestNormalizeImageReferrer(t *testing.T) {
+	t.Parallel()
+
+	for raw, expected := range map[string]string{"": ImageReferrerNone, " Origin ": ImageReferrerOrigin} {
+		if got, ok := NormalizeImageReferrer(raw); !ok || got != expected {
+			t.Fatalf("NormalizeImageReferrer(%q) = %q, %v", raw, got, ok)
+		}
+	}
+
+	if _, ok := NormalizeImageReferrer("strict"); ok {
+		t.Fatal("expected unknown policy to be rejected")
+	}
+}
@@ -18,6 +18,10 @@ const (
 	ImageProxyMaxBodyBytes = 10 << 20
 	// ImageProxyTimeout is the timeout used by image proxy upstream requests.
 	ImageProxyTimeout = 15 * time.Second
+	// ImageProxyUpgradeDialTimeout bounds connecting and the TLS handshake
+	// when an http image is tried over https first, so a host that drops
+	// port 443 leaves the http fallback most of ImageProxyTimeout.
+	ImageProxyUpgradeDialTimeout = 3 * time.Second
 	// ImageProxyCacheFallback is used when upstream omits cache directives.
 	ImageProxyCacheFallback = "public, max-age=86400"
 	// ImageProxyUserAgent identifies proxy requests to upstream servers.
@@ -41,7 +45,9 @@ type relAttrLookup struct {
 }
 
 // RewriteSummaryHTML rewrites summary HTML image and anchor URLs when possible.
-func RewriteSummaryHTML(text, baseURLRaw string) string {
+// Proxied images carry the signed image referrer policy, one of the
+// ImageReferrer values.
+func RewriteSummaryHTML(text, baseURLRaw, referrer string) string {
 	base := parseSummaryBaseURL(baseURLRaw)
 
 	if !containsRewriteTargets(text) {
@@ -53,7 +59,7 @@ func RewriteSummaryHTML(text, baseURLRaw string) string {
 		return text
 	}
 
-	if !rewriteSummaryNodes(nodes, base) {
+	if !rewriteSummaryNodes(nodes, base, referrer) {
 		return text
 	}
 
@@ -65,6 +71,166 @@ func RewriteSummaryHTML(text, baseURLRaw string) string {
 	return rewritten
 }
 
+// HasImage reports whether an HTML fragment contains an <img> element.
+// Mentions of "<img" inside text or attribute values do not count.
+func HasImage(text string) bool {
+	if !strings.Contains(strings.ToLower(text), "<img") {
+		return false
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(text))
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			if atom.Lookup(name) == atom.Img {
+				return true
+			}
+		default:
+		}
+	}
+}
+
+// FirstImageURL returns the src of an HTML fragment's first <img>, or "" when
+// it has none.
+func FirstImageURL(text string) string {
+	if !strings.Contains(strings.ToLower(text), "<img") {
+		return ""
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(text))
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.DataAtom != atom.Img {
+				continue
+			}
+
+			for _, attr := range token.Attr {
+				if attr.Key == "src" && strings.TrimSpace(attr.Val) != "" {
+					return strings.TrimSpace(attr.Val)
+				}
+			}
+		default:
+		}
+	}
+}
+
+// ProxiedImageURL returns the image-proxy URL for a standalone image such as
+// an item thumbnail, resolving rawURL against baseURL. It returns "" when the
+// image cannot be proxied, since remote images are never loaded directly.
+func ProxiedImageURL(rawURL, baseURL, referrer string) string {
+	if strings.TrimSpace(rawURL) == "" {
+		return ""
+	}
+
+	proxied, ok := proxyImageURL(rawURL, parseSummaryBaseURL(baseURL), referrer)
+	if !ok {
+		return ""
+	}
+
+	return proxied
+}
+
+// StripLeadingImage removes an HTML fragment's first <img> when it comes before
+// any text or other media, such as a hero image repeated atop every post.
+// Wrappers the removal leaves empty, like the link or paragraph around the
+// image, go with it. Images further into the body are never touched.
+func StripLeadingImage(text string) string {
+	if !strings.Contains(text, "<img") {
+		return text
+	}
+
+	nodes, ok := parseSummaryFragment(text)
+	if !ok {
+		return text
+	}
+
+	root := new(html.Node)
+	root.Type = html.ElementNode
+	root.DataAtom = atom.Div
+	root.Data = "div"
+
+	for _, node := range nodes {
+		root.AppendChild(node)
+	}
+
+	img, _ := findLeadingImage(root)
+	if img == nil {
+		return text
+	}
+
+	parent := img.Parent
+	parent.RemoveChild(img)
+
+	for parent != root && isEmptyWrapper(parent) {
+		next := parent.Parent
+		next.RemoveChild(parent)
+		parent = next
+	}
+
+	stripped, ok := renderSummaryNodes(detachChildren(root))
+	if !ok {
+		return text
+	}
+
+	return stripped
+}
+
+// findLeadingImage walks the fragment in document order and returns its first
+// <img>, or done=true once text or other media shows up first.
+func findLeadingImage(node *html.Node) (*html.Node, bool) {
+	switch node.Type {
+	case html.TextNode:
+		return nil, strings.TrimSpace(node.Data) != ""
+	case html.ElementNode:
+		switch node.DataAtom {
+		case atom.Img:
+			return node, true
+		case atom.Video, atom.Audio, atom.Iframe, atom.Object, atom.Embed, atom.Svg:
+			return nil, true
+		default:
+		}
+	default:
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		img, done := findLeadingImage(child)
+		if done {
+			return img, true
+		}
+	}
+
+	return nil, false
+}
+
+// isEmptyWrapper reports whether node holds nothing visible: no text and no
+// elements besides line breaks and leftover <picture> sources.
+func isEmptyWrapper(node *html.Node) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		switch child.Type {
+		case html.TextNode:
+			if strings.TrimSpace(child.Data) != "" {
+				return false
+			}
+		case html.ElementNode:
+			if child.DataAtom != atom.Br && child.DataAtom != atom.Source && !isEmptyWrapper(child) {
+				return false
+			}
+		default:
+		}
+	}
+
+	return true
+}
+
 func parseSummaryFragment(text string) ([]*html.Node, bool) {
 	root := new(html.Node)
 	root.Type = html.ElementNode
@@ -79,11 +245,11 @@ func parseSummaryFragment(text string) ([]*html.Node, bool) {
 	return nodes, true
 }
 
-func rewriteSummaryNodes(nodes []*html.Node, base *url.URL) bool {
+func rewriteSummaryNodes(nodes []*html.Node, base *url.URL, referrer string) bool {
 	changed := false
 
 	for _, node := range nodes {
-		if rewriteSummaryNode(node, base) {
+		if rewriteSummaryNode(node, base, referrer) {
 			changed = true
 		}
 	}
@@ -103,14 +269,14 @@ func renderSummaryNodes(nodes []*html.Node) (string, bool) {
 	return b.String(), true
 }
 
-func rewriteSummaryNode(node *html.Node, base *url.URL) bool {
+func rewriteSummaryNode(node *html.Node, base *url.URL, referrer string) bool {
 	changed := false
 	if node.Type == html.ElementNode {
-		changed = rewriteSummaryElement(node, base)
+		changed = rewriteSummaryElement(node, base, referrer)
 	}
 
 	for child := node.FirstChild; child != nil; child = child.NextSibling {
-		if rewriteSummaryNode(child, base) {
+		if rewriteSummaryNode(child, base, referrer) {
 			changed = true
 		}
 	}
@@ -118,13 +284,13 @@ func rewriteSummaryNode(node *html.Node, base *url.URL) bool {
 	return changed
 }
 
-func rewriteSummaryElement(node *html.Node, base *url.URL) bool {
+func rewriteSummaryElement(node *html.Node, base *url.URL, referrer string) bool {
 	switch node.Data {
 	case "img":
-		return rewriteSummaryImageNode(node, base)
+		return rewriteSummaryImageNode(node, base, referrer)
 	case "source":
 		return rewriteAttr(node, "srcset", func(value string) (string, bool) {
-			return rewriteSrcset(value, base)
+			return rewriteSrcset(value, base, referrer)
 		})
 	case "a":
 		return rewriteSummaryAnchorNode(node, base)
@@ -133,13 +299,13 @@ func rewriteSummaryElement(node *html.Node, base *url.URL) bool {
 	}
 }
 
-func rewriteSummaryImageNode(node *html.Node, base *url.URL) bool {
+func rewriteSummaryImageNode(node *html.Node, base *url.URL, referrer string) bool {
 	changed := rewriteAttr(node, "src", func(value string) (string, bool) {
-		return ProxyImageURL(value, base)
+		return proxyImageURL(value, base, referrer)
 	})
 
 	if rewriteAttr(node, "srcset", func(value string) (string, bool) {
-		return rewriteSrcset(value, base)
+		return rewriteSrcset(value, base, referrer)
 	}) {
 		changed = true
 	}
@@ -3,6 +3,7 @@ package content
 
 import (
 	"context"
+	"html"
 	"net/url"
 	"strings"
 	"testing"
@@ -17,8 +18,10 @@ const (
 		"media.s3.amazonaws.com%2Fpublic%2Fimages%2Fa.png"
 )
 
+// proxied returns the signed proxy URL for raw as it appears in an HTML
+// attribute.
 func proxied(raw string) string {
-	return ImageProxyPath + "?url=" + url.QueryEscape(raw)
+	return ImageProxyPath + "?url=" + url.QueryEscape(raw) + html.EscapeString(imageProxyQuery(ImageReferrerNone, raw))
 }
 
 func containsAll(text, first, second string) bool {
@@ -29,7 +32,7 @@ func TestRewriteSummaryHTMLImages(t *testing.T) {
 	t.Parallel()
 
 	input := `<p>Hello</p><img src="https://example.com/image.jpg" alt="x">`
-	output := RewriteSummaryHTML(input, "")
+	output := RewriteSummaryHTML(input, "", ImageReferrerNone)
 
 	expected := proxied("https://example.com/image.jpg")
 	if !strings.Contains(output, expected) {
@@ -42,7 +45,7 @@ func TestRewriteSummaryHTMLSrcset(t *testing.T) {
 
 	input := `<img srcset="https://example.com/a.jpg 1x, ` +
 		`https://example.com/b.jpg 2x" src="https://example.com/a.jpg">`
-	output := RewriteSummaryHTML(input, "")
+	output := RewriteSummaryHTML(input, "", ImageReferrerNone)
 	expectedA := proxied("https://example.com/a.jpg")
 
 	expectedB := proxied("https://example.com/b.jpg")
@@ -58,6 +61,7 @@ func TestRewriteSummaryHTMLForBaseRootRelativeImage(t *testing.T) {
 	output := RewriteSummaryHTML(
 		input,
 		"https://borretti.me/article/some-data-should-be-code",
+		ImageReferrerNone,
 	)
 
 	expected := proxied(
@@ -72,7 +76,7 @@ func TestRewriteSummaryHTMLForBaseRelativeSrcset(t *testing.T) {
 	t.Parallel()
 
 	input := `<img srcset="images/a.jpg 1x, /images/b.jpg 2x">`
-	output := RewriteSummaryHTML(input, "https://example.com/posts/1")
+	output := RewriteSummaryHTML(input, "https://example.com/posts/1", ImageReferrerNone)
 	expectedA := proxied("https://example.com/posts/images/a.jpg")
 
 	expectedB := proxied("https://example.com/images/b.jpg")
@@ -96,7 +100,7 @@ func TestRewriteSummaryHTMLSrcsetWithCommasInURL(t *testing.T) {
 		substackURLSuffix +
 		`">`
 
-	output := RewriteSummaryHTML(input, "")
+	output := RewriteSummaryHTML(input, "", ImageReferrerNone)
 	if strings.Contains(output, ", w_424, c_limit") ||
 		strings.Contains(output, ", w_848, c_limit") {
 		t.Fatalf(
@@ -119,7 +123,7 @@ func TestRewriteSummaryHTMLAnchorTargetAndRel(t *testing.T) {
 
 	input := `<a href="https://example.com">Example</a>`
 
-	output := RewriteSummaryHTML(input, "")
+	output := RewriteSummaryHTML(input, "", ImageReferrerNone)
 	if !strings.Contains(output, `target="_blank"`) {
 		t.Fatalf("expected target _blank, got %q", output)
 	}
@@ -134,7 +138,7 @@ func TestRewriteSummaryHTMLAnchorRelPreservesExistingTokens(t *testing.T) {
 
 	input := `<a href="https://example.com" rel="author">Example</a>`
 
-	output := RewriteSummaryHTML(input, "")
+	output := RewriteSummaryHTML(input, "", ImageReferrerNone)
 	if !strings.Contains(output, `rel="author noopener noreferrer"`) {
 		t.Fatalf(
 			"expected existing rel token plus noopener noreferrer, got %q",
@@ -148,7 +152,7 @@ func TestRewriteSummaryHTMLAnchorTargetOverwritesNonBlank(t *testing.T) {
 
 	input := `<a href="https://example.com" target="_self">Example</a>`
 
-	output := RewriteSummaryHTML(input, "")
+	output := RewriteSummaryHTML(input, "", ImageReferrerNone)
 	if !strings.Contains(output, `target="_blank"`) {
 		t.Fatalf("expected target _blank, got %q", output)
 	}
@@ -164,6 +168,7 @@ func TestRewriteSummaryHTMLAnchorHrefResolvesAgainstBase(t *testing.T) {
 		input,
 		"https://www.reddit.com/r/accelerate/comments/1r60h2p/"+
 			"discussion_weve_built_this_before/",
+		ImageReferrerNone,
 	)
 	if !strings.Contains(
 		output,
@@ -206,3 +211,87 @@ func TestBuildImageProxyRequestHeaders(t *testing.T) {
 		t.Fatalf("expected no referer header, got %q", got)
 	}
 }
+
+func TestUnwrapXHTML(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		`<div xmlns="http://www.w3.org/1999/xhtml"><p>Hello <em>world</em></p></div>`: `<p>Hello <em>world</em></p>`,
+		`<xhtml:div xmlns:xhtml="http://www.w3.org/1999/xhtml"><xhtml:p>Hi</xhtml:p>` +
+			`<xhtml:img src="/a.png"/>after</xhtml:div>`: `<p>Hi</p><img src="/a.png"/>after`,
+		` <div xmlns="http://www.w3.org/1999/xhtml">&lt;tag&gt;</div> `:  `&lt;tag&gt;`,
+		`<xhtml:div><xhtml:p>Root-declared prefix</xhtml:p></xhtml:div>`: `<p>Root-declared prefix</p>`,
+		`<div><p>Not a wrapper</p></div>`:                                `<div><p>Not a wrapper</p></div>`,
+		`<p>plain html</p>`:                                              `<p>plain html</p>`,
+	}
+
+	for input, want := range cases {
+		if got := UnwrapXHTML(input); got != want {
+			t.Fatalf("UnwrapXHTML(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestStripLeadingImage(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{`<img src="/hero.png"><p>Body</p>`, `<p>Body</p>`},
+		{`<p><a href="/post"><img src="/hero.png"></a></p><p>Body</p>`, `<p>Body</p>`},
+		{
+			`<figure><img src="/hero.png"><figcaption>Cap</figcaption></figure>`,
+			`<figure><figcaption>Cap</figcaption></figure>`,
+		},
+		{`<p>Intro</p><img src="/inline.png">`, `<p>Intro</p><img src="/inline.png">`},
+		{`<p> </p><img src="/a.png"><p>Text <img src="/b.png"></p>`, `<p> </p><p>Text <img src="/b.png"/></p>`},
+		{`<iframe src="/video"></iframe><img src="/a.png">`, `<iframe src="/video"></iframe><img src="/a.png">`},
+		{`<p>No images</p>`, `<p>No images</p>`},
+	}
+
+	for _, tc := range cases {
+		if got := StripLeadingImage(tc.input); got != tc.want {
+			t.Fatalf("StripLeadingImage(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestHasImage(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input string
+		want  bool
+	}{
+		{`<p>Text <img src="/a.png"></p>`, true},
+		{`<IMG SRC="/a.png"/>`, true},
+		{`<p>Use the &lt;img&gt; tag</p>`, false},
+		{`<a title="<img>">link</a>`, false},
+		{`<p>No images</p>`, false},
+		{``, false},
+	}
+
+	for _, tc := range cases {
+		if got := HasImage(tc.input); got != tc.want {
+			t.Fatalf("HasImage(%q) = %v, want %v", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestFirstImageURL(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		`<p>Lead</p><img alt="x"><img src=" /a.png "><img src="/b.png">`: "/a.png",
+		`<p>Use the &lt;img src="/a.png"&gt; tag</p>`:                    "",
+		``: "",
+	}
+
+	for input, want := range cases {
+		if got := FirstImageURL(input); got != want {
+			t.Fatalf("FirstImageURL(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
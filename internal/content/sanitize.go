@@ -0,0 +1,189 @@
+package content
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// sanitizeDroppedElements are removed along with everything inside them.
+var sanitizeDroppedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Title:    true,
+	atom.Head:     true,
+	atom.Meta:     true,
+	atom.Link:     true,
+	atom.Base:     true,
+	atom.Iframe:   true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Form:     true,
+	atom.Input:    true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Math:     true,
+}
+
+// sanitizeAllowedElements are kept; any other element is replaced by its
+// children so the text of unknown wrappers survives.
+var sanitizeAllowedElements = map[atom.Atom]bool{
+	atom.A: true, atom.Abbr: true, atom.B: true, atom.Blockquote: true, atom.Br: true,
+	atom.Caption: true, atom.Center: true, atom.Cite: true, atom.Code: true, atom.Dd: true,
+	atom.Del: true, atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Em: true,
+	atom.Figcaption: true, atom.Figure: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Hr: true, atom.I: true,
+	atom.Img: true, atom.Ins: true, atom.Kbd: true, atom.Li: true, atom.Mark: true,
+	atom.Ol: true, atom.P: true, atom.Pre: true, atom.Q: true, atom.S: true,
+	atom.Small: true, atom.Span: true, atom.Strong: true, atom.Sub: true, atom.Sup: true,
+	atom.Table: true, atom.Tbody: true, atom.Td: true, atom.Tfoot: true, atom.Th: true,
+	atom.Thead: true, atom.Tr: true, atom.U: true, atom.Ul: true,
+}
+
+// sanitizeAllowedAttrs lists the attributes kept on any allowed element;
+// URL attributes are checked separately by sanitizeURLAttr.
+var sanitizeAllowedAttrs = map[string]bool{
+	"alt":     true,
+	"title":   true,
+	"width":   true,
+	"height":  true,
+	"colspan": true,
+	"rowspan": true,
+	"lang":    true,
+	"dir":     true,
+}
+
+// SanitizeHTML reduces untrusted HTML, such as an email body, to an allowlist
+// of formatting elements. Scripts, styles, forms, frames, event handlers, and
+// inline styles are dropped, and links and images must use http(s) URLs
+// (links may also be mailto:). Full documents are reduced to their body.
+func SanitizeHTML(fragment string) string {
+	nodes, ok := parseSummaryFragment(fragment)
+	if !ok {
+		return ""
+	}
+
+	root := new(html.Node)
+	root.Type = html.ElementNode
+	root.DataAtom = atom.Div
+	root.Data = "div"
+
+	for _, node := range nodes {
+		root.AppendChild(node)
+	}
+
+	sanitizeChildren(root)
+
+	sanitized, ok := renderSummaryNodes(detachChildren(root))
+	if !ok {
+		return ""
+	}
+
+	return sanitized
+}
+
+func sanitizeChildren(parent *html.Node) {
+	for child := parent.FirstChild; child != nil; {
+		next := child.NextSibling
+
+		switch child.Type {
+		case html.TextNode:
+		case html.ElementNode:
+			next = sanitizeElement(parent, child, next)
+		default:
+			parent.RemoveChild(child)
+		}
+
+		child = next
+	}
+}
+
+// sanitizeElement cleans one element and returns the sibling to continue
+// with, which is the element's first hoisted child when it is unwrapped.
+func sanitizeElement(parent, node, next *html.Node) *html.Node {
+	if sanitizeDroppedElements[node.DataAtom] {
+		parent.RemoveChild(node)
+
+		return next
+	}
+
+	if !sanitizeAllowedElements[node.DataAtom] {
+		children := detachChildren(node)
+		for _, child := range children {
+			parent.InsertBefore(child, node)
+		}
+
+		parent.RemoveChild(node)
+
+		if len(children) > 0 {
+			return children[0]
+		}
+
+		return next
+	}
+
+	node.Attr = sanitizeAttrs(node)
+	sanitizeChildren(node)
+
+	return next
+}
+
+func sanitizeAttrs(node *html.Node) []html.Attribute {
+	kept := make([]html.Attribute, 0, len(node.Attr))
+
+	for _, attr := range node.Attr {
+		if attr.Namespace != "" {
+			continue
+		}
+
+		key := strings.ToLower(attr.Key)
+
+		switch {
+		case key == "href" && node.DataAtom == atom.A:
+			if value, ok := sanitizeURLAttr(attr.Val, "http", "https", "mailto"); ok {
+				kept = append(kept, html.Attribute{Key: key, Val: value})
+			}
+		case key == "src" && node.DataAtom == atom.Img:
+			if value, ok := sanitizeURLAttr(attr.Val, "http", "https"); ok {
+				kept = append(kept, html.Attribute{Key: key, Val: value})
+			}
+		case sanitizeAllowedAttrs[key]:
+			kept = append(kept, html.Attribute{Key: key, Val: attr.Val})
+		default:
+		}
+	}
+
+	return kept
+}
+
+// sanitizeURLAttr accepts absolute URLs with one of schemes and relative URLs,
+// which later rewriting resolves against the item link.
+func sanitizeURLAttr(raw string, schemes ...string) (string, bool) {
+	value := strings.TrimSpace(raw)
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return "", false
+	}
+
+	if parsed.Scheme == "" {
+		return value, value != ""
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	for _, allowed := range schemes {
+		if scheme == allowed {
+			return value, true
+		}
+	}
+
+	return "", false
+}
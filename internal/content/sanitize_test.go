@@ -0,0 +1,65 @@
+//nolint:testpackage // Content tests exercise package-internal helpers directly.
+package content
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{
+			name:     "formatting is kept",
+			fragment: `<p>Hello <strong>world</strong><br><a href="https://example.com/a" title="A">link</a></p>`,
+			want:     `<p>Hello <strong>world</strong><br/><a href="https://example.com/a" title="A">link</a></p>`,
+		},
+		{
+			name:     "scripts styles and forms are dropped with content",
+			fragment: `<style>p{}</style><p>Text</p><script>alert(1)</script><form><input name="q">Go</form>`,
+			want:     `<p>Text</p>`,
+		},
+		{
+			name:     "event handlers and inline styles are dropped",
+			fragment: `<p onclick="x()" style="color:red" class="c">Hi</p>`,
+			want:     `<p>Hi</p>`,
+		},
+		{
+			name:     "unsafe urls are dropped",
+			fragment: `<a href="javascript:alert(1)">a</a><img src="data:image/png;base64,AAAA" alt="x">`,
+			want:     `<a>a</a><img alt="x"/>`,
+		},
+		{
+			name:     "mailto links and relative urls are kept",
+			fragment: `<a href="mailto:hi@example.com">mail</a><img src="/logo.png">`,
+			want:     `<a href="mailto:hi@example.com">mail</a><img src="/logo.png"/>`,
+		},
+		{
+			name:     "unknown wrappers are unwrapped",
+			fragment: `<center><font face="Arial"><p>Inside</p></font></center><custom-tag>tag</custom-tag>`,
+			want:     `<center><p>Inside</p></center>tag`,
+		},
+		{
+			name:     "full documents are reduced to the body",
+			fragment: `<html><head><title>Subject</title><meta charset="utf-8"></head><body><p>Body</p></body></html>`,
+			want:     `<p>Body</p>`,
+		},
+		{
+			name:     "comments are dropped",
+			fragment: `<p>A<!-- tracking -->B</p>`,
+			want:     `<p>AB</p>`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := SanitizeHTML(tc.fragment); got != tc.want {
+				t.Fatalf("SanitizeHTML(%q) = %q, want %q", tc.fragment, got, tc.want)
+			}
+		})
+	}
+}
@@ -7,7 +7,7 @@ import (
 
 const srcsetStepOne = 1
 
-func rewriteSrcset(value string, base *url.URL) (string, bool) {
+func rewriteSrcset(value string, base *url.URL, referrer string) (string, bool) {
 	parts := parseSrcsetCandidates(value)
 	if parts == nil {
 		return value, false
@@ -19,7 +19,7 @@ func rewriteSrcset(value string, base *url.URL) (string, bool) {
 
 	for _, part := range parts {
 		imageURL := part.imageURL
-		if updated, ok := ProxyImageURL(imageURL, base); ok {
+		if updated, ok := proxyImageURL(imageURL, base, referrer); ok {
 			imageURL = updated
 			changed = true
 		}
@@ -0,0 +1,34 @@
+package content
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// PageTitle returns the text of the first <title> in an HTML document with
+// runs of whitespace collapsed, or "" when the document has none.
+func PageTitle(r io.Reader) string {
+	tokenizer := html.NewTokenizer(r)
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			if atom.Lookup(name) != atom.Title {
+				continue
+			}
+
+			if tokenizer.Next() != html.TextToken {
+				return ""
+			}
+
+			return strings.Join(strings.Fields(string(tokenizer.Text())), " ")
+		default:
+		}
+	}
+}
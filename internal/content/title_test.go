@@ -0,0 +1,48 @@
+//nolint:testpackage // Content tests exercise package-internal helpers directly.
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPageTitle(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{
+			name:     "title is collapsed and unescaped",
+			document: "<html><head><title>\n  Go &amp; SQLite\n  tips </title></head><body>x</body></html>",
+			want:     "Go & SQLite tips",
+		},
+		{
+			name:     "markup inside title is text",
+			document: "<title>A <b>bold</b> claim</title>",
+			want:     "A <b>bold</b> claim",
+		},
+		{
+			name:     "missing title",
+			document: "<html><body><h1>Heading</h1></body></html>",
+			want:     "",
+		},
+		{
+			name:     "empty title",
+			document: "<title></title>",
+			want:     "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := PageTitle(strings.NewReader(tc.document)); got != tc.want {
+				t.Fatalf("PageTitle(%q) = %q, want %q", tc.document, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package content
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	truncationMarker = "\u2026"
+
+	// maxLeadWords and leadOpeningWords shape DistinctLead: a summary kept
+	// ahead of the content is at most maxLeadWords long, and its first
+	// leadOpeningWords words must not open any part of the content.
+	maxLeadWords     = 60
+	leadOpeningWords = 8
+)
+
+// Truncate strips markup from an HTML fragment and returns at most limit runes
+// of its text, cut at a word boundary. Because the result is plain text it can
+// never leave a tag unclosed. A marker is appended when text was dropped.
+func Truncate(fragment string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+
+	text := strings.Join(strings.Fields(fragmentText(fragment)), " ")
+
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	cut := string(runes[:limit])
+	if idx := strings.LastIndexByte(cut, ' '); idx > 0 {
+		cut = cut[:idx]
+	}
+
+	return strings.TrimRight(cut, " ,.;:") + truncationMarker
+}
+
+// WordCount returns the number of words in an HTML fragment's text, ignoring
+// markup, scripts, and styles.
+func WordCount(fragment string) int {
+	return len(strings.Fields(fragmentText(fragment)))
+}
+
+// DistinctLead reports whether summary is worth showing ahead of body: a
+// short note, such as an editor's standfirst, rather than an excerpt of the
+// body. Summaries that the body repeats, that end like a truncated excerpt or
+// carry a "continue reading" footer, that open the same way as some part of
+// the body, or that run long are not distinct, so only the body is shown.
+// The footer check is a best-effort heuristic that only knows English
+// phrasings; other summaries still fall to the remaining checks.
+func DistinctLead(summary, body string) bool {
+	text := normalizedText(summary)
+
+	words := strings.Fields(text)
+	if len(words) == 0 || len(words) > maxLeadWords {
+		return false
+	}
+
+	for _, marker := range excerptMarkers {
+		if strings.HasSuffix(text, marker) {
+			return false
+		}
+	}
+
+	for _, phrase := range excerptPhrases {
+		if strings.Contains(text, phrase) {
+			return false
+		}
+	}
+
+	opening := strings.Join(words[:min(len(words), leadOpeningWords)], " ")
+
+	return !strings.Contains(normalizedText(body), opening)
+}
+
+// excerptMarkers are the endings feeds append to truncated summaries, longest
+// first so "[...]" is not left behind as "[" after trimming "...".
+//
+//nolint:gochecknoglobals // Read-only table of excerpt endings.
+var excerptMarkers = []string{"[\u2026]", "[...]", "(\u2026)", "(...)", "...", "\u2026"}
+
+// excerptPhrases are footers feeds add to excerpts that point at the rest.
+// Only common English wordings are listed, so this misses footers in other
+// languages rather than guessing at them.
+//
+//nolint:gochecknoglobals // Read-only table of excerpt footers.
+var excerptPhrases = []string{"continue reading", "read more", "appeared first on"}
+
+func normalizedText(fragment string) string {
+	return strings.ToLower(strings.Join(strings.Fields(fragmentText(fragment)), " "))
+}
+
+func fragmentText(fragment string) string {
+	nodes, ok := parseSummaryFragment(fragment)
+	if !ok {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		appendNodeText(&b, node)
+	}
+
+	return b.String()
+}
+
+func appendNodeText(b *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		b.WriteString(node.Data)
+	case html.ElementNode:
+		if node.DataAtom == atom.Script || node.DataAtom == atom.Style {
+			return
+		}
+
+		// Separate block-level text so adjacent paragraphs do not run together.
+		b.WriteByte(' ')
+	default:
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		appendNodeText(b, child)
+	}
+}
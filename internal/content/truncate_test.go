@@ -0,0 +1,106 @@
+//nolint:testpackage // Content tests exercise package-internal helpers directly.
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		fragment string
+		limit    int
+		want     string
+	}{
+		{
+			name:     "short text is kept",
+			fragment: "<p>Hello <b>world</b></p>",
+			limit:    50,
+			want:     "Hello world",
+		},
+		{
+			name:     "cuts at word boundary",
+			fragment: "<p>The quick brown fox jumps</p>",
+			limit:    13,
+			want:     "The quick\u2026",
+		},
+		{
+			name:     "separates block elements and drops scripts",
+			fragment: "<p>One</p><p>Two</p><script>alert(1)</script><style>p{}</style>",
+			limit:    50,
+			want:     "One Two",
+		},
+		{
+			name:     "unclosed markup is stripped",
+			fragment: "<div><a href=\"/x\">Lead <em>story",
+			limit:    50,
+			want:     "Lead story",
+		},
+		{
+			name:     "single long word is cut mid-word",
+			fragment: "Supercalifragilistic",
+			limit:    5,
+			want:     "Super\u2026",
+		},
+		{
+			name:     "multibyte runes are not split",
+			fragment: "\u65e5\u672c\u8a9e\u306e\u30c6\u30ad\u30b9\u30c8\u3067\u3059",
+			limit:    3,
+			want:     "\u65e5\u672c\u8a9e\u2026",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Truncate(tc.fragment, tc.limit); got != tc.want {
+				t.Fatalf("Truncate(%q, %d) = %q, want %q", tc.fragment, tc.limit, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]int{
+		"":                                       0,
+		"<p></p>":                                0,
+		"<p>One <b>two</b></p><p>three</p>":      3,
+		"<p>Words</p><script>var x = 1</script>": 1,
+	}
+
+	for fragment, want := range cases {
+		if got := WordCount(fragment); got != want {
+			t.Fatalf("WordCount(%q) = %d, want %d", fragment, got, want)
+		}
+	}
+}
+
+func TestDistinctLead(t *testing.T) {
+	t.Parallel()
+
+	body := "<p>The council voted <em>7-2</em> to approve the plan on Tuesday night after a long debate.</p>" +
+		"<p>Work begins in May.</p>"
+
+	cases := map[string]bool{
+		"An editor's note: this story was updated with the final vote.":                   true,
+		"The council voted 7-2 to approve the plan on Tuesday night after a long debate.": false,
+		"The council voted 7-2 to approve the plan on Tuesday night, after much debate.":  false,
+		"The council voted 7-2 to approve the plan \u2026":                                false,
+		"Work begins in May. <a href=\"/story\">Continue reading \u2026</a>":              false,
+		"A new plan for the city. The post Council vote appeared first on Example News.":  false,
+		strings.Repeat("Something else entirely, at considerable length. ", 12):           false,
+		"": false,
+	}
+
+	for summary, want := range cases {
+		if got := DistinctLead(summary, body); got != want {
+			t.Fatalf("DistinctLead(%q) = %v, want %v", summary, got, want)
+		}
+	}
+}
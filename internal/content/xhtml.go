@@ -0,0 +1,189 @@
+package content
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+	// xhtmlConventionalPrefix is assumed to mean XHTML when the feed declared
+	// it on the document root, which the parser does not pass along.
+	xhtmlConventionalPrefix = "xhtml"
+)
+
+// UnwrapXHTML turns Atom type="xhtml" content into plain HTML. The required
+// XHTML wrapper div is removed and namespace-prefixed elements such as
+// <xhtml:p> are renamed to their HTML names, so later rewriting sees ordinary
+// <img> and <a> tags. Text without XHTML markup is returned unchanged.
+func UnwrapXHTML(text string) string {
+	if !strings.Contains(text, xhtmlNamespace) && !strings.Contains(text, "<"+xhtmlConventionalPrefix+":") {
+		return text
+	}
+
+	nodes, ok := parseSummaryFragment(text)
+	if !ok {
+		return text
+	}
+
+	prefixes := map[string]bool{xhtmlConventionalPrefix: true}
+	for _, node := range nodes {
+		collectXHTMLPrefixes(node, prefixes)
+	}
+
+	wrapper := xhtmlWrapper(nodes, prefixes)
+
+	// Normalize under a shared parent so void elements at the top level can
+	// hand their swallowed content back as siblings.
+	root := new(html.Node)
+	root.Type = html.ElementNode
+	root.DataAtom = atom.Div
+	root.Data = "div"
+
+	for _, node := range nodes {
+		root.AppendChild(node)
+	}
+
+	normalizeXHTMLNode(root, prefixes)
+
+	if wrapper != nil {
+		nodes = detachChildren(wrapper)
+	} else {
+		nodes = detachChildren(root)
+	}
+
+	rendered, ok := renderSummaryNodes(nodes)
+	if !ok {
+		return text
+	}
+
+	return rendered
+}
+
+func collectXHTMLPrefixes(node *html.Node, prefixes map[string]bool) {
+	for _, attr := range node.Attr {
+		prefix, found := strings.CutPrefix(attr.Key, "xmlns:")
+		if found && attr.Val == xhtmlNamespace {
+			prefixes[prefix] = true
+		}
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		collectXHTMLPrefixes(child, prefixes)
+	}
+}
+
+// xhtmlWrapper returns the lone top-level div that is in the XHTML namespace,
+// either by prefix or by declaration, ignoring surrounding whitespace. It
+// returns nil when there is none.
+func xhtmlWrapper(nodes []*html.Node, prefixes map[string]bool) *html.Node {
+	var wrapper *html.Node
+
+	for _, node := range nodes {
+		if node.Type == html.TextNode && strings.TrimSpace(node.Data) == "" {
+			continue
+		}
+
+		if wrapper != nil || node.Type != html.ElementNode || xhtmlLocalName(node.Data, prefixes) != "div" {
+			return nil
+		}
+
+		wrapper = node
+	}
+
+	if wrapper == nil || (wrapper.Data == "div" && !declaresXHTMLNamespace(wrapper)) {
+		return nil
+	}
+
+	return wrapper
+}
+
+func declaresXHTMLNamespace(node *html.Node) bool {
+	for _, attr := range node.Attr {
+		if (attr.Key == "xmlns" || strings.HasPrefix(attr.Key, "xmlns:")) && attr.Val == xhtmlNamespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeXHTMLNode(node *html.Node, prefixes map[string]bool) {
+	if node.Type == html.ElementNode {
+		local := xhtmlLocalName(node.Data, prefixes)
+		if local != node.Data {
+			node.Data = local
+			node.DataAtom = atom.Lookup([]byte(local))
+		}
+
+		node.Attr = withoutNamespaceAttrs(node.Attr)
+	}
+
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		normalizeXHTMLNode(child, prefixes)
+		child = next
+	}
+
+	// A self-closed <xhtml:img/> parses as an open element that swallows the
+	// following content; hand those children back to the parent.
+	if isVoidElement(node) && node.FirstChild != nil && node.Parent != nil {
+		anchor := node
+		for _, child := range detachChildren(node) {
+			node.Parent.InsertBefore(child, anchor.NextSibling)
+			anchor = child
+		}
+	}
+}
+
+func xhtmlLocalName(name string, prefixes map[string]bool) string {
+	prefix, local, found := strings.Cut(name, ":")
+	if !found || !prefixes[prefix] {
+		return name
+	}
+
+	return local
+}
+
+func withoutNamespaceAttrs(attrs []html.Attribute) []html.Attribute {
+	kept := attrs[:0]
+
+	for _, attr := range attrs {
+		if attr.Key == "xmlns" || strings.HasPrefix(attr.Key, "xmlns:") {
+			continue
+		}
+
+		kept = append(kept, attr)
+	}
+
+	return kept
+}
+
+func detachChildren(node *html.Node) []*html.Node {
+	var children []*html.Node
+
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		node.RemoveChild(child)
+		children = append(children, child)
+		child = next
+	}
+
+	return children
+}
+
+func isVoidElement(node *html.Node) bool {
+	if node.Type != html.ElementNode {
+		return false
+	}
+
+	switch node.DataAtom {
+	case atom.Area, atom.Base, atom.Br, atom.Col, atom.Embed, atom.Hr, atom.Img,
+		atom.Input, atom.Link, atom.Meta, atom.Source, atom.Track, atom.Wbr:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/mmcdole/gofeed"
+
+	"rss/internal/content"
+)
+
+// Diagnosis reports how a feed URL answered a fetch, to help work out why it
+// cannot be subscribed to. Fields the fetch never reached stay empty, and
+// Error holds the error Fetch would have returned.
+type Diagnosis struct {
+	URL         string `json:"url"`
+	FinalURL    string `json:"final_url,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Error       string `json:"error,omitempty"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	BodyBytes   int    `json:"body_bytes"`
+	ItemCount   int    `json:"item_count"`
+}
+
+// Diagnose fetches feedURL the way Fetch does and reports the status, final
+// URL after redirects, content type, body size, and detected format, along
+// with the parse or fetch error, if any. Redirects to hosts lookup resolves to
+// private or loopback addresses are refused.
+func Diagnose(ctx context.Context, feedURL string, lookup content.LookupIPAddrFunc) Diagnosis {
+	diagnosis := Diagnosis{URL: feedURL}
+
+	overrides := FetchOverrides{Lookup: lookup, UserAgent: "", HTTPProxy: "", Timeout: 0, HTTPSOnly: false}
+
+	result, err := fetchFeed(ctx, feedURL, "", "", overrides, &diagnosis)
+	if err != nil {
+		diagnosis.Error = err.Error()
+
+		return diagnosis
+	}
+
+	if result.Feed != nil {
+		diagnosis.Title = result.Feed.Title
+		diagnosis.ItemCount = len(result.Feed.Items)
+
+		if result.Feed.FeedVersion != "" {
+			diagnosis.Format += " " + result.Feed.FeedVersion
+		}
+	}
+
+	return diagnosis
+}
+
+// detectFeedFormat names the feed type gofeed sees in body: "rss", "atom",
+// "json", or "unknown".
+func detectFeedFormat(body []byte) string {
+	switch gofeed.DetectFeedType(bytes.NewReader(body)) {
+	case gofeed.FeedTypeRSS:
+		return "rss"
+	case gofeed.FeedTypeAtom:
+		return "atom"
+	case gofeed.FeedTypeJSON:
+		return "json"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,309 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rss/internal/content"
+	"rss/internal/store"
+)
+
+const (
+	// enclosureSyncBatch bounds how many enclosures one Sync downloads, so a
+	// feed publishing a burst of episodes is caught up over several refreshes.
+	enclosureSyncBatch       = 5
+	enclosureDownloadTimeout = 10 * time.Minute
+	enclosureTempPrefix      = ".download-"
+	enclosureDirPerm         = 0o750
+	maxEnclosureRedirects    = 10
+)
+
+var (
+	errEnclosureTooLarge      = errors.New("enclosure is larger than the cache")
+	errEnclosureURLNotAllowed = errors.New("enclosure URL is not allowed")
+	errEnclosureRedirects     = errors.New("too many enclosure redirects")
+)
+
+// enclosureFilePattern matches the file names the cache writes: the item ID
+// and an optional short extension. Eviction only ever removes such files.
+var enclosureFilePattern = regexp.MustCompile(`^[0-9]+(\.[a-z0-9]{1,5})?$`)
+
+// EnclosureCache downloads the enclosures of feeds that opt in to a local
+// directory and keeps that directory under a size budget, evicting the least
+// recently played enclosures first. Enclosure URLs come from feed content, so
+// like the image proxy it only fetches http(s) URLs on public addresses,
+// checking each redirect too.
+type EnclosureCache struct {
+	client   *http.Client
+	lookup   content.LookupIPAddrFunc
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// NewEnclosureCache creates dir if needed and returns a cache storing at most
+// maxBytes of enclosures in it.
+func NewEnclosureCache(dir string, maxBytes int64) (*EnclosureCache, error) {
+	err := os.MkdirAll(dir, enclosureDirPerm)
+	if err != nil {
+		return nil, fmt.Errorf("create enclosure cache directory: %w", err)
+	}
+
+	cache := new(EnclosureCache)
+	cache.client = new(http.Client)
+	cache.client.CheckRedirect = cache.checkRedirect
+	cache.lookup = net.DefaultResolver.LookupIPAddr
+	cache.dir = dir
+	cache.maxBytes = maxBytes
+
+	return cache, nil
+}
+
+// SetLookup replaces the resolver used to check that enclosure hosts are not
+// private or loopback addresses. A nil lookup keeps the current one.
+func (c *EnclosureCache) SetLookup(lookup content.LookupIPAddrFunc) {
+	if lookup != nil {
+		c.lookup = lookup
+	}
+}
+
+// Path returns the location of a cached enclosure recorded as name.
+func (c *EnclosureCache) Path(name string) string {
+	return filepath.Join(c.dir, filepath.Base(name))
+}
+
+// Sync downloads enclosures waiting to be cached and then evicts cached ones
+// until the directory fits the budget. A Sync already in progress makes this
+// one return at once; the enclosures it skips stay pending for the next.
+func (c *EnclosureCache) Sync(ctx context.Context, db *sql.DB) error {
+	if !c.mu.TryLock() {
+		return nil
+	}
+	defer c.mu.Unlock()
+
+	pending, err := store.ListPendingEnclosures(ctx, db, enclosureSyncBatch)
+	if err != nil {
+		return fmt.Errorf("list pending enclosures: %w", err)
+	}
+
+	for _, enclosure := range pending {
+		// A failed download is recorded as not cached so it is not retried on
+		// every refresh; playback falls back to the original URL.
+		name, downloadErr := c.download(ctx, enclosure)
+		if downloadErr != nil {
+			slog.Warn("enclosure download failed", "item_id", enclosure.ItemID, logFieldErr, downloadErr)
+		}
+
+		err = store.SetItemEnclosurePath(ctx, db, enclosure.ItemID, name, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("record cached enclosure: %w", err)
+		}
+	}
+
+	return c.evict(ctx, db)
+}
+
+func (c *EnclosureCache) download(ctx context.Context, enclosure store.Enclosure) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, enclosureDownloadTimeout)
+	defer cancel()
+
+	target, err := url.Parse(strings.TrimSpace(enclosure.URL))
+	if err != nil || !content.IsAllowedResolvedProxyURL(ctx, target, c.lookup) {
+		return "", errEnclosureURLNotAllowed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("build enclosure request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", defaultUserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch enclosure: %w", err)
+	}
+
+	defer func() {
+		closeErr := resp.Body.Close()
+		if closeErr != nil {
+			slog.Warn("enclosure body close failed", logFieldErr, closeErr)
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return "", &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	if resp.ContentLength > c.maxBytes {
+		return "", errEnclosureTooLarge
+	}
+
+	tmp, err := os.CreateTemp(c.dir, enclosureTempPrefix+"*")
+	if err != nil {
+		return "", fmt.Errorf("create enclosure file: %w", err)
+	}
+
+	written, err := io.Copy(tmp, io.LimitReader(resp.Body, c.maxBytes+1))
+	closeErr := tmp.Close()
+
+	switch {
+	case err != nil:
+		err = fmt.Errorf("write enclosure file: %w", err)
+	case closeErr != nil:
+		err = fmt.Errorf("close enclosure file: %w", closeErr)
+	case written > c.maxBytes:
+		err = errEnclosureTooLarge
+	default:
+	}
+
+	if err != nil {
+		removeEnclosureFile(tmp.Name())
+
+		return "", err
+	}
+
+	name := enclosureFileName(enclosure)
+
+	err = os.Rename(tmp.Name(), c.Path(name))
+	if err != nil {
+		removeEnclosureFile(tmp.Name())
+
+		return "", fmt.Errorf("store enclosure file: %w", err)
+	}
+
+	return name, nil
+}
+
+func (c *EnclosureCache) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxEnclosureRedirects {
+		return errEnclosureRedirects
+	}
+
+	if !content.IsAllowedResolvedProxyURL(req.Context(), req.URL, c.lookup) {
+		return errEnclosureURLNotAllowed
+	}
+
+	return nil
+}
+
+// evict removes cache files no item refers to any more, such as those of
+// cleaned-up items and interrupted downloads, then the least recently used
+// enclosures until the rest fit in maxBytes.
+func (c *EnclosureCache) evict(ctx context.Context, db *sql.DB) error {
+	cached, err := store.ListCachedEnclosures(ctx, db)
+	if err != nil {
+		return fmt.Errorf("list cached enclosures: %w", err)
+	}
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("read enclosure cache directory: %w", err)
+	}
+
+	sizes := make(map[string]int64, len(entries))
+
+	for _, entry := range entries {
+		info, infoErr := entry.Info()
+		if infoErr != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		if enclosureFilePattern.MatchString(entry.Name()) || strings.HasPrefix(entry.Name(), enclosureTempPrefix) {
+			sizes[entry.Name()] = info.Size()
+		}
+	}
+
+	now := time.Now().UTC()
+	kept := make([]store.CachedEnclosure, 0, len(cached))
+	referenced := make(map[string]bool, len(cached))
+
+	var total int64
+
+	for _, entry := range cached {
+		size, ok := sizes[entry.Path]
+		if !ok {
+			err = store.SetItemEnclosurePath(ctx, db, entry.ItemID, "", now)
+			if err != nil {
+				return fmt.Errorf("forget missing enclosure: %w", err)
+			}
+
+			continue
+		}
+
+		referenced[entry.Path] = true
+		total += size
+		kept = append(kept, entry)
+	}
+
+	for name := range sizes {
+		if !referenced[name] {
+			removeEnclosureFile(c.Path(name))
+		}
+	}
+
+	for _, entry := range kept {
+		if total <= c.maxBytes {
+			break
+		}
+
+		removeEnclosureFile(c.Path(entry.Path))
+
+		err = store.SetItemEnclosurePath(ctx, db, entry.ItemID, "", now)
+		if err != nil {
+			return fmt.Errorf("evict enclosure: %w", err)
+		}
+
+		total -= sizes[entry.Path]
+	}
+
+	return nil
+}
+
+// enclosureFileName names an item's cached enclosure after the item, keeping
+// the extension from its URL or media type so the file stays recognizable.
+func enclosureFileName(enclosure store.Enclosure) string {
+	name := strconv.FormatInt(enclosure.ItemID, 10)
+
+	if parsed, err := url.Parse(enclosure.URL); err == nil {
+		if ext := strings.ToLower(path.Ext(parsed.Path)); enclosureFilePattern.MatchString(name + ext) {
+			return name + ext
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(enclosure.Type)
+	if err != nil {
+		return name
+	}
+
+	if exts, extErr := mime.ExtensionsByType(mediaType); extErr == nil && len(exts) > 0 {
+		if enclosureFilePattern.MatchString(name + exts[0]) {
+			return name + exts[0]
+		}
+	}
+
+	return name
+}
+
+func removeEnclosureFile(name string) {
+	err := os.Remove(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("enclosure file remove failed", "path", name, logFieldErr, err)
+	}
+}
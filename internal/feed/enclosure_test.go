@@ -0,0 +1,230 @@
+//nolint:testpackage // Feed tests exercise package-internal helpers directly.
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+
+	"rss/internal/store"
+	"rss/internal/testutil"
+)
+
+const testEpisodeBody = "RIFF01"
+
+func TestEnclosureCacheDownloadsAndEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testEpisodeBody))
+	}))
+	t.Cleanup(upstream.Close)
+
+	ctx := context.Background()
+	database := testutil.OpenTestDB(t)
+
+	feedID, err := store.UpsertFeed(ctx, database, "https://example.com/podcast.xml", "Podcast")
+	if err != nil {
+		t.Fatalf("UpsertFeed: %v", err)
+	}
+
+	episodes := []*gofeed.Item{newEpisodeItem("ep-1", testEpisodeHost+"/ep-1.mp3")}
+
+	_, err = store.UpsertItems(ctx, database, feedID, episodes)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	err = store.UpdateFeedFetchSettings(ctx, database, feedID, store.FeedFetchSettings{CacheEnclosures: true})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	dir := t.TempDir()
+
+	cache := newTestEnclosureCache(t, dir, int64(len(testEpisodeBody))+1, upstream)
+
+	for _, name := range []string{"999.mp3", "notes.txt"} {
+		err = os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600)
+		if err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	// Enclosures stored before the feed opted in are left alone.
+	mustSyncEnclosures(t, cache, database)
+	assertCachedEnclosureCount(t, database, 0)
+
+	episodes = append(episodes, newEpisodeItem("ep-2", testEpisodeHost+"/ep-2.mp3"))
+
+	_, err = store.UpsertItems(ctx, database, feedID, episodes)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	mustSyncEnclosures(t, cache, database)
+
+	episodes = append(episodes, newEpisodeItem("ep-3", testEpisodeHost+"/ep-3.mp3"))
+
+	_, err = store.UpsertItems(ctx, database, feedID, episodes)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	mustSyncEnclosures(t, cache, database)
+
+	cached, err := store.ListCachedEnclosures(ctx, database)
+	if err != nil {
+		t.Fatalf("ListCachedEnclosures: %v", err)
+	}
+
+	if len(cached) != 1 {
+		t.Fatalf("expected only the newest episode to fit the budget, got %+v", cached)
+	}
+
+	data, err := os.ReadFile(cache.Path(cached[0].Path))
+	if err != nil || string(data) != testEpisodeBody {
+		t.Fatalf("expected cached episode body, got %q (%v)", data, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	if len(names) != 2 || names[0] != cached[0].Path || names[1] != "notes.txt" {
+		t.Fatalf("expected evicted and stray episodes removed but other files kept, got %v", names)
+	}
+}
+
+func TestEnclosureCacheRefusesPrivateAddresses(t *testing.T) {
+	t.Parallel()
+
+	internalHits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect.mp3" {
+			http.Redirect(w, r, "http://127.0.0.1/internal", http.StatusFound)
+
+			return
+		}
+
+		internalHits++
+		_, _ = w.Write([]byte(testEpisodeBody))
+	}))
+	t.Cleanup(upstream.Close)
+
+	ctx := context.Background()
+	database := testutil.OpenTestDB(t)
+
+	feedID, err := store.UpsertFeed(ctx, database, "https://example.com/podcast.xml", "Podcast")
+	if err != nil {
+		t.Fatalf("UpsertFeed: %v", err)
+	}
+
+	err = store.UpdateFeedFetchSettings(ctx, database, feedID, store.FeedFetchSettings{CacheEnclosures: true})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	_, err = store.UpsertItems(ctx, database, feedID, []*gofeed.Item{
+		newEpisodeItem("loopback", upstream.URL+"/loopback.mp3"),
+		newEpisodeItem("redirect", testEpisodeHost+"/redirect.mp3"),
+		newEpisodeItem("file", "file:///etc/passwd"),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	cache := newTestEnclosureCache(t, t.TempDir(), 1<<20, upstream)
+	mustSyncEnclosures(t, cache, database)
+	assertCachedEnclosureCount(t, database, 0)
+
+	if internalHits != 0 {
+		t.Fatalf("expected no request to reach a private address, got %d", internalHits)
+	}
+}
+
+func TestEnclosureFileName(t *testing.T) {
+	t.Parallel()
+
+	cases := map[store.Enclosure]string{
+		{ItemID: 7, URL: "https://cdn.example.com/show/EP1.MP3?token=abc"}: "7.mp3",
+		{ItemID: 7, URL: "https://cdn.example.com/a.b/../weird.extension"}: "7",
+		{ItemID: 7, URL: "https://cdn.example.com/stream"}:                 "7",
+	}
+
+	for enclosure, expected := range cases {
+		if got := enclosureFileName(enclosure); got != expected {
+			t.Fatalf("enclosureFileName(%+v) = %q, want %q", enclosure, got, expected)
+		}
+	}
+}
+
+// testEpisodeHost is a public-looking enclosure host that
+// newTestEnclosureCache resolves to a public address and dials to upstream.
+const testEpisodeHost = "http://podcast.example"
+
+func newTestEnclosureCache(t *testing.T, dir string, maxBytes int64, upstream *httptest.Server) *EnclosureCache {
+	t.Helper()
+
+	cache, err := NewEnclosureCache(dir, maxBytes)
+	if err != nil {
+		t.Fatalf("NewEnclosureCache: %v", err)
+	}
+
+	cache.SetLookup(func(_ context.Context, _ string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+	})
+
+	transport := new(http.Transport)
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return new(net.Dialer).DialContext(ctx, network, upstream.Listener.Addr().String())
+	}
+	cache.client.Transport = transport
+
+	return cache
+}
+
+func newEpisodeItem(guid, enclosureURL string) *gofeed.Item {
+	item := new(gofeed.Item)
+	item.GUID = guid
+	item.Title = "Episode " + guid
+	item.Link = "https://example.com/" + guid
+	item.Enclosures = []*gofeed.Enclosure{{URL: enclosureURL, Type: "audio/mpeg", Length: "6"}}
+
+	return item
+}
+
+func mustSyncEnclosures(t *testing.T, cache *EnclosureCache, database *sql.DB) {
+	t.Helper()
+
+	err := cache.Sync(context.Background(), database)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+}
+
+func assertCachedEnclosureCount(t *testing.T, database *sql.DB, expected int) {
+	t.Helper()
+
+	cached, err := store.ListCachedEnclosures(context.Background(), database)
+	if err != nil {
+		t.Fatalf("ListCachedEnclosures: %v", err)
+	}
+
+	if len(cached) != expected {
+		t.Fatalf("expected %d cached enclosures, got %d", expected, len(cached))
+	}
+}
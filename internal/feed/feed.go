@@ -2,20 +2,30 @@
 package feed
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/binary"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html/charset"
 
+	"rss/internal/content"
 	"rss/internal/store"
 )
 
@@ -30,6 +40,8 @@ const (
 	refreshJitterMin        = 0.10
 	refreshJitterMax        = 0.20
 	feedFetchTimeout        = 15 * time.Second
+	fetchTimeoutCap         = 60 * time.Second
+	fetchTimeoutScale       = 2
 	maxErrorLength          = 300
 	randomFallback          = 0.5
 	countReset              = 0
@@ -43,6 +55,8 @@ const (
 	logFieldFeedID          = "feed_id"
 	logFieldFeedURL         = "feed_url"
 	logFieldErr             = "err"
+	defaultUserAgent        = "PulseRSS/1.0"
+	maxFetchRedirects       = 10
 )
 
 var (
@@ -51,6 +65,37 @@ var (
 	errFeedReturnedNoContent = errors.New("feed returned no content")
 	errUnexpectedFeedStatus  = errors.New("unexpected status from feed")
 	errRefreshMetaNil        = errors.New("refresh meta is nil")
+	errProxyURLInvalid       = errors.New("proxy URL must be an http, https, or socks5 URL with a host")
+	errInsecureRedirect      = errors.New("redirect to plain http blocked for HTTPS-only feed")
+	errTooManyRedirects      = errors.New("stopped after 10 redirects")
+	errRedirectNotAllowed    = errors.New("redirect to a disallowed host blocked")
+	errWebPageNotFeed        = errors.New("URL returned a web page, not a feed")
+)
+
+const (
+	// xmlDeclarationScanBytes bounds the search for an XML declaration.
+	xmlDeclarationScanBytes = 1024
+	fallbackFeedCharset     = "windows-1252"
+	// feedItemMaxDepth is the deepest nesting of items, under rss > channel.
+	feedItemMaxDepth = 2
+)
+
+var (
+	subredditShortcutPattern = regexp.MustCompile(`^/?r/([A-Za-z0-9_]{2,21})/?$`)
+	subredditNamePattern     = regexp.MustCompile(`^[A-Za-z0-9_]{2,21}$`)
+	youtubeChannelIDPattern  = regexp.MustCompile(`^UC[A-Za-z0-9_-]{22}$`)
+	languageTagPattern       = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+)
+
+// feedBodyPrefixes open XML and JSON feeds, so a body starting with one is
+// parsed even when the server labels it as a web page or plain text.
+var feedBodyPrefixes = [][]byte{
+	[]byte("<?xml"), []byte("<rss"), []byte("<feed"), []byte("<rdf:RDF"), []byte("{"),
+}
+
+var (
+	utf8BOM            = []byte{0xEF, 0xBB, 0xBF}
+	xmlEncodingPattern = regexp.MustCompile(`^\s*<\?xml[^>]*?\sencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
 )
 
 // FetchResult contains parsed feed data and fetch/cache metadata.
@@ -62,6 +107,59 @@ type FetchResult struct {
 	StatusCode   int
 }
 
+// RateLimitedError reports a 429 or 503 response that asked the client to
+// retry no earlier than RetryAt via Retry-After.
+type RateLimitedError struct {
+	RetryAt    time.Time
+	StatusCode int
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("%s: %d (retry after %s)", errUnexpectedFeedStatus, e.StatusCode, e.RetryAt.Format(time.RFC3339))
+}
+
+// StatusError reports a feed response with a non-2xx, non-304 status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d", errUnexpectedFeedStatus, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return errUnexpectedFeedStatus
+}
+
+// ErrorStatusCode returns the HTTP status behind a fetch error, or 0 when the
+// fetch failed before a response arrived or the response was unusable.
+func ErrorStatusCode(err error) int {
+	var rateLimited *RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return rateLimited.StatusCode
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode
+	}
+
+	return 0
+}
+
+// FetchOverrides holds optional per-feed request settings applied by FetchWithOverrides.
+// HTTPSOnly upgrades plain-http feed URLs to https and refuses redirects back to http.
+// A positive Timeout replaces feedFetchTimeout. A non-nil Lookup refuses
+// redirects to private or loopback hosts, resolving each hop's host with it,
+// for fetches of URLs someone typed in rather than subscribed to.
+type FetchOverrides struct {
+	Lookup    content.LookupIPAddrFunc
+	UserAgent string
+	HTTPProxy string
+	Timeout   time.Duration
+	HTTPSOnly bool
+}
+
 // CacheMeta stores cached response validators and unchanged counter.
 type CacheMeta struct {
 	ETag           string
@@ -70,18 +168,23 @@ type CacheMeta struct {
 }
 
 // RefreshMeta stores the refresh bookkeeping persisted for each feed.
+// A non-empty LastError extends the feed's run of consecutive errors and
+// ErrorCode records its HTTP status; ContentAt is set when new items arrived.
 type RefreshMeta struct {
 	LastCheckedAt  time.Time
 	NextRefreshAt  time.Time
+	ContentAt      time.Time
 	ETag           string
 	LastModified   string
 	LastError      string
 	UnchangedCount int
+	ErrorCode      int
 }
 
-// NormalizeURL validates and normalizes a feed URL.
+// NormalizeURL validates and normalizes a feed URL. Site shortcuts known to
+// ResolveShortcut are expanded to their feed URLs first.
 func NormalizeURL(raw string) (string, error) {
-	trimmed := strings.TrimSpace(raw)
+	trimmed := ResolveShortcut(strings.TrimSpace(raw))
 	if trimmed == "" {
 		return "", errFeedURLRequired
 	}
@@ -98,25 +201,119 @@ func NormalizeURL(raw string) (string, error) {
 	return u.String(), nil
 }
 
+// ResolveShortcut maps a YouTube channel page or a subreddit (including the
+// bare "r/name" form) to the feed URL the site publishes for it. Anything
+// else is returned unchanged.
+func ResolveShortcut(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+
+	if match := subredditShortcutPattern.FindStringSubmatch(trimmed); match != nil {
+		return subredditFeedURL(match[1])
+	}
+
+	candidate := trimmed
+	if !strings.Contains(candidate, "://") {
+		candidate = "https://" + candidate
+	}
+
+	u, err := url.Parse(candidate)
+	if err != nil {
+		return raw
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	if len(segments) != 2 {
+		return raw
+	}
+
+	switch host {
+	case "youtube.com", "m.youtube.com":
+		if segments[0] == "channel" && youtubeChannelIDPattern.MatchString(segments[1]) {
+			return "https://www.youtube.com/feeds/videos.xml?channel_id=" + segments[1]
+		}
+	case "reddit.com", "old.reddit.com", "new.reddit.com":
+		if segments[0] == "r" && subredditNamePattern.MatchString(segments[1]) {
+			return subredditFeedURL(segments[1])
+		}
+	default:
+	}
+
+	return raw
+}
+
+func subredditFeedURL(name string) string {
+	return "https://www.reddit.com/r/" + name + "/.rss"
+}
+
 // Fetch retrieves and parses a feed URL with conditional request headers.
+// The fetch is bounded by feedFetchTimeout and stops early when ctx is cancelled.
+// Refresh instead scales the timeout to each feed's average fetch time.
+func Fetch(ctx context.Context, feedURL, etag, lastModified string) (*FetchResult, error) {
+	return FetchWithOverrides(ctx, feedURL, etag, lastModified, FetchOverrides{})
+}
+
+// FetchWithOverrides is Fetch with a per-feed User-Agent and proxy. An invalid
+// proxy is logged and ignored so the feed is fetched directly instead.
+func FetchWithOverrides(
+	ctx context.Context,
+	feedURL, etag, lastModified string,
+	overrides FetchOverrides,
+) (*FetchResult, error) {
+	return fetchFeed(ctx, feedURL, etag, lastModified, overrides, nil)
+}
+
+// fetchFeed implements FetchWithOverrides, recording what the server sent in
+// diagnosis when it is not nil.
 //
 //nolint:gosec // Validated URL fetch path and branchy flow.
-func Fetch(ctx context.Context, feedURL, etag, lastModified string) (*FetchResult, error) {
+func fetchFeed(
+	ctx context.Context,
+	feedURL, etag, lastModified string,
+	overrides FetchOverrides,
+	diagnosis *Diagnosis,
+) (*FetchResult, error) {
 	normalizedURL, err := NormalizeURL(feedURL)
 	if err != nil {
 		return nil, err
 	}
 
+	if overrides.HTTPSOnly {
+		if parsed, parseErr := url.Parse(normalizedURL); parseErr == nil {
+			if upgraded, ok := content.UpgradeToHTTPS(parsed); ok {
+				normalizedURL = upgraded.String()
+			}
+		}
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timeout := feedFetchTimeout
+	if overrides.Timeout > 0 {
+		timeout = overrides.Timeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, normalizedURL, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "PulseRSS/1.0")
+	req.Header.Set("User-Agent", defaultUserAgent)
+
+	if userAgent := strings.TrimSpace(overrides.UserAgent); userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
 	setConditionalHeaders(req, etag, lastModified)
 
-	client := new(http.Client)
-	client.Timeout = feedFetchTimeout
+	client := newFetchClient(normalizedURL, overrides.HTTPProxy)
+	client.CheckRedirect = overrides.checkRedirect
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -130,7 +327,13 @@ func Fetch(ctx context.Context, feedURL, etag, lastModified string) (*FetchResul
 		}
 	}()
 
-	result, parseErr := parseFetchResponse(resp)
+	if diagnosis != nil {
+		diagnosis.StatusCode = resp.StatusCode
+		diagnosis.FinalURL = resp.Request.URL.String()
+		diagnosis.ContentType = resp.Header.Get("Content-Type")
+	}
+
+	result, parseErr := parseFetchResponse(resp, store.MaxItemsPerFeed, diagnosis)
 	if parseErr != nil {
 		return nil, parseErr
 	}
@@ -138,7 +341,83 @@ func Fetch(ctx context.Context, feedURL, etag, lastModified string) (*FetchResul
 	return result, nil
 }
 
-func parseFetchResponse(resp *http.Response) (*FetchResult, error) {
+// ParseProxyURL validates a per-feed proxy setting.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return nil, errProxyURLInvalid
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return u, nil
+	default:
+		return nil, errProxyURLInvalid
+	}
+}
+
+// checkRedirect applies rejectInsecureRedirect to HTTPS-only fetches and, when
+// Lookup is set, checks that each hop resolves to a public address.
+func (o FetchOverrides) checkRedirect(req *http.Request, via []*http.Request) error {
+	if o.HTTPSOnly {
+		err := rejectInsecureRedirect(req, via)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(via) >= maxFetchRedirects {
+		return errTooManyRedirects
+	}
+
+	if o.Lookup != nil && !content.IsAllowedResolvedProxyURL(req.Context(), req.URL, o.Lookup) {
+		return errRedirectNotAllowed
+	}
+
+	return nil
+}
+
+func rejectInsecureRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return errInsecureRedirect
+	}
+
+	if len(via) >= maxFetchRedirects {
+		return errTooManyRedirects
+	}
+
+	return nil
+}
+
+func newFetchClient(feedURL, rawProxy string) *http.Client {
+	client := new(http.Client)
+
+	if strings.TrimSpace(rawProxy) == "" {
+		return client
+	}
+
+	proxyURL, err := ParseProxyURL(rawProxy)
+	if err != nil {
+		slog.Warn("feed proxy ignored", logFieldFeedURL, feedURL, logFieldErr, err)
+
+		return client
+	}
+
+	transport := new(http.Transport)
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport = defaultTransport.Clone()
+	}
+
+	transport.Proxy = http.ProxyURL(proxyURL)
+	client.Transport = transport
+
+	return client
+}
+
+// parseFetchResponse parses at most maxItems items from a feed response; the
+// rest of a longer body is never read. A non-nil diagnosis gets the size and
+// detected format of the body that was read.
+func parseFetchResponse(resp *http.Response, maxItems int, diagnosis *Diagnosis) (*FetchResult, error) {
 	result := new(FetchResult)
 	result.ETag = strings.TrimSpace(resp.Header.Get("ETag"))
 	result.LastModified = strings.TrimSpace(resp.Header.Get("Last-Modified"))
@@ -150,14 +429,40 @@ func parseFetchResponse(resp *http.Response) (*FetchResult, error) {
 		return result, nil
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		retryAt, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now().UTC())
+		if ok {
+			return nil, &RateLimitedError{RetryAt: retryAt, StatusCode: resp.StatusCode}
+		}
+	}
+
 	if resp.StatusCode < http.StatusOK ||
 		resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("%w: %d", errUnexpectedFeedStatus, resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := readFeedBody(resp.Body, maxItems)
+	if err != nil {
+		return nil, fmt.Errorf("read feed body: %w", err)
+	}
+
+	if diagnosis != nil {
+		diagnosis.BodyBytes = len(body)
+		diagnosis.Format = detectFeedFormat(body)
+	}
+
+	body, err = transcodeToUTF8(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	if servedAsWebPage(resp.Header.Get("Content-Type")) && !looksLikeFeed(body) {
+		return nil, errWebPageNotFeed
 	}
 
 	parser := gofeed.NewParser()
 
-	feed, err := parser.Parse(resp.Body)
+	feed, err := parser.Parse(bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse feed: %w", err)
 	}
@@ -167,26 +472,298 @@ func parseFetchResponse(resp *http.Response) (*FetchResult, error) {
 	return result, nil
 }
 
-//nolint:cyclop,funlen,gocognit,revive // Branching flow keeps refresh side effects explicit.
+// servedAsWebPage reports whether a response is labeled as an HTML page.
+// Such bodies are only parsed when they look like a feed, so a site's home
+// page fails with a clear error instead of gofeed's type detection failure.
+func servedAsWebPage(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// looksLikeFeed reports whether a body, after any byte order mark and leading
+// whitespace, opens the way XML and JSON feeds do.
+func looksLikeFeed(body []byte) bool {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(body, utf8BOM), " \t\r\n")
+
+	for _, prefix := range feedBodyPrefixes {
+		if bytes.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readFeedBody streams a feed body through an XML tokenizer and stops reading
+// once maxItems top-level <item> or <entry> elements have closed. The prefix
+// read so far is returned with the still-open ancestors closed again, so huge
+// histories such as changelogs parse as their newest maxItems items instead of
+// being held in memory whole. Bodies the tokenizer cannot follow, such as
+// undeclared legacy charsets, are read in full.
+func readFeedBody(body io.Reader, maxItems int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	decoder := xml.NewDecoder(io.TeeReader(body, &buf))
+	decoder.Strict = false
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		// Only element structure matters here; transcodeToUTF8 decodes later.
+		return input, nil
+	}
+
+	var (
+		open  []xml.Name
+		items int
+	)
+
+	for maxItems > 0 {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			return buf.Bytes(), nil
+		}
+
+		if err != nil {
+			break
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			open = append(open, element.Name)
+		case xml.EndElement:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+
+			if !isFeedItemElement(element.Name, len(open)) {
+				continue
+			}
+
+			items++
+			if items >= maxItems {
+				return closeFeedPrefix(buf.Bytes()[:decoder.InputOffset()], open), nil
+			}
+		default:
+		}
+	}
+
+	_, err := io.Copy(&buf, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// isFeedItemElement reports whether a closing element at depth is an RSS
+// <item> (rss > channel > item, or rdf:RDF > item) or an Atom <entry>.
+func isFeedItemElement(name xml.Name, depth int) bool {
+	return (name.Local == "item" || name.Local == "entry") && depth <= feedItemMaxDepth
+}
+
+// closeFeedPrefix appends end tags for the elements still open at the cut.
+func closeFeedPrefix(prefix []byte, open []xml.Name) []byte {
+	closed := slices.Clone(prefix)
+
+	for i := len(open) - 1; i >= 0; i-- {
+		name := open[i].Local
+		if open[i].Space != "" {
+			name = open[i].Space + ":" + name
+		}
+
+		closed = append(closed, "</"+name+">"...)
+	}
+
+	return closed
+}
+
+// transcodeToUTF8 converts a feed body in a legacy charset to UTF-8 so the
+// parser never has to guess at non-UTF-8 bytes. When the body is converted
+// its XML declaration is rewritten to say UTF-8 so it is not decoded twice.
+func transcodeToUTF8(body []byte, contentType string) ([]byte, error) {
+	label := feedCharset(body, contentType)
+
+	encoding, name := charset.Lookup(label)
+	if encoding == nil || name == "utf-8" {
+		return body, nil
+	}
+
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s feed body: %w", name, err)
+	}
+
+	if loc := xmlEncodingPattern.FindSubmatchIndex(decoded); loc != nil {
+		decoded = slices.Concat(decoded[:loc[2]], []byte("UTF-8"), decoded[loc[3]:])
+	}
+
+	return decoded, nil
+}
+
+// feedCharset picks the charset label for a feed body. A byte order mark or
+// the XML declaration wins over the Content-Type charset, since the document
+// travels with its own declaration while header charsets are often server
+// defaults. Undeclared bodies that are not valid UTF-8 are read as
+// Windows-1252, which also covers ISO-8859-1.
+func feedCharset(body []byte, contentType string) string {
+	if bytes.HasPrefix(body, utf8BOM) {
+		return "utf-8"
+	}
+
+	head := body[:min(len(body), xmlDeclarationScanBytes)]
+	if match := xmlEncodingPattern.FindSubmatch(head); match != nil {
+		return string(match[1])
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err == nil && strings.TrimSpace(params["charset"]) != "" {
+		return strings.TrimSpace(params["charset"])
+	}
+
+	if utf8.Valid(body) {
+		return "utf-8"
+	}
+
+	return fallbackFeedCharset
+}
+
+// parseRetryAfter accepts either delay-seconds or an HTTP date.
+func parseRetryAfter(raw string, now time.Time) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, false
+	}
+
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err == nil {
+		if seconds < 0 {
+			return time.Time{}, false
+		}
+
+		return now.Add(time.Duration(min(seconds, int64(refreshBackoffMax/time.Second))) * time.Second), true
+	}
+
+	retryAt, err := http.ParseTime(raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return retryAt.UTC(), true
+}
+
+// rateLimitedRefreshAt schedules the next attempt no earlier than the server's
+// Retry-After, but never later than the backoff max.
+func rateLimitedRefreshAt(checkedAt, retryAt time.Time) time.Time {
+	next := NextRefreshAt(checkedAt, countReset)
+	if retryAt.After(next) {
+		next = retryAt
+	}
+
+	if limit := checkedAt.Add(refreshBackoffMax); next.After(limit) {
+		return limit
+	}
+
+	return next
+}
+
+// FetchedRefresh is a feed fetched by FetchForRefresh whose outcome has not
+// been stored yet.
+type FetchedRefresh struct {
+	checkedAt time.Time
+	fetchErr  error
+	result    *FetchResult
+	cache     CacheMeta
+	feedURL   string
+	feedID    int64
+	duration  int64
+	inserted  int
+	local     bool
+}
+
+// Inserted reports how many new items StoreRefresh stored for this fetch.
+func (f *FetchedRefresh) Inserted() int {
+	return f.inserted
+}
+
+// Refresh fetches a feed and stores the outcome. Callers that serialize
+// writes should call FetchForRefresh and StoreRefresh separately so the
+// network fetch stays outside their lock.
 func Refresh(ctx context.Context, db *sql.DB, feedID int64) (int64, error) {
+	fetched, err := FetchForRefresh(ctx, db, feedID)
+	if err != nil {
+		return zeroFeedID, err
+	}
+
+	return StoreRefresh(ctx, db, fetched)
+}
+
+// FetchForRefresh loads a feed's cache validators and fetch settings and
+// fetches it. Only the reads it needs touch the database; a failed fetch is
+// kept in the result for StoreRefresh to record.
+func FetchForRefresh(ctx context.Context, db *sql.DB, feedID int64) (*FetchedRefresh, error) {
 	feedURL, err := store.GetFeedURL(ctx, db, feedID)
 	if err != nil {
 		slog.Error("refresh feed lookup failed", logFieldFeedID, feedID, logFieldErr, err)
 
-		return zeroFeedID, fmt.Errorf("get feed URL: %w", err)
+		return nil, fmt.Errorf("get feed URL: %w", err)
 	}
 
-	cache, err := getFeedCacheMeta(ctx, db, feedID)
+	fetched := &FetchedRefresh{feedID: feedID, feedURL: feedURL}
+
+	// Newsletter and saved-link feeds are filled locally; there is nothing to fetch.
+	if store.IsLocalFeedURL(feedURL) {
+		fetched.local = true
+
+		return fetched, nil
+	}
+
+	fetched.cache, err = getFeedCacheMeta(ctx, db, feedID)
 	if err != nil {
 		slog.Error("refresh feed cache lookup failed", logFieldFeedID, feedID, logFieldFeedURL, feedURL, logFieldErr, err)
 
-		return zeroFeedID, err
+		return nil, err
+	}
+
+	overrides, err := getFeedFetchOverrides(ctx, db, feedID)
+	if err != nil {
+		slog.Warn("refresh feed fetch settings lookup failed", logFieldFeedID, feedID, logFieldErr, err)
 	}
 
+	lastModified := conditionalLastModified(ctx, db, feedID, fetched.cache)
+
 	start := time.Now()
-	result, err := Fetch(ctx, feedURL, cache.ETag, cache.LastModified)
-	duration := time.Since(start).Milliseconds()
-	checkedAt := time.Now().UTC()
+	fetched.result, fetched.fetchErr = FetchWithOverrides(ctx, feedURL, fetched.cache.ETag, lastModified, overrides)
+	fetched.duration = time.Since(start).Milliseconds()
+	fetched.checkedAt = time.Now().UTC()
+
+	return fetched, nil
+}
+
+// StoreRefresh records a fetch from FetchForRefresh: refresh bookkeeping,
+// feed details, and new items. It returns the feed ID the items were stored
+// under.
+//
+//nolint:cyclop,funlen,gocognit,revive // Branching flow keeps refresh side effects explicit.
+func StoreRefresh(ctx context.Context, db *sql.DB, fetched *FetchedRefresh) (int64, error) {
+	if fetched.local {
+		return fetched.feedID, nil
+	}
+
+	feedID := fetched.feedID
+	feedURL := fetched.feedURL
+	cache := fetched.cache
+	result, err := fetched.result, fetched.fetchErr
+	duration := fetched.duration
+	checkedAt := fetched.checkedAt
+
+	// Failed fetches count too, timeouts at the full timeout, so a feed that
+	// keeps running out of time earns a longer one on the next attempt.
+	durationErr := store.RecordFetchDuration(ctx, db, feedID, time.Duration(duration)*time.Millisecond)
+	if durationErr != nil {
+		slog.Warn("refresh feed duration update failed", logFieldFeedID, feedID, logFieldErr, durationErr)
+	}
 
 	var meta RefreshMeta
 
@@ -194,8 +771,16 @@ func Refresh(ctx context.Context, db *sql.DB, feedID int64) (int64, error) {
 
 	if err != nil {
 		meta.LastError = truncateString(err.Error())
+		meta.ErrorCode = ErrorStatusCode(err)
 		meta.UnchangedCount = countReset
 		meta.NextRefreshAt = NextRefreshAt(checkedAt, meta.UnchangedCount)
+
+		var rateLimited *RateLimitedError
+		if errors.As(err, &rateLimited) {
+			meta.NextRefreshAt = rateLimitedRefreshAt(checkedAt, rateLimited.RetryAt)
+			meta.LastError = "Rate limited, retrying at " + meta.NextRefreshAt.Format("Jan 2 15:04 MST")
+		}
+
 		saveRefreshMetaBestEffort(ctx, db, feedID, &meta)
 		slog.Error("refresh feed fetch failed",
 			logFieldFeedID, feedID,
@@ -269,6 +854,11 @@ func Refresh(ctx context.Context, db *sql.DB, feedID int64) (int64, error) {
 		return zeroFeedID, fmt.Errorf("upsert feed: %w", err)
 	}
 
+	detailsErr := SaveDetails(ctx, db, updatedID, feedURL, result.Feed)
+	if detailsErr != nil {
+		slog.Warn("refresh feed details update failed", logFieldFeedID, updatedID, logFieldErr, detailsErr)
+	}
+
 	inserted, err := store.UpsertItems(ctx, db, updatedID, result.Feed.Items)
 	if err != nil {
 		meta.LastError = truncateString(err.Error())
@@ -285,6 +875,8 @@ func Refresh(ctx context.Context, db *sql.DB, feedID int64) (int64, error) {
 		return zeroFeedID, fmt.Errorf("upsert items: %w", err)
 	}
 
+	fetched.inserted = inserted
+
 	enforceErr := store.EnforceItemLimit(ctx, db, updatedID)
 	if enforceErr != nil {
 		meta.LastError = truncateString(enforceErr.Error())
@@ -305,6 +897,7 @@ func Refresh(ctx context.Context, db *sql.DB, feedID int64) (int64, error) {
 		meta.UnchangedCount = cache.UnchangedCount + countStep
 	} else {
 		meta.UnchangedCount = countReset
+		meta.ContentAt = checkedAt
 	}
 
 	meta.NextRefreshAt = NextRefreshAt(checkedAt, meta.UnchangedCount)
@@ -327,6 +920,62 @@ func Refresh(ctx context.Context, db *sql.DB, feedID int64) (int64, error) {
 	return updatedID, nil
 }
 
+// SaveDetails persists the parsed feed's description, site link, and language.
+func SaveDetails(ctx context.Context, db *sql.DB, feedID int64, feedURL string, parsed *gofeed.Feed) error {
+	if parsed == nil {
+		return nil
+	}
+
+	err := store.UpdateFeedDetails(
+		ctx,
+		db,
+		feedID,
+		strings.TrimSpace(parsed.Description),
+		SiteURL(parsed.Link, feedURL),
+		Language(parsed.Language),
+	)
+	if err != nil {
+		return fmt.Errorf("save feed details: %w", err)
+	}
+
+	return nil
+}
+
+// Language normalizes a feed's declared language to a BCP 47 style tag such as
+// "en-US", returning "" when the feed declares none or something unusable.
+func Language(raw string) string {
+	tag := strings.ReplaceAll(strings.TrimSpace(raw), "_", "-")
+	if !languageTagPattern.MatchString(tag) {
+		return ""
+	}
+
+	return tag
+}
+
+// SiteURL resolves a feed's site link against its feed URL, keeping only http(s) links.
+func SiteURL(link, feedURL string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	base, err := url.Parse(feedURL)
+	if err == nil {
+		ref = base.ResolveReference(ref)
+	}
+
+	if ref.Host == "" || (ref.Scheme != "http" && ref.Scheme != "https") {
+		return ""
+	}
+
+	return ref.String()
+}
+
 func setConditionalHeaders(req *http.Request, etag, lastModified string) {
 	if strings.TrimSpace(etag) != "" {
 		req.Header.Set("If-None-Match", etag)
@@ -416,6 +1065,67 @@ WHERE id = ?
 	}, nil
 }
 
+// conditionalLastModified returns the If-Modified-Since value for a refresh.
+// Feeds that never sent an ETag or Last-Modified fall back to the newest stored
+// item's publish time, which many servers honor anyway. The fallback is only
+// sent, never stored, so a real Last-Modified still replaces it.
+func conditionalLastModified(ctx context.Context, db *sql.DB, feedID int64, cache CacheMeta) string {
+	if cache.LastModified != "" || cache.ETag != "" {
+		return cache.LastModified
+	}
+
+	newest, err := store.NewestItemTime(ctx, db, feedID)
+	if err != nil {
+		slog.Warn("refresh newest item lookup failed", logFieldFeedID, feedID, logFieldErr, err)
+
+		return ""
+	}
+
+	if newest.IsZero() || newest.After(time.Now()) {
+		return ""
+	}
+
+	return newest.UTC().Format(http.TimeFormat)
+}
+
+func getFeedFetchOverrides(ctx context.Context, db *sql.DB, feedID int64) (FetchOverrides, error) {
+	var (
+		userAgent, httpProxy sql.NullString
+		avgFetchMillis       sql.NullInt64
+		httpsOnly            bool
+	)
+
+	err := db.QueryRowContext(ctx, `
+SELECT user_agent, http_proxy, https_only, avg_fetch_ms
+FROM feeds
+WHERE id = ?
+`, feedID).Scan(&userAgent, &httpProxy, &httpsOnly, &avgFetchMillis)
+	if err != nil {
+		return FetchOverrides{}, fmt.Errorf("load feed fetch settings: %w", err)
+	}
+
+	return FetchOverrides{
+		Lookup:    nil,
+		UserAgent: strings.TrimSpace(userAgent.String),
+		HTTPProxy: strings.TrimSpace(httpProxy.String),
+		Timeout:   adaptiveFetchTimeout(avgFetchMillis),
+		HTTPSOnly: httpsOnly,
+	}, nil
+}
+
+// adaptiveFetchTimeout gives feeds that are consistently slow twice their
+// average fetch time, so they are not cut off at feedFetchTimeout, while
+// keeping fast feeds at the default and every feed under fetchTimeoutCap.
+func adaptiveFetchTimeout(avgFetchMillis sql.NullInt64) time.Duration {
+	if !avgFetchMillis.Valid {
+		return feedFetchTimeout
+	}
+
+	scaled := fetchTimeoutScale * time.Duration(avgFetchMillis.Int64) * time.Millisecond
+
+	return min(max(feedFetchTimeout, scaled), fetchTimeoutCap)
+}
+
 func updateFeedRefreshMeta(ctx context.Context, db *sql.DB, feedID int64, meta *RefreshMeta) error {
 	if meta == nil {
 		return errRefreshMetaNil
@@ -443,7 +1153,11 @@ SET etag = COALESCE(?, etag),
     last_refreshed_at = ?,
     last_error = ?,
     unchanged_count = ?,
-    next_refresh_at = ?
+    next_refresh_at = ?,
+    consecutive_errors = CASE WHEN ? IS NULL THEN 0 ELSE consecutive_errors + 1 END,
+    last_error_at = CASE WHEN ? IS NULL THEN NULL ELSE COALESCE(last_error_at, ?) END,
+    last_error_code = ?,
+    last_content_at = COALESCE(?, last_content_at)
 WHERE id = ?
 `,
 		nullString(meta.ETag),
@@ -452,6 +1166,11 @@ WHERE id = ?
 		nullString(meta.LastError),
 		meta.UnchangedCount,
 		meta.NextRefreshAt,
+		nullString(meta.LastError),
+		nullString(meta.LastError),
+		meta.LastCheckedAt,
+		nullErrorCode(meta.ErrorCode),
+		nullTime(meta.ContentAt),
 		feedID,
 	)
 	if err != nil {
@@ -521,3 +1240,19 @@ func nullString(value string) any {
 
 	return value
 }
+
+func nullErrorCode(code int) any {
+	if code <= 0 {
+		return nil
+	}
+
+	return code
+}
+
+func nullTime(value time.Time) any {
+	if value.IsZero() {
+		return nil
+	}
+
+	return value
+}
@@ -4,9 +4,19 @@ package feed
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/mmcdole/gofeed"
+
 	"rss/internal/store"
 	"rss/internal/testutil"
 )
@@ -69,6 +79,680 @@ func TestRefreshInsertsNewItems(t *testing.T) {
 	assertFeedItemCount(t, database, feedID, expectedUpdatedItemCount, "second")
 }
 
+func TestFetchStopsWhenContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	_, err := Fetch(ctx, upstream.URL, "", "")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed >= feedFetchTimeout {
+		t.Fatalf("expected fetch to stop on cancellation, took %s", elapsed)
+	}
+}
+
+func TestRefreshAppliesFeedFetchOverrides(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		userAgent string
+		proxyHost string
+	)
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgent = r.UserAgent()
+		proxyHost = r.URL.Host
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(testutil.RSSXML(refreshFeedTitle, nil)))
+	}))
+	defer proxy.Close()
+
+	database := testutil.OpenTestDB(t)
+
+	feedID, err := store.UpsertFeed(context.Background(), database, "http://feed.invalid/rss", refreshFeedTitle)
+	if err != nil {
+		t.Fatalf("store.UpsertFeed: %v", err)
+	}
+
+	err = store.UpdateFeedFetchSettings(context.Background(), database, feedID, store.FeedFetchSettings{
+		UserAgent: "CustomAgent/2.0",
+		HTTPProxy: proxy.URL,
+	})
+	if err != nil {
+		t.Fatalf("store.UpdateFeedFetchSettings: %v", err)
+	}
+
+	_, err = Refresh(context.Background(), database, feedID)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if proxyHost != "feed.invalid" {
+		t.Fatalf("expected request for feed.invalid via proxy, got host %q", proxyHost)
+	}
+
+	if userAgent != "CustomAgent/2.0" {
+		t.Fatalf("expected custom User-Agent, got %q", userAgent)
+	}
+}
+
+func TestRefreshAdaptsTimeoutToFetchDuration(t *testing.T) {
+	t.Parallel()
+
+	_, feedURL := testutil.NewFeedServer(t, testutil.RSSXML(refreshFeedTitle, nil))
+	database := testutil.OpenTestDB(t)
+	ctx := context.Background()
+
+	feedID, err := store.UpsertFeed(ctx, database, feedURL, refreshFeedTitle)
+	if err != nil {
+		t.Fatalf("store.UpsertFeed: %v", err)
+	}
+
+	overrides, err := getFeedFetchOverrides(ctx, database, feedID)
+	if err != nil || overrides.Timeout != feedFetchTimeout {
+		t.Fatalf("expected default timeout before any fetch, got %s (%v)", overrides.Timeout, err)
+	}
+
+	_, err = Refresh(ctx, database, feedID)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	var avgFetchMillis sql.NullInt64
+
+	err = database.QueryRowContext(ctx, "SELECT avg_fetch_ms FROM feeds WHERE id = ?", feedID).Scan(&avgFetchMillis)
+	if err != nil || !avgFetchMillis.Valid {
+		t.Fatalf("expected refresh to record its duration, got %v (%v)", avgFetchMillis, err)
+	}
+
+	_, err = database.ExecContext(ctx, "UPDATE feeds SET avg_fetch_ms = 20000 WHERE id = ?", feedID)
+	if err != nil {
+		t.Fatalf("set avg_fetch_ms: %v", err)
+	}
+
+	overrides, err = getFeedFetchOverrides(ctx, database, feedID)
+	if err != nil || overrides.Timeout != 40*time.Second {
+		t.Fatalf("expected a slow feed to get twice its average, got %s (%v)", overrides.Timeout, err)
+	}
+}
+
+func TestAdaptiveFetchTimeout(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		avg      sql.NullInt64
+		expected time.Duration
+	}{
+		{avg: sql.NullInt64{}, expected: feedFetchTimeout},
+		{avg: sql.NullInt64{Int64: 300, Valid: true}, expected: feedFetchTimeout},
+		{avg: sql.NullInt64{Int64: 12000, Valid: true}, expected: 24 * time.Second},
+		{avg: sql.NullInt64{Int64: 90000, Valid: true}, expected: fetchTimeoutCap},
+	}
+
+	for _, tc := range cases {
+		if got := adaptiveFetchTimeout(tc.avg); got != tc.expected {
+			t.Fatalf("adaptiveFetchTimeout(%v) = %s, want %s", tc.avg, got, tc.expected)
+		}
+	}
+}
+
+func TestRefreshHonorsRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "7200")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	database := testutil.OpenTestDB(t)
+
+	feedID, err := store.UpsertFeed(context.Background(), database, upstream.URL, refreshFeedTitle)
+	if err != nil {
+		t.Fatalf("store.UpsertFeed: %v", err)
+	}
+
+	_, err = Refresh(context.Background(), database, feedID)
+
+	var rateLimited *RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("expected rate limited error, got %v", err)
+	}
+
+	var (
+		nextRefreshAt time.Time
+		lastError     string
+	)
+
+	err = database.QueryRowContext(
+		context.Background(),
+		"SELECT next_refresh_at, last_error FROM feeds WHERE id = ?",
+		feedID,
+	).Scan(&nextRefreshAt, &lastError)
+	if err != nil {
+		t.Fatalf("load refresh meta: %v", err)
+	}
+
+	if until := time.Until(nextRefreshAt); until < 119*time.Minute {
+		t.Fatalf("expected next refresh at least two hours out, got %s", until)
+	}
+
+	if !strings.HasPrefix(lastError, "Rate limited, retrying at ") {
+		t.Fatalf("expected friendly rate limit status, got %q", lastError)
+	}
+}
+
+func TestRefreshTracksFeedHealth(t *testing.T) {
+	t.Parallel()
+
+	var failing atomic.Bool
+
+	failing.Store(true)
+
+	feedXML := testutil.RSSXML(refreshFeedTitle, []testutil.RSSItem{{
+		Title: "First",
+		Link:  "http://example.com/1",
+		GUID:  "1",
+	}})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(feedXML))
+	}))
+	defer upstream.Close()
+
+	database := testutil.OpenTestDB(t)
+
+	feedID, err := store.UpsertFeed(context.Background(), database, upstream.URL, refreshFeedTitle)
+	if err != nil {
+		t.Fatalf("store.UpsertFeed: %v", err)
+	}
+
+	var failingSince time.Time
+
+	for attempt := range 2 {
+		_, err = Refresh(context.Background(), database, feedID)
+		if ErrorStatusCode(err) != http.StatusInternalServerError {
+			t.Fatalf("expected status error 500, got %v", err)
+		}
+
+		feed, getErr := store.GetFeed(context.Background(), database, feedID)
+		if getErr != nil {
+			t.Fatalf("store.GetFeed: %v", getErr)
+		}
+
+		if attempt == 0 {
+			failingSince = feed.LastErrorAt
+		}
+
+		if failingSince.IsZero() || !feed.LastErrorAt.Equal(failingSince) || feed.FailingSince == "" {
+			t.Fatalf("expected error start kept at first failure %v, got %v", failingSince, feed.LastErrorAt)
+		}
+	}
+
+	errorsInARow, errorCode, contentAt := loadFeedHealth(t, database, feedID)
+	if errorsInARow != 2 || errorCode.Int64 != http.StatusInternalServerError || contentAt.Valid {
+		t.Fatalf("expected 2 errors with code 500 and no content, got %d %v %v", errorsInARow, errorCode, contentAt)
+	}
+
+	failing.Store(false)
+
+	_, err = Refresh(context.Background(), database, feedID)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	errorsInARow, errorCode, contentAt = loadFeedHealth(t, database, feedID)
+	if errorsInARow != 0 || errorCode.Valid || !contentAt.Valid {
+		t.Fatalf("expected reset errors and recorded content, got %d %v %v", errorsInARow, errorCode, contentAt)
+	}
+
+	feed, err := store.GetFeed(context.Background(), database, feedID)
+	if err != nil {
+		t.Fatalf("store.GetFeed: %v", err)
+	}
+
+	if !feed.LastErrorAt.IsZero() || feed.FailingSince != "" {
+		t.Fatalf("expected error start cleared after success, got %v", feed.LastErrorAt)
+	}
+}
+
+func TestRefreshFallsBackToNewestItemForIfModifiedSince(t *testing.T) {
+	t.Parallel()
+
+	newest := time.Date(2026, time.March, 2, 15, 4, 5, 0, time.UTC)
+	feedXML := testutil.RSSXML(refreshFeedTitle, []testutil.RSSItem{{
+		Title:   "Newest",
+		Link:    "http://example.com/2",
+		GUID:    "2",
+		PubDate: newest.Format(time.RFC1123Z),
+	}, {
+		Title:   "Older",
+		Link:    "http://example.com/1",
+		GUID:    "1",
+		PubDate: newest.Add(-time.Hour).Format(time.RFC1123Z),
+	}})
+
+	var (
+		mu              sync.Mutex
+		ifModifiedSince []string
+	)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ifModifiedSince = append(ifModifiedSince, r.Header.Get("If-Modified-Since"))
+		mu.Unlock()
+
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(feedXML))
+	}))
+	defer upstream.Close()
+
+	database := testutil.OpenTestDB(t)
+
+	feedID, err := store.UpsertFeed(context.Background(), database, upstream.URL, refreshFeedTitle)
+	if err != nil {
+		t.Fatalf("store.UpsertFeed: %v", err)
+	}
+
+	for range 2 {
+		_, err = Refresh(context.Background(), database, feedID)
+		if err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	want := []string{"", newest.Format(http.TimeFormat)}
+	if !slices.Equal(ifModifiedSince, want) {
+		t.Fatalf("expected If-Modified-Since %q, got %q", want, ifModifiedSince)
+	}
+
+	cache, err := getFeedCacheMeta(context.Background(), database, feedID)
+	if err != nil {
+		t.Fatalf("getFeedCacheMeta: %v", err)
+	}
+
+	if cache.LastModified != "" {
+		t.Fatalf("expected fallback date not to be stored, got %q", cache.LastModified)
+	}
+}
+
+func TestFetchFallsBackToDirectClientOnInvalidProxy(t *testing.T) {
+	t.Parallel()
+
+	var userAgent string
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		_, _ = w.Write([]byte(testutil.RSSXML(refreshFeedTitle, nil)))
+	}))
+	defer upstream.Close()
+
+	_, err := FetchWithOverrides(context.Background(), upstream.URL, "", "", FetchOverrides{
+		UserAgent: " ",
+		HTTPProxy: "ftp://proxy.example",
+	})
+	if err != nil {
+		t.Fatalf("FetchWithOverrides: %v", err)
+	}
+
+	if userAgent != defaultUserAgent {
+		t.Fatalf("expected default User-Agent, got %q", userAgent)
+	}
+}
+
+func TestRejectInsecureRedirect(t *testing.T) {
+	t.Parallel()
+
+	secure := httptest.NewRequest(http.MethodGet, "https://example.com/rss", http.NoBody)
+	insecure := httptest.NewRequest(http.MethodGet, "http://example.com/rss", http.NoBody)
+
+	err := rejectInsecureRedirect(insecure, []*http.Request{secure})
+	if !errors.Is(err, errInsecureRedirect) {
+		t.Fatalf("expected insecure redirect error, got %v", err)
+	}
+
+	err = rejectInsecureRedirect(secure, []*http.Request{secure})
+	if err != nil {
+		t.Fatalf("expected https redirect to be allowed, got %v", err)
+	}
+
+	via := make([]*http.Request, maxFetchRedirects)
+
+	err = rejectInsecureRedirect(secure, via)
+	if !errors.Is(err, errTooManyRedirects) {
+		t.Fatalf("expected too many redirects error, got %v", err)
+	}
+}
+
+func TestFetchTranscodesLegacyCharsets(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "latin-1 xml declaration",
+			contentType: "application/rss+xml",
+			body:        `<?xml version="1.0" encoding="ISO-8859-1"?>`,
+		},
+		{
+			name:        "windows-1252 content type",
+			contentType: "application/rss+xml; charset=windows-1252",
+			body:        `<?xml version="1.0"?>`,
+		},
+		{
+			name:        "undeclared non-utf-8 bytes",
+			contentType: "application/rss+xml",
+			body:        "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// "Caf\u00e9" with an e-acute (0xE9) and Windows-1252 smart quotes (0x93, 0x94).
+			body := tc.body + "<rss version=\"2.0\"><channel><title>Caf\xe9</title>" +
+				"<item><title>\x93Quoted\x94 na\xefve</title><guid>1</guid></item></channel></rss>"
+
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				_, _ = w.Write([]byte(body))
+			}))
+			defer upstream.Close()
+
+			result, err := Fetch(context.Background(), upstream.URL, "", "")
+			if err != nil {
+				t.Fatalf("Fetch: %v", err)
+			}
+
+			if result.Feed.Title != "Caf\u00e9" {
+				t.Fatalf("expected transcoded feed title, got %q", result.Feed.Title)
+			}
+
+			if got := result.Feed.Items[0].Title; got != "\u201cQuoted\u201d na\u00efve" {
+				t.Fatalf("expected transcoded item title, got %q", got)
+			}
+		})
+	}
+}
+
+func TestFetchParsesFeedsServedWithPageContentTypes(t *testing.T) {
+	t.Parallel()
+
+	rss := `<rss version="2.0"><channel><title>Mislabeled</title><item><guid>1</guid></item></channel></rss>`
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     error
+	}{
+		{name: "rss as html", contentType: "text/html; charset=utf-8", body: "\n  " + rss},
+		{name: "xml declaration as html", contentType: "text/html", body: `<?xml version="1.0"?>` + rss},
+		{
+			name:        "atom as plain text",
+			contentType: "text/plain",
+			body: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Mislabeled</title>` +
+				`<entry><id>1</id><title>One</title></entry></feed>`,
+		},
+		{
+			name:        "json feed as html",
+			contentType: "text/html",
+			body: `{"version":"https://jsonfeed.org/version/1.1","title":"Mislabeled",` +
+				`"items":[{"id":"1","content_text":"One"}]}`,
+		},
+		{
+			name:        "web page",
+			contentType: "text/html",
+			body:        `<!DOCTYPE html><html><head><title>Home</title></head><body>Hi</body></html>`,
+			wantErr:     errWebPageNotFeed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer upstream.Close()
+
+			result, err := Fetch(context.Background(), upstream.URL, "", "")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Fetch: %v", err)
+			}
+
+			if result.Feed.Title != "Mislabeled" || len(result.Feed.Items) != 1 {
+				t.Fatalf("unexpected feed %+v", result.Feed)
+			}
+		})
+	}
+}
+
+func TestReadFeedBodyStopsAfterItemCap(t *testing.T) {
+	t.Parallel()
+
+	var rssItems strings.Builder
+	for i := range 2000 {
+		fmt.Fprintf(&rssItems, "<item><title>Entry %d</title><guid>%d</guid></item>", i, i)
+	}
+
+	var atomEntries strings.Builder
+	for i := range 2000 {
+		fmt.Fprintf(&atomEntries, "<entry><title>Entry %d</title><id>%d</id></entry>", i, i)
+	}
+
+	cases := map[string]string{
+		"rss": `<?xml version="1.0"?><rss version="2.0"><channel><title>Changelog</title>` +
+			rssItems.String() + `</channel></rss>`,
+		"atom": `<feed xmlns="http://www.w3.org/2005/Atom"><title>Changelog</title>` +
+			atomEntries.String() + `</feed>`,
+		"rdf": `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">` +
+			`<channel><title>Changelog</title></channel>` + rssItems.String() + `</rdf:RDF>`,
+	}
+
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			body, err := readFeedBody(strings.NewReader(document), 3)
+			if err != nil {
+				t.Fatalf("readFeedBody: %v", err)
+			}
+
+			if len(body) > len(document)/10 {
+				t.Fatalf("expected the body to stop early, read %d of %d bytes", len(body), len(document))
+			}
+
+			parsed, err := gofeed.NewParser().ParseString(string(body))
+			if err != nil {
+				t.Fatalf("parse capped body: %v\n%s", err, body)
+			}
+
+			if parsed.Title != "Changelog" || len(parsed.Items) != 3 || parsed.Items[2].Title != "Entry 2" {
+				t.Fatalf("expected the first 3 items, got %q with %d items", parsed.Title, len(parsed.Items))
+			}
+		})
+	}
+}
+
+func TestReadFeedBodyKeepsShortAndUnreadableBodies(t *testing.T) {
+	t.Parallel()
+
+	for _, document := range []string{
+		`<rss><channel><item><title>Only</title></item></channel></rss>`,
+		"<rss><channel><title>Caf\xe9</title><item/><item/><item/></channel></rss>",
+	} {
+		body, err := readFeedBody(strings.NewReader(document), 2)
+		if err != nil {
+			t.Fatalf("readFeedBody: %v", err)
+		}
+
+		if string(body) != document {
+			t.Fatalf("expected body %q to be read whole, got %q", document, body)
+		}
+	}
+}
+
+func TestTranscodeToUTF8LeavesUTF8Alone(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`<?xml version="1.0" encoding="utf-8"?><rss><channel><title>Caf` + "\u00e9" +
+		`</title></channel></rss>`)
+
+	got, err := transcodeToUTF8(body, "text/xml; charset=iso-8859-1")
+	if err != nil {
+		t.Fatalf("transcodeToUTF8: %v", err)
+	}
+
+	if string(got) != string(body) {
+		t.Fatalf("expected declared UTF-8 body to be unchanged, got %q", got)
+	}
+}
+
+func TestResolveShortcut(t *testing.T) {
+	t.Parallel()
+
+	const channelFeed = "https://www.youtube.com/feeds/videos.xml?channel_id=UCuAXFkgsw1L7xaCfnd5JJOw"
+
+	cases := map[string]string{
+		"r/golang":                    "https://www.reddit.com/r/golang/.rss",
+		"/r/golang/":                  "https://www.reddit.com/r/golang/.rss",
+		"https://old.reddit.com/r/Go": "https://www.reddit.com/r/Go/.rss",
+		"reddit.com/r/golang":         "https://www.reddit.com/r/golang/.rss",
+		"https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw": channelFeed,
+		"m.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw/":          channelFeed,
+	}
+
+	for raw, want := range cases {
+		if got := ResolveShortcut(raw); got != want {
+			t.Fatalf("ResolveShortcut(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	for _, raw := range []string{
+		"https://www.youtube.com/@golang",
+		"https://www.reddit.com/r/golang/comments/abc/post",
+		"https://example.com/r/golang",
+		channelFeed,
+	} {
+		if got := ResolveShortcut(raw); got != raw {
+			t.Fatalf("expected %q to pass through unchanged, got %q", raw, got)
+		}
+	}
+
+	normalized, err := NormalizeURL(" r/golang ")
+	if err != nil || normalized != "https://www.reddit.com/r/golang/.rss" {
+		t.Fatalf("NormalizeURL(r/golang) = %q, %v", normalized, err)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"en-us":            "en-us",
+		" de ":             "de",
+		"pt_BR":            "pt-BR",
+		"":                 "",
+		"English":          "English",
+		"en us":            "",
+		"<script>":         "",
+		"zh-Hant-TW":       "zh-Hant-TW",
+		"x":                "",
+		"fr-waytoolongtag": "",
+	}
+
+	for raw, want := range cases {
+		if got := Language(raw); got != want {
+			t.Fatalf("Language(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestParseProxyURL(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"http://proxy.example:8080":       true,
+		"https://proxy.example":           true,
+		"socks5://user:pw@127.0.0.1:1080": true,
+		"ftp://proxy.example":             false,
+		"proxy.example:8080":              false,
+		"http://":                         false,
+	}
+
+	for raw, wantOK := range cases {
+		_, err := ParseProxyURL(raw)
+		if (err == nil) != wantOK {
+			t.Fatalf("ParseProxyURL(%q) err = %v, want ok=%v", raw, err, wantOK)
+		}
+	}
+}
+
+func loadFeedHealth(t *testing.T, database *sql.DB, feedID int64) (int, sql.NullInt64, sql.NullTime) {
+	t.Helper()
+
+	var (
+		errorsInARow int
+		errorCode    sql.NullInt64
+		contentAt    sql.NullTime
+	)
+
+	err := database.QueryRowContext(
+		context.Background(),
+		"SELECT consecutive_errors, last_error_code, last_content_at FROM feeds WHERE id = ?",
+		feedID,
+	).Scan(&errorsInARow, &errorCode, &contentAt)
+	if err != nil {
+		t.Fatalf("load feed health: %v", err)
+	}
+
+	return errorsInARow, errorCode, contentAt
+}
+
 func assertFeedItemCount(
 	t *testing.T,
 	database *sql.DB,
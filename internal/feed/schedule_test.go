@@ -81,3 +81,47 @@ func TestApplyJitterRange(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		raw    string
+		want   time.Time
+		wantOK bool
+	}{
+		{raw: "120", want: now.Add(2 * time.Minute), wantOK: true},
+		{raw: "Sun, 01 Mar 2026 13:00:00 GMT", want: now.Add(time.Hour), wantOK: true},
+		{raw: "999999999", want: now.Add(backoffCap), wantOK: true},
+		{raw: "", wantOK: false},
+		{raw: "-5", wantOK: false},
+		{raw: "soon", wantOK: false},
+	}
+
+	for _, tc := range cases {
+		got, ok := parseRetryAfter(tc.raw, now)
+		if ok != tc.wantOK || !got.Equal(tc.want) {
+			t.Fatalf("parseRetryAfter(%q) = %v, %v; want %v, %v", tc.raw, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestRateLimitedRefreshAtCapsAtBackoffMax(t *testing.T) {
+	t.Parallel()
+
+	checkedAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := rateLimitedRefreshAt(checkedAt, checkedAt.Add(3*time.Hour)); !got.Equal(checkedAt.Add(3 * time.Hour)) {
+		t.Fatalf("expected Retry-After to be honored, got %v", got)
+	}
+
+	if got := rateLimitedRefreshAt(checkedAt, checkedAt.Add(48*time.Hour)); !got.Equal(checkedAt.Add(backoffCap)) {
+		t.Fatalf("expected Retry-After to be capped at %v, got %v", backoffCap, got)
+	}
+
+	if got := rateLimitedRefreshAt(checkedAt, checkedAt); got.Before(checkedAt.Add(RefreshInterval / 2)) {
+		t.Fatalf("expected normal schedule when Retry-After is already past, got %v", got)
+	}
+}
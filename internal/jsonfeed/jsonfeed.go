@@ -0,0 +1,53 @@
+// Package jsonfeed writes JSON Feed 1.1 documents, as described at
+// https://www.jsonfeed.org/version/1.1/.
+package jsonfeed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Version is the URL a document names its format version with.
+const Version = "https://jsonfeed.org/version/1.1"
+
+// Feed is the top-level JSON Feed object. Write fills in Version.
+type Feed struct {
+	Version     string `json:"version"`
+	Title       string `json:"title"`
+	HomePageURL string `json:"home_page_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Items       []Item `json:"items"`
+}
+
+// Item is one entry of a feed. The spec requires id and at least one of
+// content_html or content_text, so ContentHTML is always written.
+type Item struct {
+	ID            string     `json:"id"`
+	URL           string     `json:"url,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	ContentHTML   string     `json:"content_html"`
+	DatePublished *time.Time `json:"date_published,omitempty"`
+	DateModified  *time.Time `json:"date_modified,omitempty"`
+}
+
+// Write encodes feed to w as a JSON Feed 1.1 document. A feed without items
+// is written with an empty items array, which the spec requires.
+func Write(w io.Writer, feed Feed) error {
+	feed.Version = Version
+	if feed.Items == nil {
+		feed.Items = []Item{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(feed)
+	if err != nil {
+		return fmt.Errorf("encode json feed: %w", err)
+	}
+
+	return nil
+}
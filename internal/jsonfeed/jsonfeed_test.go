@@ -0,0 +1,76 @@
+package jsonfeed_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"rss/internal/jsonfeed"
+)
+
+func TestWriteProducesJSONFeed(t *testing.T) {
+	t.Parallel()
+
+	published := time.Date(2026, time.March, 1, 9, 30, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+
+	err := jsonfeed.Write(&buf, jsonfeed.Feed{
+		Title:       "Example",
+		HomePageURL: "https://example.com/",
+		Items: []jsonfeed.Item{
+			{ID: "post-1", URL: "https://example.com/1", Title: "One", ContentHTML: "<p>Hi</p>", DatePublished: &published},
+			{ID: "post-2", Title: "Two"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var got map[string]any
+
+	err = json.Unmarshal(buf.Bytes(), &got)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if got["version"] != jsonfeed.Version || got["title"] != "Example" || got["home_page_url"] != "https://example.com/" {
+		t.Fatalf("unexpected feed fields %v", got)
+	}
+
+	items, ok := got["items"].([]any)
+	if !ok || len(items) != 2 {
+		t.Fatalf("expected two items, got %v", got["items"])
+	}
+
+	first, _ := items[0].(map[string]any)
+	if first["id"] != "post-1" || first["content_html"] != "<p>Hi</p>" ||
+		first["date_published"] != "2026-03-01T09:30:00Z" {
+		t.Fatalf("unexpected first item %v", first)
+	}
+
+	second, _ := items[1].(map[string]any)
+	if _, hasContent := second["content_html"]; !hasContent {
+		t.Fatalf("expected content_html on every item, got %v", second)
+	}
+
+	if _, hasDate := second["date_published"]; hasDate {
+		t.Fatalf("expected no date_published without a date, got %v", second)
+	}
+}
+
+func TestWriteEmptyFeedHasItemsArray(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	err := jsonfeed.Write(&buf, jsonfeed.Feed{Title: "Empty"})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"items": []`)) {
+		t.Fatalf("expected an empty items array, got %s", buf.String())
+	}
+}
@@ -0,0 +1,233 @@
+// Package mailbox turns emailed newsletters into feed items.
+package mailbox
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html/charset"
+
+	"rss/internal/content"
+)
+
+const (
+	// maxPartDepth bounds multipart nesting so a crafted message cannot
+	// recurse without limit.
+	maxPartDepth = 8
+	// untitledSubject is used for messages that arrive without a subject.
+	untitledSubject = "(no subject)"
+	// derivedGUIDPrefix marks GUIDs computed for messages without a Message-Id.
+	derivedGUIDPrefix = "mailbox-"
+)
+
+// ErrNoBody is returned when a message has no text/html or text/plain part.
+var ErrNoBody = errors.New("message has no readable body")
+
+// body collects the first HTML and plain-text parts found in a message.
+type body struct {
+	html string
+	text string
+}
+
+// ParseMessage reads one RFC 5322 message and converts it into a feed item:
+// the subject becomes the title, the Date header the published time, and the
+// Message-Id the GUID. The HTML part is preferred over plain text, and the
+// resulting content is sanitized since it comes from an arbitrary sender.
+func ParseMessage(r io.Reader) (*gofeed.Item, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+
+	var parts body
+
+	err = readPart(textproto.MIMEHeader(msg.Header), msg.Body, 0, &parts)
+	if err != nil {
+		return nil, err
+	}
+
+	var itemContent string
+
+	switch {
+	case strings.TrimSpace(parts.html) != "":
+		itemContent = content.SanitizeHTML(parts.html)
+	case strings.TrimSpace(parts.text) != "":
+		itemContent = textToHTML(parts.text)
+	default:
+		return nil, ErrNoBody
+	}
+
+	title := strings.TrimSpace(decodeHeader(msg.Header.Get("Subject")))
+	if title == "" {
+		title = untitledSubject
+	}
+
+	item := &gofeed.Item{
+		Title:   title,
+		Content: itemContent,
+		GUID:    strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+	}
+
+	if date, dateErr := msg.Header.Date(); dateErr == nil {
+		item.PublishedParsed = &date
+	}
+
+	if item.GUID == "" {
+		item.GUID = derivedGUID(msg.Header, itemContent)
+	}
+
+	return item, nil
+}
+
+func readPart(header textproto.MIMEHeader, reader io.Reader, depth int, parts *body) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// RFC 2045 defaults untyped bodies to plain text.
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if isAttachment(header) {
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if depth >= maxPartDepth {
+			return nil
+		}
+
+		return readMultipart(reader, params["boundary"], depth, parts)
+	case mediaType == "text/html" && parts.html == "":
+		decoded, err := decodePart(header, params, reader)
+		if err != nil {
+			return err
+		}
+
+		parts.html = decoded
+	case mediaType == "text/plain" && parts.text == "":
+		decoded, err := decodePart(header, params, reader)
+		if err != nil {
+			return err
+		}
+
+		parts.text = decoded
+	default:
+	}
+
+	return nil
+}
+
+func readMultipart(reader io.Reader, boundary string, depth int, parts *body) error {
+	if boundary == "" {
+		return nil
+	}
+
+	multipartReader := multipart.NewReader(reader, boundary)
+
+	for {
+		part, err := multipartReader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("read message part: %w", err)
+		}
+
+		err = readPart(part.Header, part, depth+1, parts)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func isAttachment(header textproto.MIMEHeader) bool {
+	disposition, _, err := mime.ParseMediaType(header.Get("Content-Disposition"))
+
+	return err == nil && disposition == "attachment"
+}
+
+// decodePart undoes the transfer encoding and converts the part's charset to
+// UTF-8.
+func decodePart(header textproto.MIMEHeader, params map[string]string, reader io.Reader) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "quoted-printable":
+		reader = quotedprintable.NewReader(reader)
+	case "base64":
+		reader = base64.NewDecoder(base64.StdEncoding, reader)
+	default:
+	}
+
+	if label := params["charset"]; label != "" {
+		converted, err := charset.NewReaderLabel(label, reader)
+		if err == nil {
+			reader = converted
+		}
+	}
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("decode message part: %w", err)
+	}
+
+	return string(decoded), nil
+}
+
+func decodeHeader(value string) string {
+	decoder := mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+	decoded, err := decoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+
+	return decoded
+}
+
+// textToHTML escapes a plain-text body and turns blank-line separated
+// paragraphs into <p> blocks.
+func textToHTML(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var builder strings.Builder
+
+	for paragraph := range strings.SplitSeq(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		builder.WriteString("<p>")
+		builder.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br/>"))
+		builder.WriteString("</p>")
+	}
+
+	return builder.String()
+}
+
+// derivedGUID identifies a message that has no Message-Id by its sender,
+// date, subject, and content, so a redelivered copy is not stored twice.
+func derivedGUID(header mail.Header, itemContent string) string {
+	sum := sha256.New()
+
+	for _, value := range []string{
+		header.Get("From"), header.Get("Date"), header.Get("Subject"), itemContent,
+	} {
+		sum.Write([]byte(value))
+		sum.Write([]byte{0})
+	}
+
+	return derivedGUIDPrefix + hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,102 @@
+package mailbox_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"rss/internal/mailbox"
+)
+
+const multipartMessage = "From: Weekly <news@example.com>\r\n" +
+	"Subject: =?UTF-8?Q?Caf=C3=A9_weekly?=\r\n" +
+	"Date: Mon, 02 Mar 2026 09:30:00 +0000\r\n" +
+	"Message-Id: <issue-42@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"Plain version\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/html; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<html><head><style>p{}</style></head><body><p onclick=3D\"x()\">Caf=E9 news</p>" +
+	"<script>alert(1)</script><img src=3D\"https://cdn.example.com/a.png\"></body></html>\r\n" +
+	"--b1--\r\n"
+
+func TestParseMessagePrefersSanitizedHTML(t *testing.T) {
+	t.Parallel()
+
+	item, err := mailbox.ParseMessage(strings.NewReader(multipartMessage))
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+
+	if item.Title != "Caf\u00e9 weekly" {
+		t.Fatalf("expected decoded subject, got %q", item.Title)
+	}
+
+	if item.GUID != "issue-42@example.com" {
+		t.Fatalf("expected message id as guid, got %q", item.GUID)
+	}
+
+	want := time.Date(2026, time.March, 2, 9, 30, 0, 0, time.UTC)
+	if item.PublishedParsed == nil || !item.PublishedParsed.Equal(want) {
+		t.Fatalf("expected published %v, got %v", want, item.PublishedParsed)
+	}
+
+	wantContent := "<p>Caf\u00e9 news</p>" + `<img src="https://cdn.example.com/a.png"/>`
+	if item.Content != wantContent {
+		t.Fatalf("expected content %q, got %q", wantContent, item.Content)
+	}
+}
+
+func TestParseMessagePlainText(t *testing.T) {
+	t.Parallel()
+
+	message := "From: news@example.com\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"Rmlyc3QgPGxpbmU+CnNlY29u\r\nZAoKTmV4dA==\r\n"
+
+	item, err := mailbox.ParseMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+
+	if item.Title != "(no subject)" {
+		t.Fatalf("expected placeholder title, got %q", item.Title)
+	}
+
+	if item.Content != "<p>First &lt;line&gt;<br/>second</p><p>Next</p>" {
+		t.Fatalf("unexpected content %q", item.Content)
+	}
+
+	if !strings.HasPrefix(item.GUID, "mailbox-") {
+		t.Fatalf("expected derived guid, got %q", item.GUID)
+	}
+}
+
+func TestParseMessageWithoutBody(t *testing.T) {
+	t.Parallel()
+
+	message := "Subject: Attachment only\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"b1\"\r\n" +
+		"\r\n" +
+		"--b1\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"Content-Disposition: attachment; filename=\"notes.txt\"\r\n" +
+		"\r\n" +
+		"attached\r\n" +
+		"--b1--\r\n"
+
+	_, err := mailbox.ParseMessage(strings.NewReader(message))
+	if !errors.Is(err, mailbox.ErrNoBody) {
+		t.Fatalf("expected ErrNoBody, got %v", err)
+	}
+}
@@ -2,11 +2,13 @@
 package opml
 
 import (
+	"bytes"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"strconv"
 	"strings"
 )
 
@@ -14,19 +16,38 @@ const (
 	opmlRootName = "opml"
 	opmlVersion  = "2.0"
 	xmlIndent    = "  "
+
+	// pulseNamespace qualifies the non-standard attributes Write adds to
+	// outlines, bound to the pulse prefix on the root element so the document
+	// stays well-formed and other readers can skip them.
+	pulseNamespace = "urn:pulse-rss:opml"
+
+	// maxDocumentBytes bounds how much of an OPML document Parse will read.
+	maxDocumentBytes = 2 << 20
+	// maxElements bounds the number of elements (outlines included) in one document.
+	maxElements = 10000
+	// maxDepth bounds element nesting so deeply nested outlines cannot exhaust the stack.
+	maxDepth = 64
 )
 
-// Subscription describes one feed entry in an OPML document.
+// Subscription describes one feed entry in an OPML document. UnreadCount is
+// only written, as the pulse:unreadCount attribute; Parse leaves it zero.
 type Subscription struct {
-	Title string
-	URL   string
+	Title       string
+	URL         string
+	UnreadCount int
 }
 
+// document maps the prefixed pulse attributes by their literal names: the
+// encoder writes them as is, and the decoder, which resolves the prefix to
+// pulseNamespace, never matches them, so Parse ignores them like any other
+// unknown attribute.
 type document struct {
-	XMLName xml.Name `xml:"opml"`
-	Version string   `xml:"version,attr,omitempty"`
-	Head    head     `xml:"head"`
-	Body    body     `xml:"body"`
+	XMLName    xml.Name `xml:"opml"`
+	Version    string   `xml:"version,attr,omitempty"`
+	PulseXMLNS string   `xml:"xmlns:pulse,attr,omitempty"`
+	Head       head     `xml:"head"`
+	Body       body     `xml:"body"`
 }
 
 type head struct {
@@ -37,23 +58,52 @@ type body struct {
 	Outlines []outline `xml:"outline"`
 }
 
+// outline accepts the attribute spellings exporters disagree on: xmlUrl in
+// any of its casings, and url from readers that use it for the feed.
 type outline struct {
-	Text      string    `xml:"text,attr,omitempty"`
-	Title     string    `xml:"title,attr,omitempty"`
-	Type      string    `xml:"type,attr,omitempty"`
-	XMLURL    string    `xml:"xmlUrl,attr,omitempty"`
-	XMLURLAlt string    `xml:"xmlurl,attr,omitempty"`
-	URL       string    `xml:"url,attr,omitempty"`
-	Outlines  []outline `xml:"outline,omitempty"`
+	Text        string    `xml:"text,attr,omitempty"`
+	Title       string    `xml:"title,attr,omitempty"`
+	Type        string    `xml:"type,attr,omitempty"`
+	XMLURL      string    `xml:"xmlUrl,attr,omitempty"`
+	XMLURLAlt   string    `xml:"xmlurl,attr,omitempty"`
+	XMLURLUpper string    `xml:"xmlURL,attr,omitempty"`
+	URL         string    `xml:"url,attr,omitempty"`
+	UnreadCount string    `xml:"pulse:unreadCount,attr,omitempty"`
+	Outlines    []outline `xml:"outline,omitempty"`
 }
 
-var errInvalidRoot = errors.New("invalid OPML: expected root <opml>")
+// ErrDocumentRejected reports an OPML document refused before decoding because it
+// is oversized, too deeply nested, or declares a DTD or entities.
+var ErrDocumentRejected = errors.New("OPML document rejected")
+
+var (
+	errInvalidRoot      = errors.New("invalid OPML: expected root <opml>")
+	errDocumentTooLarge = fmt.Errorf("%w: larger than %d bytes", ErrDocumentRejected, maxDocumentBytes)
+	errDirectiveFound   = fmt.Errorf("%w: DOCTYPE and entity declarations are not allowed", ErrDocumentRejected)
+	errTooManyElements  = fmt.Errorf("%w: more than %d elements", ErrDocumentRejected, maxElements)
+	errNestingTooDeep   = fmt.Errorf("%w: nested deeper than %d elements", ErrDocumentRejected, maxDepth)
+)
 
 // Parse decodes OPML data from r and returns discovered feed subscriptions.
+// Documents are screened first so hostile uploads fail fast with ErrDocumentRejected.
 func Parse(r io.Reader) ([]Subscription, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDocumentBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read OPML: %w", err)
+	}
+
+	if len(data) > maxDocumentBytes {
+		return nil, errDocumentTooLarge
+	}
+
+	err = screenDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
 	var doc document
 
-	err := xml.NewDecoder(r).Decode(&doc)
+	err = newDecoder(data).Decode(&doc)
 	if err != nil {
 		return nil, fmt.Errorf("invalid OPML: %w", err)
 	}
@@ -68,6 +118,55 @@ func Parse(r io.Reader) ([]Subscription, error) {
 	return out, nil
 }
 
+// newDecoder returns a strict decoder that only knows the predefined XML
+// entities, so no custom or external entity is ever resolved.
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+	decoder.Entity = nil
+
+	return decoder
+}
+
+// screenDocument walks the raw token stream, rejecting DTDs and entity
+// declarations and enforcing the element count and depth limits.
+func screenDocument(data []byte) error {
+	decoder := newDecoder(data)
+	elements := 0
+	depth := 0
+
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid OPML: %w", err)
+		}
+
+		switch token.(type) {
+		case xml.Directive:
+			return errDirectiveFound
+		case xml.StartElement:
+			elements++
+			depth++
+
+			if elements > maxElements {
+				return errTooManyElements
+			}
+
+			if depth > maxDepth {
+				return errNestingTooDeep
+			}
+		case xml.EndElement:
+			depth--
+		default:
+			// Character data, comments, and processing instructions need no screening.
+		}
+	}
+}
+
 // Write encodes subscriptions as an OPML document and writes it to writer.
 func Write(writer io.Writer, title string, subscriptions []Subscription) error {
 	doc := document{
@@ -75,9 +174,10 @@ func Write(writer io.Writer, title string, subscriptions []Subscription) error {
 			Space: "",
 			Local: opmlRootName,
 		},
-		Version: opmlVersion,
-		Head:    head{Title: strings.TrimSpace(title)},
-		Body:    body{Outlines: buildOutlines(subscriptions)},
+		Version:    opmlVersion,
+		PulseXMLNS: pulseNamespace,
+		Head:       head{Title: strings.TrimSpace(title)},
+		Body:       body{Outlines: buildOutlines(subscriptions)},
 	}
 
 	_, err := io.WriteString(writer, xml.Header)
@@ -132,13 +232,15 @@ func buildOutlines(subscriptions []Subscription) []outline {
 		}
 
 		outlines = append(outlines, outline{
-			Text:      feedTitle,
-			Title:     feedTitle,
-			Type:      "rss",
-			XMLURL:    feedURL,
-			XMLURLAlt: "",
-			URL:       "",
-			Outlines:  nil,
+			Text:        feedTitle,
+			Title:       feedTitle,
+			Type:        "rss",
+			XMLURL:      feedURL,
+			XMLURLAlt:   "",
+			XMLURLUpper: "",
+			URL:         "",
+			UnreadCount: strconv.Itoa(subscription.UnreadCount),
+			Outlines:    nil,
 		})
 	}
 
@@ -149,8 +251,12 @@ func appendOutlineSubscription(current *outline, out *[]Subscription) {
 	feedURL := firstTrimmedValue(
 		current.XMLURL,
 		current.XMLURLAlt,
-		current.URL,
+		current.XMLURLUpper,
 	)
+	if feedURL == "" && !linksElsewhere(current) {
+		feedURL = strings.TrimSpace(current.URL)
+	}
+
 	if feedURL == "" {
 		return
 	}
@@ -161,11 +267,24 @@ func appendOutlineSubscription(current *outline, out *[]Subscription) {
 	}
 
 	*out = append(*out, Subscription{
-		Title: feedTitle,
-		URL:   feedURL,
+		Title:       feedTitle,
+		URL:         feedURL,
+		UnreadCount: 0,
 	})
 }
 
+// linksElsewhere reports whether an outline's url points at a web page or
+// another OPML document (OPML 2.0's link and include types) rather than a
+// feed, as in Inoreader's exports.
+func linksElsewhere(current *outline) bool {
+	switch strings.ToLower(strings.TrimSpace(current.Type)) {
+	case "link", "include":
+		return true
+	default:
+		return false
+	}
+}
+
 func firstTrimmedValue(values ...string) string {
 	for _, value := range values {
 		trimmed := strings.TrimSpace(value)
@@ -3,6 +3,9 @@ package opml
 
 import (
 	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -63,6 +66,55 @@ func TestParseCollectsNestedSubscriptions(t *testing.T) {
 	}
 }
 
+func TestParseToleratesReaderExports(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		fixture  string
+		expected []Subscription
+	}{
+		{
+			fixture: "newsblur.opml",
+			expected: []Subscription{
+				{Title: "Alpha Weekly", URL: "https://alpha.example.com/feed.xml"},
+				{Title: "Beta Notes", URL: "https://beta.example.com/atom.xml"},
+				{Title: "Gamma Daily", URL: "https://gamma.example.com/rss"},
+			},
+		},
+		{
+			fixture: "inoreader.opml",
+			expected: []Subscription{
+				{Title: "Delta Report", URL: "https://delta.example.com/feed"},
+				{Title: "Epsilon Journal", URL: "https://epsilon.example.com/index.xml"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := os.ReadFile(filepath.Join("testdata", tc.fixture))
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+
+			got, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %d subscriptions, got %d: %+v", len(tc.expected), len(got), got)
+			}
+
+			for index := range tc.expected {
+				assertSubscription(t, got[index], tc.expected[index], index)
+			}
+		})
+	}
+}
+
 func TestWriteRoundTrip(t *testing.T) {
 	t.Parallel()
 
@@ -102,6 +154,84 @@ func TestWriteRoundTrip(t *testing.T) {
 	}
 }
 
+func TestWriteAddsUnreadCounts(t *testing.T) {
+	t.Parallel()
+
+	input := []Subscription{
+		{Title: "Alpha", URL: alphaFeedURL, UnreadCount: 3},
+		{Title: "Beta", URL: betaFeedURL, UnreadCount: 0},
+	}
+
+	var buf bytes.Buffer
+
+	err := Write(&buf, "Subscriptions", input)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		`<opml version="2.0" xmlns:pulse="urn:pulse-rss:opml">`,
+		`xmlUrl="https://example.com/alpha.xml" pulse:unreadCount="3"`,
+		`xmlUrl="https://example.com/beta.xml" pulse:unreadCount="0"`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output:\n%s", want, output)
+		}
+	}
+
+	got, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(got) != expectedRoundtripFeeds || got[0].UnreadCount != 0 || got[1].UnreadCount != 0 {
+		t.Fatalf("expected unread counts to be ignored on import, got %+v", got)
+	}
+}
+
+func TestParseRejectsEntityExpansionPayload(t *testing.T) {
+	t.Parallel()
+
+	input := `<?xml version="1.0"?>
+<!DOCTYPE opml [
+  <!ENTITY lol "lol">
+  <!ENTITY lol1 "&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;&lol;">
+  <!ENTITY lol2 "&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;&lol1;">
+  <!ENTITY lol3 "&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;&lol2;">
+]>
+<opml version="2.0">
+  <body>
+    <outline text="&lol3;" xmlUrl="https://example.com/alpha.xml" />
+  </body>
+</opml>`
+
+	_, err := Parse(strings.NewReader(input))
+	if !errors.Is(err, ErrDocumentRejected) {
+		t.Fatalf("expected entity expansion payload to be rejected, got %v", err)
+	}
+}
+
+func TestParseRejectsOversizedDocuments(t *testing.T) {
+	t.Parallel()
+
+	tooMany := "<opml><body>" + strings.Repeat(`<outline text="x"/>`, maxElements) + "</body></opml>"
+	tooDeep := "<opml><body>" + strings.Repeat("<outline>", maxDepth) +
+		strings.Repeat("</outline>", maxDepth) + "</body></opml>"
+	tooLarge := "<opml><body>" + strings.Repeat(" ", maxDocumentBytes) + "</body></opml>"
+
+	for name, input := range map[string]string{
+		"too many elements": tooMany,
+		"too deep":          tooDeep,
+		"too large":         tooLarge,
+	} {
+		_, err := Parse(strings.NewReader(input))
+		if !errors.Is(err, ErrDocumentRejected) {
+			t.Fatalf("%s: expected ErrDocumentRejected, got %v", name, err)
+		}
+	}
+}
+
 func assertSubscription(t *testing.T, got, want Subscription, index int) {
 	t.Helper()
 
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// activeItemHeader carries the item a page has selected, such as the one
+	// expanded for reading. The frontend sets it on every htmx request,
+	// including the item list's polls, so an open item keeps being reported.
+	activeItemHeader = "X-Active-Item"
+	// defaultActiveItemRetention is how long after its last report an active
+	// item is still kept from cleanup.
+	defaultActiveItemRetention = 10 * time.Minute
+	// maxActiveItems bounds how many reported items are remembered at once.
+	maxActiveItems = 256
+)
+
+// activeItemTracker remembers the items pages report as active, so read
+// cleanup does not delete an item from under the reader when it was read
+// longer ago than cleanup's retention but is still open.
+type activeItemTracker struct {
+	mu    sync.Mutex
+	items map[int64]time.Time
+}
+
+func newActiveItemTracker() *activeItemTracker {
+	tracker := new(activeItemTracker)
+	tracker.items = make(map[int64]time.Time)
+
+	return tracker
+}
+
+// touch records itemID as active at now. Once maxActiveItems are remembered,
+// new items are ignored until older ones expire.
+func (t *activeItemTracker) touch(itemID int64, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, known := t.items[itemID]
+	if !known && len(t.items) >= maxActiveItems {
+		return
+	}
+
+	t.items[itemID] = now
+}
+
+// active returns the items reported within retention of now and forgets the
+// rest.
+func (t *activeItemTracker) active(now time.Time, retention time.Duration) []int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	itemIDs := make([]int64, 0, len(t.items))
+
+	for itemID, seenAt := range t.items {
+		if now.Sub(seenAt) > retention {
+			delete(t.items, itemID)
+
+			continue
+		}
+
+		itemIDs = append(itemIDs, itemID)
+	}
+
+	return itemIDs
+}
+
+// withActiveItemTracking records the item a request reports as active. It
+// runs inside the auth middleware, so only signed-in pages are tracked.
+func (a *App) withActiveItemTracking(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		itemID := parseItemElementID(r.Header.Get(activeItemHeader))
+		if itemID > 0 {
+			a.activeItems.touch(itemID, time.Now())
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
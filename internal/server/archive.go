@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+
+	"rss/internal/store"
+)
+
+// handleArchiveFeed stops refreshing a feed and moves it to the sidebar's
+// archived section, keeping its items readable.
+func (a *App) handleArchiveFeed(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	err := store.ArchiveFeed(r.Context(), a.db, feedID)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to archive feed")
+
+		return
+	}
+
+	a.renderItemListResponse(w, r, feedID)
+}
+
+// handleUnarchiveFeed returns an archived feed to the active list.
+func (a *App) handleUnarchiveFeed(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	err := store.UnarchiveFeed(r.Context(), a.db, feedID)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to unarchive feed")
+
+		return
+	}
+
+	a.renderItemListResponse(w, r, feedID)
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"rss/internal/auth"
+	"rss/internal/view"
 )
 
 const (
@@ -30,6 +31,7 @@ const (
 	setupNonceTokenBytes   = 18
 	authFailureMessage     = "authentication failed"
 	requestIDTokenBytes    = 16
+	cspNonceTokenBytes     = 16
 	authRateRefillPerSec   = 5.0
 	authRateMaxTokens      = 20.0
 	authFailureThreshold   = 5
@@ -47,15 +49,16 @@ const (
 	authPrincipalContextKey authContextKey = "authPrincipal"
 	authRealIPContextKey    authContextKey = "realIP"
 	authRequestIDContextKey authContextKey = "requestID"
+	cspNonceContextKey      authContextKey = "cspNonce"
 )
 
 // AuthConfig controls optional passkey authentication features.
 type AuthConfig struct {
 	RPID         string
-	RPOrigin     string
 	RPName       string
 	SetupToken   string
 	CookieName   string
+	RPOrigins    []string
 	SessionTTL   time.Duration
 	ChallengeTTL time.Duration
 	Enabled      bool
@@ -197,7 +200,7 @@ func (a *App) SetAuthConfig(cfg *AuthConfig) error {
 
 	manager, err := auth.NewManager(a.db, &auth.Config{
 		RPID:         strings.TrimSpace(cfg.RPID),
-		RPOrigin:     strings.TrimSpace(cfg.RPOrigin),
+		RPOrigins:    cfg.RPOrigins,
 		RPName:       strings.TrimSpace(cfg.RPName),
 		SessionTTL:   cfg.SessionTTL,
 		ChallengeTTL: cfg.ChallengeTTL,
@@ -235,6 +238,71 @@ func (*App) withRequestID(next http.Handler) http.Handler {
 	})
 }
 
+// withRequestLogging wraps the whole middleware chain so requests rejected by
+// auth are logged too; the request ID is read back from the response header
+// set by withRequestID.
+func (a *App) withRequestLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: 0, size: 0}
+
+		next.ServeHTTP(recorder, r)
+
+		slog.Log(r.Context(), a.requestLogLevel, "http request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.statusCode(),
+			"size", recorder.size,
+			"duration", time.Since(start),
+			"request_id", w.Header().Get("X-Request-ID"),
+		)
+	})
+}
+
+// statusRecorder captures the status code and body size written by handlers.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status int
+	size   int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(body []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+
+	n, err := s.ResponseWriter.Write(body)
+	s.size += n
+
+	if err != nil {
+		return n, fmt.Errorf("write response: %w", err)
+	}
+
+	return n, nil
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+func (s *statusRecorder) statusCode() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+
+	return s.status
+}
+
 func (*App) withRealIP(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ip := realIPFromRequest(r)
@@ -243,6 +311,23 @@ func (*App) withRealIP(next http.Handler) http.Handler {
 	})
 }
 
+// withCSPNonce issues a fresh nonce per request for withSecurityHeaders to
+// allowlist and templates to stamp on their inline <style> and <script> blocks.
+func (*App) withCSPNonce(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce, err := randomToken(cspNonceTokenBytes)
+		if err != nil {
+			slog.Warn("csp nonce generation failed", "err", err)
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), cspNonceContextKey, nonce)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (*App) withSecurityHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-Frame-Options", "DENY")
@@ -250,17 +335,28 @@ func (*App) withSecurityHeaders(next http.Handler) http.Handler {
 		w.Header().Set("Referrer-Policy", "no-referrer")
 		w.Header().Set("Permissions-Policy", "camera=(), microphone=(), geolocation=()")
 		w.Header().Set("Cross-Origin-Resource-Policy", "same-origin")
-		w.Header().Set(
-			"Content-Security-Policy",
-			"default-src 'self'; script-src 'self'; style-src 'self'; font-src 'self'; "+
-				"img-src 'self' data:; connect-src 'self'; object-src 'none'; base-uri 'self'; "+
-				"frame-ancestors 'none'; form-action 'self'",
-		)
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy(requestCSPNonce(r)))
 
 		next.ServeHTTP(w, r)
 	})
 }
 
+// contentSecurityPolicy builds the strict policy, letting inline scripts and
+// styles through only when they carry the request's nonce.
+func contentSecurityPolicy(nonce string) string {
+	scriptSrc := "script-src 'self'"
+	styleSrc := "style-src 'self'"
+
+	if nonce != "" {
+		scriptSrc += " 'nonce-" + nonce + "'"
+		styleSrc += " 'nonce-" + nonce + "'"
+	}
+
+	return "default-src 'self'; " + scriptSrc + "; " + styleSrc + "; font-src 'self'; " +
+		"img-src 'self' data:; connect-src 'self'; object-src 'none'; base-uri 'self'; " +
+		"frame-ancestors 'none'; form-action 'self'"
+}
+
 func (a *App) withAuthRateLimit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !a.authEnabled || !strings.HasPrefix(r.URL.Path, "/auth/") {
@@ -329,7 +425,7 @@ func (*App) redirectIfAlreadyAuthenticated(w http.ResponseWriter, r *http.Reques
 }
 
 func (a *App) rejectIfAuthRequiredAndMissing(w http.ResponseWriter, r *http.Request) bool {
-	if !pathRequiresAuth(r.URL.Path) {
+	if !pathRequiresAuth(r.URL.Path) || a.isKioskRequest(r) {
 		return false
 	}
 
@@ -407,7 +503,10 @@ func csrfTokenMatches(r *http.Request, expected string) (bool, error) {
 }
 
 func pathRequiresAuth(path string) bool {
-	if path == "/healthz" || strings.HasPrefix(path, "/static/") {
+	// Newsletter ingest and shared feed pages are authorized by the per-feed
+	// token in the path.
+	if path == "/healthz" || strings.HasPrefix(path, "/static/") || strings.HasPrefix(path, "/ingest/") ||
+		strings.HasPrefix(path, "/share/") {
 		return false
 	}
 
@@ -485,6 +584,14 @@ func realIPFromRequest(r *http.Request) string {
 	return strings.TrimSpace(r.RemoteAddr)
 }
 
+// requestCSPNonce returns the request's CSP nonce, or "" when none was issued
+// and inline blocks must be left out.
+func requestCSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceContextKey).(string)
+
+	return nonce
+}
+
 func requestRealIP(r *http.Request) string {
 	raw := r.Context().Value(authRealIPContextKey)
 	if raw == nil {
@@ -499,6 +606,17 @@ func requestRealIP(r *http.Request) string {
 	return ip
 }
 
+// requestID returns the ID withRequestID gave the request, or "" outside that
+// middleware.
+func requestID(r *http.Request) string {
+	id, ok := r.Context().Value(authRequestIDContextKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return id
+}
+
 func (a *App) recordAuthFailure(r *http.Request) {
 	if a.authRateLimiter == nil {
 		return
@@ -690,7 +808,7 @@ func (a *App) handleAuthLoginVerify(w http.ResponseWriter, r *http.Request) {
 
 	authRequest := requestWithJSONBody(r, body)
 
-	issue, err := a.authManager.FinishDiscoverableLogin(r.Context(), request.ChallengeID, authRequest)
+	issue, err := a.authManager.FinishDiscoverableLogin(r.Context(), request.ChallengeID, authRequest, sessionClient(r))
 	if err != nil {
 		slog.Warn("passkey login verify failed")
 		a.recordAuthFailure(r)
@@ -836,7 +954,7 @@ func (a *App) handleAuthRegisterVerify(w http.ResponseWriter, r *http.Request) {
 func (a *App) issueOrRotateSession(r *http.Request, userID int64) (auth.SessionIssue, error) {
 	cookie, err := r.Cookie(a.authCookieName)
 	if err != nil {
-		issue, createErr := a.authManager.CreateSessionForUser(r.Context(), userID)
+		issue, createErr := a.authManager.CreateSessionForUser(r.Context(), userID, sessionClient(r))
 		if createErr != nil {
 			return auth.SessionIssue{}, fmt.Errorf("create auth session: %w", createErr)
 		}
@@ -844,7 +962,7 @@ func (a *App) issueOrRotateSession(r *http.Request, userID int64) (auth.SessionI
 		return issue, nil
 	}
 
-	issue, rotateErr := a.authManager.RotateSession(r.Context(), cookie.Value, userID)
+	issue, rotateErr := a.authManager.RotateSession(r.Context(), cookie.Value, userID, sessionClient(r))
 	if rotateErr != nil {
 		return auth.SessionIssue{}, fmt.Errorf("rotate auth session: %w", rotateErr)
 	}
@@ -852,6 +970,12 @@ func (a *App) issueOrRotateSession(r *http.Request, userID int64) (auth.SessionI
 	return issue, nil
 }
 
+// sessionClient describes the requester for the session list: the client IP
+// as resolved through trusted proxies, and its User-Agent.
+func sessionClient(r *http.Request) auth.SessionClient {
+	return auth.SessionClient{IP: requestRealIP(r), UserAgent: r.UserAgent()}
+}
+
 func (a *App) registrationUserID(r *http.Request) (int64, bool) {
 	principal, ok := currentPrincipal(r)
 	if ok {
@@ -888,6 +1012,41 @@ func (a *App) handleAuthLogout(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
 }
 
+// handleAuthSessionRevoke signs out one of the owner's sessions. Revoking the
+// session making the request signs this browser out too.
+func (a *App) handleAuthSessionRevoke(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(r)
+	if !ok {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+
+		return
+	}
+
+	sessionID := strings.TrimSpace(r.PathValue("sessionID"))
+
+	err := a.authManager.RevokeSession(r.Context(), principal.UserID, sessionID)
+	if errors.Is(err, auth.ErrInvalidSession) {
+		http.Error(w, "session not found", http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, "failed to revoke session", http.StatusInternalServerError)
+
+		return
+	}
+
+	if sessionID == principal.SessionID {
+		a.clearAuthSessionCookie(w)
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+
+		return
+	}
+
+	a.renderSecurityPage(w, r, principal, "Session signed out.", "")
+}
+
 func (a *App) handleAuthSecurity(w http.ResponseWriter, r *http.Request) {
 	principal, ok := currentPrincipal(r)
 	if !ok {
@@ -921,8 +1080,16 @@ func (a *App) renderSecurityPage(
 		return
 	}
 
+	sessions, err := a.authManager.ListSessions(r.Context(), principal.UserID)
+	if err != nil {
+		http.Error(w, "failed to load sessions", http.StatusInternalServerError)
+
+		return
+	}
+
 	data := authSecurityPageData{
 		CSRFToken:          principal.CSRFToken,
+		Sessions:           authSessionViews(sessions, principal.SessionID, time.Now()),
 		PasskeyCount:       credentials,
 		HasRecoveryCode:    hasRecoveryCode,
 		RecoveryCode:       recoveryCode,
@@ -934,6 +1101,22 @@ func (a *App) renderSecurityPage(
 	a.renderTemplate(w, "auth_security", data)
 }
 
+func authSessionViews(sessions []auth.SessionInfo, currentSessionID string, now time.Time) []authSessionView {
+	views := make([]authSessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, authSessionView{
+			ID:        session.SessionID,
+			SignedIn:  view.FormatTime(session.CreatedAt),
+			LastSeen:  view.FormatRelativeShort(session.LastSeenAt, now) + " ago",
+			IP:        session.IP,
+			UserAgent: session.UserAgent,
+			Current:   session.SessionID == currentSessionID,
+		})
+	}
+
+	return views
+}
+
 func (a *App) handleAuthRecoveryGenerate(w http.ResponseWriter, r *http.Request) {
 	principal, ok := currentPrincipal(r)
 	if !ok {
@@ -4,6 +4,7 @@ package server
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,6 +12,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mmcdole/gofeed"
+
+	"rss/internal/auth"
 	"rss/internal/store"
 )
 
@@ -22,10 +26,10 @@ func newAuthEnabledTestApp(t *testing.T) *App {
 	err := app.SetAuthConfig(&AuthConfig{
 		Enabled:      true,
 		RPID:         "example.com",
-		RPOrigin:     "https://example.com",
 		RPName:       "Pulse RSS",
 		SetupToken:   "setup-token",
 		CookieName:   "",
+		RPOrigins:    []string{"https://example.com"},
 		SessionTTL:   24 * time.Hour,
 		ChallengeTTL: 5 * time.Minute,
 		CookieSecure: false,
@@ -45,7 +49,7 @@ func issueAuthCookie(t *testing.T, app *App) *http.Cookie {
 		t.Fatalf("EnsureOwner: %v", err)
 	}
 
-	issue, err := app.authManager.CreateSessionForUser(context.Background(), owner.ID)
+	issue, err := app.authManager.CreateSessionForUser(context.Background(), owner.ID, auth.SessionClient{})
 	if err != nil {
 		t.Fatalf("CreateSessionForUser: %v", err)
 	}
@@ -135,6 +139,45 @@ func TestAuthRedirectsUnauthenticatedRequestsToLoginAfterInitialCode(t *testing.
 	}
 }
 
+func TestSecurityHeadersIssuePerRequestCSPNonce(t *testing.T) {
+	t.Parallel()
+
+	app := newTestApp(t)
+
+	nonces := make(map[string]bool)
+
+	for range 2 {
+		rr := getRequest(app, "/")
+
+		csp := rr.Header().Get("Content-Security-Policy")
+
+		_, nonce, found := strings.Cut(csp, "'nonce-")
+		if !found {
+			t.Fatalf("expected nonce in CSP, got %q", csp)
+		}
+
+		nonce, _, _ = strings.Cut(nonce, "'")
+		for _, directive := range []string{
+			"script-src 'self' 'nonce-" + nonce + "'",
+			"style-src 'self' 'nonce-" + nonce + "'",
+		} {
+			if !strings.Contains(csp, directive) {
+				t.Fatalf("expected %q in CSP, got %q", directive, csp)
+			}
+		}
+
+		if strings.Contains(csp, "unsafe-inline") {
+			t.Fatalf("expected no unsafe-inline in CSP, got %q", csp)
+		}
+
+		nonces[nonce] = true
+	}
+
+	if len(nonces) != 2 {
+		t.Fatal("expected a fresh nonce per request")
+	}
+}
+
 func TestAuthSecurityHeadersOnLoginPage(t *testing.T) {
 	t.Parallel()
 
@@ -186,6 +229,120 @@ func TestAuthCSRFRequiredForUnsafeRequests(t *testing.T) {
 	}
 }
 
+func TestAuthIngestUsesTokenInsteadOfSession(t *testing.T) {
+	t.Parallel()
+
+	app := newAuthEnabledTestApp(t)
+
+	_, err := store.CreateMailboxFeed(context.Background(), app.db, "Letters", "ingest-token")
+	if err != nil {
+		t.Fatalf("CreateMailboxFeed: %v", err)
+	}
+
+	message := "Subject: Hello\r\nContent-Type: text/plain\r\n\r\nBody\r\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/ingest/ingest-token", strings.NewReader(message))
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected ingest without session to be accepted, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/feeds/mailbox", strings.NewReader("title=Other"))
+	rr = httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	if rr.Code == http.StatusOK {
+		t.Fatal("expected creating a mailbox feed to require a session")
+	}
+}
+
+func TestAuthSharedFeedUsesTokenInsteadOfSession(t *testing.T) {
+	t.Parallel()
+
+	app := newAuthEnabledTestApp(t)
+
+	feedID, err := store.UpsertFeed(context.Background(), app.db, exampleRSSURL, "Shared")
+	if err != nil {
+		t.Fatalf("UpsertFeed: %v", err)
+	}
+
+	err = store.SetFeedShareToken(context.Background(), app.db, feedID, "share-token")
+	if err != nil {
+		t.Fatalf("SetFeedShareToken: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/share/share-token", http.NoBody)
+	rr := httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected shared feed without session to render, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/feeds/%d/share", feedID), http.NoBody)
+	rr = httptest.NewRecorder()
+	app.Routes().ServeHTTP(rr, req)
+
+	if rr.Code == http.StatusOK {
+		t.Fatal("expected creating a share link to require a session")
+	}
+}
+
+func TestAuthKioskOpensOnlyTheKioskPage(t *testing.T) {
+	t.Parallel()
+
+	app := newAuthEnabledTestApp(t)
+
+	feedID, err := store.UpsertFeed(context.Background(), app.db, exampleRSSURL, "Wall News")
+	if err != nil {
+		t.Fatalf("UpsertFeed: %v", err)
+	}
+
+	mustUpsertItems(t, app, feedID, []*gofeed.Item{
+		newGofeedItem("Headline on the wall", "https://example.com/wall", "wall", "", nil),
+	})
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, http.NoBody)
+		rr := httptest.NewRecorder()
+		app.Routes().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	rr := serve(http.MethodGet, "/kiosk")
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected kiosk to require a session while kiosk mode is off, got %d", rr.Code)
+	}
+
+	app.SetKioskEnabled(true)
+
+	rr = serve(http.MethodGet, "/kiosk")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected kiosk without session to render, got %d", rr.Code)
+	}
+
+	body := rr.Body.String()
+	assertContains(t, body, "Headline on the wall", "expected unread headline on the kiosk")
+	assertContains(t, body, "Wall News", "expected feed title on the kiosk")
+	assertContains(t, body, `http-equiv="refresh"`, "expected the kiosk to reload itself")
+	assertNotContains(t, body, "hx-post", "expected no controls on the kiosk")
+
+	for _, target := range []string{"/", fmt.Sprintf("/feeds/%d/items", feedID), "/items/today"} {
+		rr = serve(http.MethodGet, target)
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("expected %s to still require a session, got %d", target, rr.Code)
+		}
+	}
+
+	rr = serve(http.MethodPost, fmt.Sprintf("/feeds/%d/items/read", feedID))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected marking read to still require a session, got %d", rr.Code)
+	}
+}
+
 func TestAuthLoginVerifyRejectsInvalidChallenge(t *testing.T) {
 	t.Parallel()
 
@@ -223,6 +380,57 @@ func TestAuthRegisterOptionsRequiresSetupOrSession(t *testing.T) {
 	}
 }
 
+func TestVerifyConsistencyRequiresSession(t *testing.T) {
+	t.Parallel()
+
+	app := newAuthEnabledTestApp(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/consistency", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	app.Routes().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized consistency check without a session, got %d", rr.Code)
+	}
+}
+
+func TestAuthRejectedRequestsCarryRequestID(t *testing.T) {
+	t.Parallel()
+
+	app := newAuthEnabledTestApp(t)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/admin/consistency", http.NoBody),
+		httptest.NewRequest(http.MethodGet, pathIndex, http.NoBody),
+	} {
+		rr := httptest.NewRecorder()
+
+		app.Routes().ServeHTTP(rr, req)
+
+		if rr.Code == http.StatusOK || rr.Header().Get("X-Request-ID") == "" {
+			t.Fatalf("expected %s %s to be rejected with a request ID, got %d %q",
+				req.Method, req.URL.Path, rr.Code, rr.Header().Get("X-Request-ID"))
+		}
+	}
+}
+
+func TestFeedJSONRequiresSession(t *testing.T) {
+	t.Parallel()
+
+	app := newAuthEnabledTestApp(t)
+	feedID := mustUpsertFeed(t, app, exampleRSSURL, "Private Feed")
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/feeds/%d/feed.json", feedID), http.NoBody)
+	rr := httptest.NewRecorder()
+
+	app.Routes().ServeHTTP(rr, req)
+
+	if rr.Code == http.StatusOK || strings.Contains(rr.Body.String(), "Private Feed") {
+		t.Fatalf("expected the JSON Feed to require a session, got %d %q", rr.Code, rr.Body.String())
+	}
+}
+
 func TestAuthSetupUnlockRequiresToken(t *testing.T) {
 	t.Parallel()
 
@@ -351,10 +559,10 @@ func TestAuthSessionExpiryRedirectsToLogin(t *testing.T) {
 	err := app.SetAuthConfig(&AuthConfig{
 		Enabled:      true,
 		RPID:         "example.com",
-		RPOrigin:     "https://example.com",
 		RPName:       "Pulse RSS",
 		SetupToken:   "setup-token",
 		CookieName:   "",
+		RPOrigins:    []string{"https://example.com"},
 		SessionTTL:   40 * time.Millisecond,
 		ChallengeTTL: 5 * time.Minute,
 		CookieSecure: false,
@@ -383,3 +591,59 @@ func TestAuthSessionExpiryRedirectsToLogin(t *testing.T) {
 		t.Fatalf("expected redirect to login, got %q", rr.Header().Get("Location"))
 	}
 }
+
+func TestAuthSecurityPageListsAndRevokesSessions(t *testing.T) {
+	t.Parallel()
+
+	app := newAuthEnabledTestApp(t)
+	seedAuthCredential(t, app)
+
+	owner, err := app.authManager.EnsureOwner(context.Background())
+	if err != nil {
+		t.Fatalf("EnsureOwner: %v", err)
+	}
+
+	current, err := app.authManager.CreateSessionForUser(context.Background(), owner.ID, auth.SessionClient{
+		IP:        "192.0.2.10",
+		UserAgent: "Firefox on laptop",
+	})
+	if err != nil {
+		t.Fatalf("CreateSessionForUser(current): %v", err)
+	}
+
+	other, err := app.authManager.CreateSessionForUser(context.Background(), owner.ID, auth.SessionClient{
+		IP:        "198.51.100.7",
+		UserAgent: "Safari on phone",
+	})
+	if err != nil {
+		t.Fatalf("CreateSessionForUser(other): %v", err)
+	}
+
+	cookie := &http.Cookie{Name: app.authCookieName, Value: current.CookieValue}
+
+	rr := getRequest(app, "/auth/security", cookie)
+	assertResponseCode(t, rr, "security page status")
+	assertContains(t, rr.Body.String(), "Safari on phone", "other session user agent")
+	assertContains(t, rr.Body.String(), "198.51.100.7", "other session address")
+	assertContains(t, rr.Body.String(), "/auth/sessions/"+other.SessionID+"/revoke", "revoke form")
+
+	form := url.Values{"csrf_token": {current.CSRFToken}}
+
+	rr = postFormRequest(app, "/auth/sessions/"+other.SessionID+"/revoke", form, cookie)
+	assertResponseCode(t, rr, "revoke session status")
+	assertNotContains(t, rr.Body.String(), "Safari on phone", "revoked session")
+
+	session, err := store.GetAuthSessionByID(context.Background(), app.db, other.SessionID)
+	if err != nil {
+		t.Fatalf("GetAuthSessionByID: %v", err)
+	}
+
+	if !session.RevokedAt.Valid {
+		t.Fatal("expected other session to be revoked")
+	}
+
+	rr = postFormRequest(app, "/auth/sessions/"+other.SessionID+"/revoke", form, cookie)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected not found for an already revoked session, got %d", rr.Code)
+	}
+}
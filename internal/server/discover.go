@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"rss/internal/feed"
+	"rss/internal/store"
+
+	_ "embed"
+)
+
+// recommendedFeedsJSON is the curated list the discover view offers new
+// users, grouped by topic.
+//
+//go:embed recommended.json
+var recommendedFeedsJSON []byte
+
+type recommendedTopic struct {
+	Topic string            `json:"topic"`
+	Feeds []recommendedFeed `json:"feeds"`
+}
+
+// recommendedFeed is one entry of recommended.json. Subscribed is filled in
+// per request for feeds the reader already follows.
+type recommendedFeed struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Subscribed  bool   `json:"-"`
+}
+
+func loadRecommendedFeeds() ([]recommendedTopic, error) {
+	var topics []recommendedTopic
+
+	err := json.Unmarshal(recommendedFeedsJSON, &topics)
+	if err != nil {
+		return nil, fmt.Errorf("decode recommended feeds: %w", err)
+	}
+
+	return topics, nil
+}
+
+// handleDiscover lists the recommended feeds, each with a subscribe button
+// posting to /feeds like the subscribe form does.
+func (a *App) handleDiscover(w http.ResponseWriter, r *http.Request) {
+	topics, err := loadRecommendedFeeds()
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load recommended feeds")
+
+		return
+	}
+
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	subscribed := make(map[string]bool, len(feeds))
+	for idx := range feeds {
+		subscribed[feeds[idx].URL] = true
+	}
+
+	for i := range topics {
+		for j := range topics[i].Feeds {
+			feedURL, normalizeErr := feed.NormalizeURL(topics[i].Feeds[j].URL)
+			topics[i].Feeds[j].Subscribed = normalizeErr == nil && subscribed[feedURL]
+		}
+	}
+
+	a.renderTemplate(w, "discover_response", discoverResponseData{
+		Topics:         topics,
+		Feeds:          feeds,
+		SelectedFeedID: 0,
+		FeedEditMode:   feedEditModeEnabled(r),
+	})
+}
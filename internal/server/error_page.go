@@ -0,0 +1,56 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+const (
+	// errorTargetSelector is where htmx requests show a failure, whatever
+	// element they meant to swap.
+	errorTargetSelector = "#app-error"
+	htmxRequestHeader   = "HX-Request"
+)
+
+// renderError answers a failed request from the app's pages with a styled
+// message instead of http.Error's plain text: htmx requests get a partial
+// retargeted into the page's error region, and full page loads get a page of
+// their own. Server errors are logged and show the request ID, so a report
+// can be matched to the log line. Machine endpoints (polls, JSON, downloads,
+// the image proxy) keep using http.Error.
+func (a *App) renderError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	data := errorPageData{
+		Message:     message,
+		RequestID:   requestID(r),
+		Status:      status,
+		StatusText:  http.StatusText(status),
+		ServerError: status >= http.StatusInternalServerError,
+	}
+
+	if data.ServerError {
+		slog.Error("request failed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"message", message,
+			"request_id", data.RequestID,
+		)
+	}
+
+	name := "error_page"
+	if r.Header.Get(htmxRequestHeader) == "true" {
+		name = "error_message"
+
+		w.Header().Set("HX-Retarget", errorTargetSelector)
+		w.Header().Set("HX-Reswap", "innerHTML")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	err := a.tmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		slog.Error("error template execute failed", "err", err)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"rss/internal/jsonfeed"
+	"rss/internal/store"
+)
+
+// jsonFeedItemLimit bounds how many of a feed's newest items its JSON Feed
+// lists.
+const jsonFeedItemLimit = 100
+
+// handleFeedJSON re-serves a feed's stored items as a JSON Feed 1.1
+// document, so other tools can read the deduplicated, sanitized copy kept
+// here. Like every page but the share and kiosk pages, it requires a session
+// when authentication is on.
+func (a *App) handleFeedJSON(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	localFeed, err := store.GetFeed(r.Context(), a.db, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, "failed to load feed", http.StatusInternalServerError)
+
+		return
+	}
+
+	items, err := store.ListJSONFeedItems(r.Context(), a.db, feedID, jsonFeedItemLimit)
+	if err != nil {
+		http.Error(w, "failed to load feed items", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+
+	err = jsonfeed.Write(w, jsonfeed.Feed{
+		Version:     jsonfeed.Version,
+		Title:       localFeed.Title,
+		HomePageURL: localFeed.SiteURL,
+		Description: localFeed.Description,
+		Language:    localFeed.Language,
+		Items:       items,
+	})
+	if err != nil {
+		slog.Warn("write json feed failed", "feed_id", feedID, "err", err)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"rss/internal/store"
+)
+
+const (
+	kioskPath = "/kiosk"
+	// kioskItemLimit bounds how many headlines the kiosk page lists.
+	kioskItemLimit = 30
+	// kioskRefreshSeconds is how often the kiosk page reloads itself.
+	kioskRefreshSeconds = 60
+)
+
+// isKioskRequest reports whether r reads the kiosk page while kiosk mode is
+// on. Only that page skips the session check; every other route, including
+// those the kiosk page would need to act on items, still requires one.
+func (a *App) isKioskRequest(r *http.Request) bool {
+	return a.kioskEnabled && r.URL.Path == kioskPath && (r.Method == http.MethodGet || r.Method == http.MethodHead)
+}
+
+// handleKiosk renders the newest unread headlines across feeds for a shared
+// display. The page reloads itself and has no controls, so it never changes
+// read state. Like share pages it is rate limited per client IP.
+func (a *App) handleKiosk(w http.ResponseWriter, r *http.Request) {
+	if !a.kioskEnabled {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if !a.shareRateLimiter.allow(requestRealIP(r), time.Now().UTC()) {
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+
+		return
+	}
+
+	items, err := store.ListRecentUnreadItems(r.Context(), a.db, kioskItemLimit)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load items")
+
+		return
+	}
+
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	titles := make(map[int64]string, len(feeds))
+	for idx := range feeds {
+		titles[feeds[idx].ID] = feeds[idx].Title
+	}
+
+	a.renderTemplate(w, "kiosk", kioskPageData{
+		Items:          items,
+		FeedTitles:     titles,
+		RefreshSeconds: kioskRefreshSeconds,
+	})
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rss/internal/view"
+)
+
+const jsonMediaType = "application/json"
+
+// wantsJSON reports whether the request asks for JSON ahead of HTML in its
+// Accept header, so the item endpoints can serve integrations from the same
+// routes as the UI. htmx requests always get HTML, and so does an Accept
+// header that ranks JSON no higher than HTML or a wildcard.
+func wantsJSON(r *http.Request) bool {
+	if r.Header.Get(htmxRequestHeader) == "true" {
+		return false
+	}
+
+	var jsonQuality, htmlQuality float64
+
+	for _, accept := range r.Header.Values("Accept") {
+		for entry := range strings.SplitSeq(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+			if err != nil {
+				continue
+			}
+
+			quality := acceptQuality(params)
+
+			switch mediaType {
+			case jsonMediaType:
+				jsonQuality = max(jsonQuality, quality)
+			case "text/html", "text/*", "*/*":
+				htmlQuality = max(htmlQuality, quality)
+			default:
+			}
+		}
+	}
+
+	return jsonQuality > htmlQuality
+}
+
+// acceptQuality reads an Accept entry's q parameter, which defaults to 1.
+func acceptQuality(params map[string]string) float64 {
+	raw, ok := params["q"]
+	if !ok {
+		return 1
+	}
+
+	quality, err := strconv.ParseFloat(raw, 64)
+	if err != nil || quality < 0 {
+		return 0
+	}
+
+	return min(quality, 1)
+}
+
+// itemJSON is the JSON form of an item. It carries what an integration needs
+// to show or act on the item and none of the template-only state. Times are
+// RFC 3339 in UTC and left out when the feed did not give them.
+type itemJSON struct {
+	Published    time.Time `json:"published,omitzero"`
+	Updated      time.Time `json:"updated,omitzero"`
+	Title        string    `json:"title"`
+	Link         string    `json:"link"`
+	SummaryHTML  string    `json:"summary_html"`
+	Language     string    `json:"language,omitempty"`
+	Categories   []string  `json:"categories"`
+	ID           int64     `json:"id"`
+	FeedID       int64     `json:"feed_id"`
+	WordCount    int       `json:"word_count"`
+	Read         bool      `json:"read"`
+	HasEnclosure bool      `json:"has_enclosure"`
+}
+
+// feedJSON is the JSON form of a feed. Its URL is the display URL, so a feed
+// marked secret stays masked, and it leaves out the proxy, the ingest and
+// share tokens, and the rest of the fetch settings.
+type feedJSON struct {
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	SiteURL   string `json:"site_url,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+	ID        int64  `json:"id"`
+	Unread    int    `json:"unread"`
+}
+
+// itemListJSON is the JSON form of a feed's first page of items.
+type itemListJSON struct {
+	Feed  feedJSON   `json:"feed"`
+	Items []itemJSON `json:"items"`
+}
+
+func newItemJSON(item view.ItemView) itemJSON {
+	categories := make([]string, 0, len(item.Categories))
+	for _, category := range item.Categories {
+		categories = append(categories, category.Name)
+	}
+
+	return itemJSON{
+		Title:        item.Title,
+		Link:         item.Link,
+		SummaryHTML:  string(item.SummaryHTML),
+		Language:     item.Language,
+		Published:    item.PublishedAt,
+		Updated:      item.UpdatedAt,
+		Categories:   categories,
+		ID:           item.ID,
+		FeedID:       item.FeedID,
+		WordCount:    item.WordCount,
+		Read:         item.IsRead,
+		HasEnclosure: item.HasEnclosure,
+	}
+}
+
+func newItemListJSON(itemList *view.ItemListData) itemListJSON {
+	items := make([]itemJSON, 0, len(itemList.Items))
+	for _, item := range itemList.Items {
+		items = append(items, newItemJSON(item))
+	}
+
+	return itemListJSON{
+		Feed: feedJSON{
+			Title:     itemList.Feed.Title,
+			URL:       itemList.Feed.URLDisplay,
+			SiteURL:   itemList.Feed.SiteURL,
+			LastError: itemList.Feed.LastError,
+			ID:        itemList.Feed.ID,
+			Unread:    itemList.Feed.UnreadCount,
+		},
+		Items: items,
+	}
+}
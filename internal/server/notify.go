@@ -0,0 +1,48 @@
+package server
+
+import "sync"
+
+// itemNotifier wakes long-poll requests waiting on a feed when items may have
+// been added to it. Each feed has at most one channel, closed and dropped on
+// notify, so every waiter registered before the notify sees it.
+type itemNotifier struct {
+	mu    sync.Mutex
+	feeds map[int64]chan struct{}
+}
+
+func newItemNotifier() *itemNotifier {
+	notifier := new(itemNotifier)
+	notifier.feeds = make(map[int64]chan struct{})
+
+	return notifier
+}
+
+// wait returns a channel closed by the next notify for feedID. Callers take
+// the channel before checking for items so a notify between the check and the
+// wait is not missed.
+func (n *itemNotifier) wait(feedID int64) <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ch, ok := n.feeds[feedID]
+	if !ok {
+		ch = make(chan struct{})
+		n.feeds[feedID] = ch
+	}
+
+	return ch
+}
+
+// notify wakes everything waiting on feedID.
+func (n *itemNotifier) notify(feedID int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ch, ok := n.feeds[feedID]
+	if !ok {
+		return
+	}
+
+	close(ch)
+	delete(n.feeds, feedID)
+}
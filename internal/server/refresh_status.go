@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"rss/internal/view"
+)
+
+// refreshCallTimeout bounds a shared refresh, which runs apart from the
+// requests waiting on it.
+const refreshCallTimeout = 2 * time.Minute
+
+// refreshTracker records which feeds are refreshing. A refresh asked for
+// while the same feed is already in flight, such as a manual refresh during
+// the background loop's, waits for that one and shares its result instead of
+// fetching the feed a second time. A refresh runs under the tracker's own
+// context rather than any one caller's: it stops when every caller waiting on
+// it has given up, or when the tracker is closed, but not while any remain.
+type refreshTracker struct {
+	ctx    context.Context //nolint:containedctx // Owned by the tracker; close cancels it on shutdown.
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	feeds  map[int64]*refreshCall
+}
+
+// refreshCall is one in-flight refresh. done is closed once inserted and err
+// are set. waiters counts the callers still waiting on it, under the
+// tracker's lock.
+type refreshCall struct {
+	done     chan struct{}
+	cancel   context.CancelFunc
+	err      error
+	inserted int
+	waiters  int
+}
+
+func newRefreshTracker() *refreshTracker {
+	tracker := new(refreshTracker)
+	tracker.ctx, tracker.cancel = context.WithCancel(context.Background())
+	tracker.feeds = make(map[int64]*refreshCall)
+
+	return tracker
+}
+
+// do runs refresh for feedID unless a refresh of it is already in flight,
+// then waits for whichever is running, or for ctx, and returns its result.
+// A caller that gives up, such as a manual refresh whose client disconnected,
+// cancels the refresh only when no other caller is still waiting on it.
+func (t *refreshTracker) do(
+	ctx context.Context,
+	feedID int64,
+	refresh func(context.Context) (int, error),
+) (int, error) {
+	t.mu.Lock()
+
+	call, ok := t.feeds[feedID]
+	if !ok {
+		var runCtx context.Context
+
+		call = new(refreshCall)
+		call.done = make(chan struct{})
+		runCtx, call.cancel = context.WithTimeout(t.ctx, refreshCallTimeout)
+		t.feeds[feedID] = call
+
+		go t.run(runCtx, feedID, call, refresh)
+	}
+
+	call.waiters++
+	t.mu.Unlock()
+
+	select {
+	case <-call.done:
+		t.leave(feedID, call)
+
+		return call.inserted, call.err
+	case <-ctx.Done():
+		t.leave(feedID, call)
+
+		return 0, fmt.Errorf("wait for feed %d refresh: %w", feedID, ctx.Err())
+	}
+}
+
+// leave drops one waiter from call. The last one to leave cancels the
+// refresh if it is still running and forgets it, so a later caller starts a
+// fresh refresh rather than joining a cancelled one.
+func (t *refreshTracker) leave(feedID int64, call *refreshCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+
+	call.cancel()
+
+	if t.feeds[feedID] == call {
+		delete(t.feeds, feedID)
+	}
+}
+
+func (t *refreshTracker) run(
+	ctx context.Context,
+	feedID int64,
+	call *refreshCall,
+	refresh func(context.Context) (int, error),
+) {
+	defer call.cancel()
+
+	defer func() {
+		t.mu.Lock()
+		if t.feeds[feedID] == call {
+			delete(t.feeds, feedID)
+		}
+		t.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.inserted, call.err = refresh(ctx)
+}
+
+// close cancels every refresh in flight and any started later.
+func (t *refreshTracker) close() {
+	t.cancel()
+}
+
+// running reports whether a refresh of feedID is in flight.
+func (t *refreshTracker) running(feedID int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.feeds[feedID]
+
+	return ok
+}
+
+// handleRefreshStatus reports whether the feed is refreshing. The item list
+// polls it while a refresh is in flight: each answer is the placeholder
+// again, still polling, until the refresh ends and it comes back empty.
+func (a *App) handleRefreshStatus(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	status := view.RefreshStatusData{FeedID: feedID, Refreshing: a.refreshes.running(feedID)}
+
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if wantsJSON(r) {
+		writeJSON(w, status)
+
+		return
+	}
+
+	a.renderTemplate(w, "refresh_status", status)
+}
@@ -4,39 +4,111 @@ import (
 	"bufio"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
+	"iter"
 	"log"
 	"log/slog"
+	"maps"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mmcdole/gofeed"
 
 	"rss/internal/auth"
+	"rss/internal/backup"
 	"rss/internal/content"
 	"rss/internal/feed"
+	"rss/internal/mailbox"
 	"rss/internal/opml"
 	"rss/internal/store"
+	"rss/internal/translate"
 	"rss/internal/view"
 )
 
 const (
-	feedEditModeCookie             = "pulse_rss_feed_edit_mode"
-	maxOPMLUploadBytes       int64 = 2 << 20
-	imageProxySniffBytes           = 512
-	cleanupInterval                = 10 * time.Minute
-	feedEditModeCookieMaxAge       = 60 * 60 * 24 * 365
+	feedEditModeCookie               = "pulse_rss_feed_edit_mode"
+	skipReadConfirmCookie            = "pulse_rss_skip_read_confirm"
+	maxOPMLUploadBytes         int64 = 2 << 20
+	imageProxySniffBytes             = 512
+	cleanupInterval                  = 10 * time.Minute
+	defaultMaintenanceInterval       = 24 * time.Hour
+	feedEditModeCookieMaxAge         = 60 * 60 * 24 * 365
+	feedPreviewItemLimit             = 5
+	maxShortcutPrefsBytes      int64 = 4 << 10
+	shortcutsPrefKey                 = "shortcuts"
+	accentPrefKey                    = "accent_color"
+	markReadOnLeavePrefKey           = "mark_read_on_leave"
+	maxIngestMessageBytes      int64 = 10 << 20
+	ingestTokenBytes                 = 24
+	maxSavedPageBytes          int64 = 1 << 20
+	selectedFeedPrefKey              = "selected_feed"
+	maxBackupUploadBytes       int64 = 256 << 20
+	backupItemPageSize               = 500
+	itemWaitTimeout                  = 25 * time.Second
+	// itemWaitWriteSlack extends a long poll's write deadline past its wait so
+	// the server's WriteTimeout does not cut the response off.
+	itemWaitWriteSlack = 10 * time.Second
+	// imageProxyRetryDelay is the pause before the image proxy retries an
+	// upstream fetch that failed to connect or answered 5xx.
+	imageProxyRetryDelay = 250 * time.Millisecond
+	// feedSearchLimit bounds the feeds a jump-to-feed search returns.
+	feedSearchLimit = 20
+	// defaultSubscribeTimeout bounds the fetch behind subscribing to or
+	// previewing a feed, shorter than background refreshes since someone is
+	// waiting on it.
+	defaultSubscribeTimeout = 8 * time.Second
+	// sidebarPeekItemLimit is how many headlines the sidebar shows when
+	// hovering a feed.
+	sidebarPeekItemLimit = 5
+	// maxFeedMinAgeMinutes caps how long a feed can hold back new items: a
+	// day.
+	maxFeedMinAgeMinutes = 24 * 60
+)
+
+var (
+	errFeedReturnedNoContent = errors.New("feed returned no content")
+	errFeedPreviewURLBlocked = errors.New("feed URL points to a disallowed host")
+	errUnknownShortcutAction = errors.New("unknown shortcut action")
+	errInvalidShortcutKey    = errors.New("shortcut key must be a single printable character")
+	errDuplicateShortcutKey  = errors.New("shortcut key is assigned to more than one action")
+	errInvalidAccentColor    = errors.New("accent color must be a hex color such as #0f766e")
+	errMailboxTitleRequired  = errors.New("newsletter feed needs a name")
+	errSavedLinkURLInvalid   = errors.New("saved link must be an http or https URL")
+	errBackupFileMissing     = errors.New("missing backup file")
 )
 
-var errFeedReturnedNoContent = errors.New("feed returned no content")
+// accentColorPattern is deliberately strict: accent colors are echoed into an
+// inline <style>, so only #rgb and #rrggbb values are accepted.
+var accentColorPattern = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6})$`)
+
+// defaultShortcuts maps each remappable keyboard action to its default key.
+// Arrow keys and Enter stay bound in the frontend regardless of the mapping.
+var defaultShortcuts = map[string]string{
+	"next_item":        "j",
+	"previous_item":    "k",
+	"expand_item":      "l",
+	"collapse_item":    "h",
+	"open_article":     "o",
+	"toggle_read":      "r",
+	"next_unread_feed": "n",
+	"mark_read_above":  "a",
+}
 
 // App wires handlers, dependencies, and background loops for the HTTP server.
 type App struct {
@@ -45,15 +117,33 @@ type App struct {
 	db                  *sql.DB
 	tmpl                *template.Template
 	imageProxyClient    *http.Client
+	imageUpgradeClient  *http.Client
 	imageProxyLookup    content.LookupIPAddrFunc
+	enclosureCache      *feed.EnclosureCache
+	translator          *translate.Client
+	itemNotifier        *itemNotifier
+	refreshes           *refreshTracker
+	activeItems         *activeItemTracker
+	location            *time.Location
 	authRateLimiter     *authRateLimiter
+	shareRateLimiter    *authRateLimiter
+	ingestRateLimiter   *authRateLimiter
 	authCookieName      string
 	authSetupToken      string
 	authSetupCookieName string
 	authSetupSignerKey  []byte
 	refreshMu           sync.Mutex
+	maintenanceInterval time.Duration
+	activeItemRetention time.Duration
+	itemWaitTimeout     time.Duration
+	subscribeTimeout    time.Duration
+	itemPageSize        int
+	requestLogLevel     slog.Level
 	authEnabled         bool
 	authCookieSecure    bool
+	keepHistoryOnDelete bool
+	kioskEnabled        bool
+	imageHTTPSUpgrade   bool
 }
 
 // New constructs an App with default static file and image proxy dependencies.
@@ -63,18 +153,36 @@ func New(db *sql.DB, tmpl *template.Template) *App {
 	app.tmpl = tmpl
 	app.staticHandler = http.FileServer(http.Dir("static"))
 	app.imageProxyClient = content.NewHTTPClient()
+	app.imageUpgradeClient = content.NewHTTPSUpgradeClient()
 	app.imageProxyLookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
 		return net.DefaultResolver.LookupIPAddr(ctx, host)
 	}
+	app.enclosureCache = nil
+	app.translator = nil
 	app.authManager = nil
 	app.authRateLimiter = nil
+	app.shareRateLimiter = newAuthRateLimiter()
+	app.ingestRateLimiter = newAuthRateLimiter()
 	app.authCookieName = ""
 	app.authSetupToken = ""
 	app.authSetupCookieName = ""
 	app.authSetupSignerKey = nil
 	app.refreshMu = sync.Mutex{}
+	app.itemNotifier = newItemNotifier()
+	app.refreshes = newRefreshTracker()
+	app.activeItems = newActiveItemTracker()
+	app.maintenanceInterval = defaultMaintenanceInterval
+	app.activeItemRetention = defaultActiveItemRetention
+	app.itemWaitTimeout = itemWaitTimeout
+	app.subscribeTimeout = defaultSubscribeTimeout
+	app.itemPageSize = store.DefaultItemPageSize
+	app.location = time.Local
+	app.requestLogLevel = slog.LevelInfo
 	app.authEnabled = false
 	app.authCookieSecure = false
+	app.keepHistoryOnDelete = false
+	app.kioskEnabled = false
+	app.imageHTTPSUpgrade = false
 
 	return app
 }
@@ -84,6 +192,95 @@ func (a *App) SetStaticFS(fsys fs.FS) {
 	a.staticHandler = http.FileServer(http.FS(fsys))
 }
 
+// SetKeepHistoryOnDelete controls whether deleting a feed keeps its read state
+// and tombstones so a later re-subscription can restore them.
+func (a *App) SetKeepHistoryOnDelete(enabled bool) {
+	a.keepHistoryOnDelete = enabled
+}
+
+// SetKioskEnabled controls whether GET /kiosk serves its read-only page of
+// unread headlines, without a session even when auth is on.
+func (a *App) SetKioskEnabled(enabled bool) {
+	a.kioskEnabled = enabled
+}
+
+// SetImageHTTPSUpgrade controls whether the image proxy tries plain-http
+// images over https first, falling back to http when that fails.
+func (a *App) SetImageHTTPSUpgrade(enabled bool) {
+	a.imageHTTPSUpgrade = enabled
+}
+
+// SetMaintenanceInterval sets how often the database is vacuumed and the WAL
+// truncated. A non-positive interval disables maintenance.
+func (a *App) SetMaintenanceInterval(interval time.Duration) {
+	a.maintenanceInterval = interval
+}
+
+// SetEnclosureCache turns on downloading enclosures for feeds that opt in,
+// checking their hosts with the image proxy's lookup. A nil cache leaves
+// enclosures served from their original URLs.
+func (a *App) SetEnclosureCache(cache *feed.EnclosureCache) {
+	if cache != nil {
+		cache.SetLookup(a.imageProxyLookup)
+	}
+
+	a.enclosureCache = cache
+}
+
+// SetTranslator turns on translating items on demand through client. A nil
+// client leaves translation off.
+func (a *App) SetTranslator(client *translate.Client) {
+	a.translator = client
+}
+
+// SetSubscribeTimeout sets how long subscribing to or previewing a feed waits
+// for it. A non-positive timeout keeps the default.
+func (a *App) SetSubscribeTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultSubscribeTimeout
+	}
+
+	a.subscribeTimeout = timeout
+}
+
+// SetActiveItemRetention sets how long after a page last reported an item as
+// active, such as the one expanded for reading, cleanup keeps that item even
+// when it was read long enough ago to delete. A non-positive retention keeps
+// the default.
+func (a *App) SetActiveItemRetention(retention time.Duration) {
+	if retention <= 0 {
+		retention = defaultActiveItemRetention
+	}
+
+	a.activeItemRetention = retention
+}
+
+// SetItemPageSize sets how many items a feed's list shows before offering to
+// load more. A non-positive size keeps the default.
+func (a *App) SetItemPageSize(size int) {
+	if size <= 0 {
+		size = store.DefaultItemPageSize
+	}
+
+	a.itemPageSize = size
+}
+
+// SetRequestLogLevel sets the level used for per-request access logs, so they
+// can be quieted independently of application logs.
+func (a *App) SetRequestLogLevel(level slog.Level) {
+	a.requestLogLevel = level
+}
+
+// SetLocation sets the time zone whose midnight starts the today view. A nil
+// location keeps the server's local time zone.
+func (a *App) SetLocation(location *time.Location) {
+	if location == nil {
+		location = time.Local
+	}
+
+	a.location = location
+}
+
 // Routes returns the fully configured application HTTP handler.
 func (a *App) Routes() http.Handler {
 	mux := http.NewServeMux()
@@ -99,36 +296,89 @@ func (a *App) Routes() http.Handler {
 	return a.wrapRoutes(handler)
 }
 
-// StartBackgroundLoops starts cleanup and feed refresh goroutines.
+// StartBackgroundLoops starts cleanup, maintenance, and feed refresh goroutines.
 func (a *App) StartBackgroundLoops() {
 	go a.cleanupLoop()
 	go a.refreshLoop()
+
+	if a.maintenanceInterval > 0 {
+		go a.maintenanceLoop()
+	}
+}
+
+// Close cancels feed refreshes still in flight so they do not outlive the
+// server.
+func (a *App) Close() {
+	a.refreshes.close()
 }
 
 func (a *App) registerCoreRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /healthz", a.handleHealthz)
 	mux.Handle("GET /static/", http.StripPrefix("/static/", a.staticHandler))
 	mux.HandleFunc("GET /{$}", a.handleIndex)
+	mux.HandleFunc("GET /i/{itemID}", a.handleItemPermalink)
+	mux.HandleFunc("GET /prefs/shortcuts", a.handleGetShortcuts)
+	mux.HandleFunc("POST /prefs/shortcuts", a.handleSaveShortcuts)
+	mux.HandleFunc("POST /prefs/feed-order", a.handleSaveFeedOrder)
+	mux.HandleFunc("POST /prefs/accent", a.handleSaveAccentColor)
+	mux.HandleFunc("POST /prefs/mark-read-on-leave", a.handleSaveMarkReadOnLeave)
 	mux.HandleFunc("GET /opml/export", a.handleExportOPML)
 	mux.HandleFunc("POST /opml/import", a.handleImportOPML)
+	mux.HandleFunc("POST /opml/import-url", a.handleImportOPMLURL)
+	mux.HandleFunc("GET /export/backup.json", a.handleExportBackup)
+	mux.HandleFunc("POST /import/backup.json", a.handleImportBackup)
 	mux.HandleFunc("GET "+content.ImageProxyPath, a.handleImageProxy)
+	mux.HandleFunc("POST /admin/consistency", a.handleVerifyConsistency)
 }
 
 func (a *App) registerFeedRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /feeds", a.handleSubscribe)
+	mux.HandleFunc("POST /feeds/mailbox", a.handleCreateMailboxFeed)
+	mux.HandleFunc("POST /ingest/{token}", a.handleIngestMessage)
+	mux.HandleFunc("GET /share/{token}", a.handleSharedFeed)
+	mux.HandleFunc("GET "+kioskPath, a.handleKiosk)
+	mux.HandleFunc("POST /saved", a.handleSaveLink)
+	mux.HandleFunc("GET /feeds/preview", a.handleFeedPreview)
+	mux.HandleFunc("GET /feeds/health", a.handleFeedHealth)
 	mux.HandleFunc("POST /feeds/edit-mode", a.handleEnterFeedEditMode)
 	mux.HandleFunc("POST /feeds/edit-mode/save", a.handleSaveFeedEditMode)
+	mux.HandleFunc("GET /tags/{tag}", a.handleTagItems)
 	mux.HandleFunc("POST /feeds/edit-mode/cancel", a.handleCancelFeedEditMode)
+	mux.HandleFunc("GET /feeds/next-unread", a.handleNextUnreadFeed)
+	mux.HandleFunc("GET /feeds/search", a.handleFeedSearch)
+	mux.HandleFunc("GET /feeds/diagnose", a.handleFeedDiagnose)
+	mux.HandleFunc("POST /feeds/delete", a.handleDeleteFeeds)
 	mux.HandleFunc("POST /feeds/{feedID}/delete", a.handleDeleteFeed)
 	mux.HandleFunc("POST /feeds/{feedID}/refresh", a.handleRefreshFeed)
+	mux.HandleFunc("GET /feeds/{feedID}/refresh-status", a.handleRefreshStatus)
+	mux.HandleFunc("POST /feeds/{feedID}/fetch-settings", a.handleSaveFeedFetchSettings)
+	mux.HandleFunc("POST /feeds/{feedID}/share", a.handleShareFeed)
+	mux.HandleFunc("POST /feeds/{feedID}/share/revoke", a.handleRevokeFeedShare)
+	mux.HandleFunc("POST /feeds/{feedID}/archive", a.handleArchiveFeed)
+	mux.HandleFunc("POST /feeds/{feedID}/unarchive", a.handleUnarchiveFeed)
+	mux.HandleFunc("GET /feeds/{feedID}/feed.json", a.handleFeedJSON)
 	mux.HandleFunc("GET /feeds/{feedID}/items", a.handleFeedItems)
+	mux.HandleFunc("GET /feeds/{feedID}/preview", a.handleFeedPeek)
 	mux.HandleFunc("GET /feeds/{feedID}/items/new", a.handleFeedItemsNew)
 	mux.HandleFunc("GET /feeds/{feedID}/items/poll", a.handleFeedItemsPoll)
+	mux.HandleFunc("GET /feeds/{feedID}/items/page", a.handleFeedItemsPage)
+	mux.HandleFunc("GET /feeds/{feedID}/items/wait", a.handleFeedItemsWait)
+	mux.HandleFunc("GET /unread/count", a.handleUnreadCount)
+	mux.HandleFunc("GET /feeds/{feedID}/items/read/confirm", a.handleMarkAllReadConfirm)
 	mux.HandleFunc("POST /feeds/{feedID}/items/read", a.handleMarkAllRead)
+	mux.HandleFunc("POST /feeds/{feedID}/items/read-above", a.handleMarkReadAbove)
+	mux.HandleFunc("POST /feeds/{feedID}/items/read-batch", a.handleMarkItemsRead)
+	mux.HandleFunc("POST /feeds/{feedID}/items/read-seen", a.handleMarkSeenItemsRead)
 	mux.HandleFunc("POST /feeds/{feedID}/items/sweep", a.handleSweepRead)
+	mux.HandleFunc("GET /items/today", a.handleTodayItems)
+	mux.HandleFunc("GET /discover", a.handleDiscover)
 	mux.HandleFunc("GET /items/{itemID}", a.handleItemExpanded)
 	mux.HandleFunc("GET /items/{itemID}/compact", a.handleItemCompact)
 	mux.HandleFunc("POST /items/{itemID}/toggle", a.handleToggleRead)
+	mux.HandleFunc("POST /items/{itemID}/read-next", a.handleReadNext)
+	mux.HandleFunc("POST /items/{itemID}/dismiss", a.handleDismissItem)
+	mux.HandleFunc("POST /items/{itemID}/translate", a.handleTranslateItem)
+	mux.HandleFunc("GET /enclosures/{itemID}", a.handleEnclosure)
 }
 
 func (a *App) registerAuthRoutes(mux *http.ServeMux) {
@@ -140,6 +390,7 @@ func (a *App) registerAuthRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /auth/webauthn/register/options", a.handleAuthRegisterOptions)
 	mux.HandleFunc("POST /auth/webauthn/register/verify", a.handleAuthRegisterVerify)
 	mux.HandleFunc("POST /auth/logout", a.handleAuthLogout)
+	mux.HandleFunc("POST /auth/sessions/{sessionID}/revoke", a.handleAuthSessionRevoke)
 	mux.HandleFunc("GET /auth/security", a.handleAuthSecurity)
 	mux.HandleFunc("GET /auth/recovery", a.handleAuthRecovery)
 	mux.HandleFunc("POST /auth/recovery/use", a.handleAuthRecoveryUse)
@@ -147,9 +398,10 @@ func (a *App) registerAuthRoutes(mux *http.ServeMux) {
 }
 
 func (a *App) wrapRoutes(handler http.Handler) http.Handler {
-	handler = a.withRequestID(handler)
+	handler = a.withActiveItemTracking(handler)
 	handler = a.withRealIP(handler)
 	handler = a.withSecurityHeaders(handler)
+	handler = a.withCSPNonce(handler)
 
 	if a.authEnabled {
 		handler = a.withAuthRateLimit(handler)
@@ -157,7 +409,11 @@ func (a *App) wrapRoutes(handler http.Handler) http.Handler {
 		handler = a.withAuthSession(handler)
 	}
 
-	return handler
+	// The request ID is set outside auth so rejected requests carry one in
+	// their response and access log line too.
+	handler = a.withRequestID(handler)
+
+	return a.withRequestLogging(handler)
 }
 
 func feedEditModeEnabled(r *http.Request) bool {
@@ -193,10 +449,93 @@ func clearFeedEditModeCookie(w http.ResponseWriter) {
 	http.SetCookie(w, cookie)
 }
 
+func skipReadConfirmEnabled(r *http.Request) bool {
+	cookie, err := r.Cookie(skipReadConfirmCookie)
+	if err != nil {
+		return false
+	}
+
+	return cookie.Value == "1"
+}
+
+func setSkipReadConfirmCookie(w http.ResponseWriter) {
+	cookie := new(http.Cookie)
+	cookie.Name = skipReadConfirmCookie
+	cookie.Value = "1"
+	cookie.Path = "/"
+	cookie.MaxAge = feedEditModeCookieMaxAge
+	cookie.Expires = time.Now().Add(365 * 24 * time.Hour)
+	cookie.HttpOnly = true
+	cookie.SameSite = http.SameSiteLaxMode
+	http.SetCookie(w, cookie)
+}
+
 func (a *App) handleIndex(w http.ResponseWriter, r *http.Request) {
+	var itemList *view.ItemListData
+
+	// ?feed= preselects a feed so permalinks to cleaned-up items can fall back to it.
+	if raw := r.URL.Query().Get("feed"); raw != "" {
+		feedID, err := strconv.ParseInt(raw, 10, 64)
+		if err == nil && feedID > 0 {
+			itemList, err = store.LoadItemList(r.Context(), a.db, feedID, a.itemPageSize)
+			if err != nil {
+				itemList = nil
+			}
+		}
+	} else {
+		itemList = a.loadRememberedItemList(r.Context())
+	}
+
+	a.renderIndex(w, r, itemList, "")
+}
+
+// loadRememberedItemList reopens the feed selected before the last reload.
+// When that feed has since been deleted the first feed is opened instead;
+// with nothing remembered it returns nil and the empty state is shown.
+func (a *App) loadRememberedItemList(ctx context.Context) *view.ItemListData {
+	value, ok, err := store.GetUserPref(ctx, a.db, selectedFeedPrefKey)
+	if err != nil || !ok {
+		return nil
+	}
+
+	if feedID, parseErr := strconv.ParseInt(value, 10, 64); parseErr == nil {
+		itemList, loadErr := store.LoadItemList(ctx, a.db, feedID, a.itemPageSize)
+		if loadErr == nil {
+			return itemList
+		}
+	}
+
+	feeds, err := store.ListFeeds(ctx, a.db)
+	if err != nil || len(feeds) == 0 {
+		return nil
+	}
+
+	itemList, err := store.LoadItemList(ctx, a.db, feeds[0].ID, a.itemPageSize)
+	if err != nil {
+		return nil
+	}
+
+	return itemList
+}
+
+func (a *App) renderIndex(w http.ResponseWriter, r *http.Request, itemList *view.ItemListData, notice string) {
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	shortcuts, err := loadShortcuts(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load shortcuts")
+
+		return
+	}
+
+	feedOrder, err := store.GetFeedOrder(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feed order")
 
 		return
 	}
@@ -204,15 +543,100 @@ func (a *App) handleIndex(w http.ResponseWriter, r *http.Request) {
 	var data pageData
 
 	data.Feeds = feeds
+	data.UnreadTotal = totalUnread(feeds)
+	data.Shortcuts = shortcuts
+	data.FeedOrder = feedOrder
+	data.AccentColor = loadAccentColor(r.Context(), a.db)
+	data.MarkReadOnLeave = loadMarkReadOnLeave(r.Context(), a.db)
+	data.CSPNonce = requestCSPNonce(r)
+	data.ItemList = itemList
+	data.Notice = notice
 	data.FeedEditMode = feedEditModeEnabled(r)
 	data.CSRFToken = a.csrfTokenForRequest(r)
+
+	if itemList != nil {
+		data.SelectedFeedID = itemList.Feed.ID
+	}
+
 	a.renderTemplate(w, "index", data)
 }
 
+// handleItemPermalink renders the full app shell with the item's feed selected
+// and the item expanded, so the URL can be bookmarked or shared.
+func (a *App) handleItemPermalink(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := parsePathInt64(r, "itemID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	feedID, err := store.GetFeedIDByItem(r.Context(), a.db, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.renderMissingPermalink(w, r)
+
+		return
+	}
+
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load item")
+
+		return
+	}
+
+	itemList, err := store.LoadItemList(r.Context(), a.db, feedID, a.itemPageSize)
+	if err == nil && !itemListHasItem(itemList, itemID) {
+		// The item is further down than the first page, so list every item
+		// for it to show expanded in place.
+		itemList, err = store.LoadItemList(r.Context(), a.db, feedID, 0)
+	}
+
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load items")
+
+		return
+	}
+
+	itemList.ExpandedItemID = itemID
+	for i := range itemList.Items {
+		itemList.Items[i].IsActive = itemList.Items[i].ID == itemID
+	}
+
+	a.renderIndex(w, r, itemList, "")
+}
+
+func itemListHasItem(itemList *view.ItemListData, itemID int64) bool {
+	for i := range itemList.Items {
+		if itemList.Items[i].ID == itemID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renderMissingPermalink redirects to the item's feed when the link carries
+// one that still exists, and otherwise renders the shell with a 404 notice.
+func (a *App) renderMissingPermalink(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.URL.Query().Get("feed"), 10, 64)
+	if err == nil && feedID > 0 {
+		_, feedErr := store.GetFeedURL(r.Context(), a.db, feedID)
+		if feedErr == nil {
+			http.Redirect(w, r, "/?feed="+strconv.FormatInt(feedID, 10), http.StatusSeeOther)
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	a.renderIndex(w, r, nil, "This item is no longer available.")
+}
+
 func (a *App) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "invalid form", http.StatusBadRequest)
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
 
 		return
 	}
@@ -244,7 +668,13 @@ func (a *App) subscribeAndStoreFeed(ctx context.Context, rawURL string) (int64,
 
 	slog.Info("subscribe feed")
 
-	result, err := feed.Fetch(ctx, feedURL, "", "")
+	result, err := feed.FetchWithOverrides(ctx, feedURL, "", "", a.subscriberFetchOverrides())
+	if errors.Is(ctx.Err(), context.Canceled) {
+		slog.Info("subscribe canceled by client")
+
+		return 0, fmt.Errorf("fetch feed: %w", ctx.Err())
+	}
+
 	if err != nil {
 		slog.Error("subscribe fetch failed", "err", err)
 
@@ -271,6 +701,19 @@ func (a *App) subscribeAndStoreFeed(ctx context.Context, rawURL string) (int64,
 	return feedID, nil
 }
 
+// subscriberFetchOverrides bounds fetches someone is waiting on by the
+// subscribe timeout. Callers pass the request's context, so the fetch also
+// stops as soon as the client goes away.
+func (a *App) subscriberFetchOverrides() feed.FetchOverrides {
+	return feed.FetchOverrides{
+		Lookup:    nil,
+		UserAgent: "",
+		HTTPProxy: "",
+		Timeout:   a.subscribeTimeout,
+		HTTPSOnly: false,
+	}
+}
+
 func (a *App) persistSubscribedFeed(ctx context.Context, feedURL string, result *feed.FetchResult) (int64, error) {
 	feedTitle := subscribeFeedTitle(result.Feed.Title, feedURL)
 
@@ -281,6 +724,11 @@ func (a *App) persistSubscribedFeed(ctx context.Context, feedURL string, result
 		return 0, fmt.Errorf("upsert feed: %w", err)
 	}
 
+	detailsErr := feed.SaveDetails(ctx, a.db, feedID, feedURL, result.Feed)
+	if detailsErr != nil {
+		slog.Warn("subscribe save feed details failed", "err", detailsErr)
+	}
+
 	_, err = store.UpsertItems(ctx, a.db, feedID, result.Feed.Items)
 	if err != nil {
 		slog.Error("subscribe upsert items failed")
@@ -317,6 +765,10 @@ func (a *App) saveSubscribeRefreshMeta(ctx context.Context, feedID int64, result
 	meta.UnchangedCount = 0
 	meta.NextRefreshAt = feed.NextRefreshAt(checkedAt, 0)
 
+	if result.Feed != nil && len(result.Feed.Items) > 0 {
+		meta.ContentAt = checkedAt
+	}
+
 	err := feed.SaveRefreshMeta(ctx, a.db, feedID, meta)
 	if err != nil {
 		log.Printf("refresh meta update failed: %v", err)
@@ -333,7 +785,7 @@ func (a *App) buildSubscribeResponseData(
 		return subscribeResponseData{}, fmt.Errorf("list feeds: %w", err)
 	}
 
-	itemList, err := store.LoadItemList(ctx, a.db, feedID)
+	itemList, err := store.LoadItemList(ctx, a.db, feedID, a.itemPageSize)
 	if err != nil {
 		return subscribeResponseData{}, fmt.Errorf("load feed items: %w", err)
 	}
@@ -349,146 +801,881 @@ func (a *App) buildSubscribeResponseData(
 	}, nil
 }
 
-func (a *App) renderSubscribeError(w http.ResponseWriter, err error) {
-	var data subscribeResponseData
-
-	data.Message = err.Error()
-	data.MessageClass = "error"
-	data.Update = false
-	a.renderTemplate(w, "subscribe_response", data)
-}
-
-func (a *App) handleExportOPML(w http.ResponseWriter, r *http.Request) {
-	feeds, err := store.ListFeeds(r.Context(), a.db)
+// handleFeedHealth renders every feed's refresh bookkeeping in one table,
+// sorted by the ?sort= column so dead or noisy feeds stand out.
+func (a *App) handleFeedHealth(w http.ResponseWriter, r *http.Request) {
+	feeds, err := store.ListFeedHealth(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feed health")
 
 		return
 	}
 
-	subscriptions := make([]opml.Subscription, 0, len(feeds))
-	for _, listedFeed := range feeds {
-		subscriptions = append(subscriptions, opml.Subscription{
-			Title: listedFeed.Title,
-			URL:   listedFeed.URL,
-		})
-	}
-
-	filename := "pulse-rss-subscriptions-" + time.Now().UTC().Format("20060102") + ".opml"
+	sortKey := view.SortFeedHealth(feeds, r.URL.Query().Get("sort"))
 
-	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
-	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	a.renderTemplate(w, "feed_health", feedHealthPageData{
+		Feeds:   feeds,
+		Columns: feedHealthColumns(sortKey),
+	})
+}
 
-	err = opml.Write(w, "Pulse RSS Subscriptions", subscriptions)
-	if err != nil {
-		http.Error(w, "failed to export opml", http.StatusInternalServerError)
+func feedHealthColumns(sortKey string) []feedHealthColumn {
+	columns := []feedHealthColumn{
+		{Key: view.HealthSortTitle, Label: "Feed", Order: "ascending"},
+		{Key: view.HealthSortChecked, Label: "Last checked", Order: "ascending"},
+		{Key: view.HealthSortContent, Label: "Last content", Order: "ascending"},
+		{Key: view.HealthSortErrors, Label: "Consecutive errors", Order: "descending"},
+		{Label: "Error code"},
+		{Key: view.HealthSortNext, Label: "Next refresh", Order: "ascending"},
+		{Key: view.HealthSortRate, Label: "Items/day", Order: "descending"},
+		{Key: view.HealthSortAdded, Label: "Added", Order: "descending"},
+	}
 
-		return
+	for i := range columns {
+		columns[i].Active = sortKey != "" && columns[i].Key == sortKey
 	}
-}
 
-type opmlImportCounts struct {
-	imported int
-	skipped  int
+	return columns
 }
 
-func (a *App) handleImportOPML(w http.ResponseWriter, r *http.Request) {
-	subscriptions, message := parseOPMLUpload(w, r)
-	if message != "" {
-		a.renderOPMLImportResponse(w, r, 0, 0, "error", message)
+// handleFeedPreview fetches and parses a feed without storing it so the
+// subscribe form can show what the URL contains before committing. It refuses
+// URLs on private or loopback hosts, including ones a redirect leads to.
+func (a *App) handleFeedPreview(w http.ResponseWriter, r *http.Request) {
+	data, err := a.buildFeedPreview(r.Context(), r.URL.Query().Get("url"))
+	if err != nil {
+		a.renderSubscribeError(w, err)
 
 		return
 	}
 
-	counts := a.importOPMLSubscriptions(r.Context(), subscriptions)
+	a.renderTemplate(w, "feed_preview", data)
+}
 
-	if counts.imported == 0 {
-		a.renderOPMLImportResponse(
-			w,
-			r,
-			counts.imported,
-			counts.skipped,
-			"error",
-			"no valid feeds found in OPML",
-		)
+func (a *App) buildFeedPreview(ctx context.Context, rawURL string) (feedPreviewData, error) {
+	feedURL, err := feed.NormalizeURL(rawURL)
+	if err != nil {
+		return feedPreviewData{}, fmt.Errorf("normalize feed URL: %w", err)
+	}
 
-		return
+	target, err := url.Parse(feedURL)
+	if err != nil || !content.IsAllowedResolvedProxyURL(ctx, target, a.imageProxyLookup) {
+		return feedPreviewData{}, errFeedPreviewURLBlocked
 	}
 
-	a.renderOPMLImportResponse(w, r, counts.imported, counts.skipped, "success", "")
-}
+	overrides := a.subscriberFetchOverrides()
+	overrides.Lookup = a.imageProxyLookup
 
-//nolint:gocritic // Tuple return keeps upload parsing call sites simple.
-func parseOPMLUpload(w http.ResponseWriter, r *http.Request) ([]opml.Subscription, string) {
-	r.Body = http.MaxBytesReader(w, r.Body, maxOPMLUploadBytes)
+	result, err := feed.FetchWithOverrides(ctx, feedURL, "", "", overrides)
+	if err != nil {
+		return feedPreviewData{}, fmt.Errorf("fetch feed: %w", err)
+	}
 
-	parseErr := r.ParseMultipartForm(maxOPMLUploadBytes)
-	if parseErr != nil {
-		return nil, "invalid OPML upload"
+	if result.NotModified || result.Feed == nil {
+		return feedPreviewData{}, errFeedReturnedNoContent
 	}
 
-	file, _, err := r.FormFile("file")
-	if err != nil {
-		return nil, "missing OPML file"
+	data := feedPreviewData{
+		FeedURL:     feedURL,
+		Title:       subscribeFeedTitle(result.Feed.Title, feedURL),
+		Description: strings.TrimSpace(result.Feed.Description),
+		SiteURL:     feed.SiteURL(result.Feed.Link, feedURL),
+		ItemTitles:  make([]string, 0, feedPreviewItemLimit),
+		ItemCount:   len(result.Feed.Items),
 	}
 
-	defer func() {
-		closeErr := file.Close()
-		if closeErr != nil {
-			log.Printf("opml upload close: %v", closeErr)
+	for _, item := range result.Feed.Items {
+		if len(data.ItemTitles) == feedPreviewItemLimit {
+			break
 		}
-	}()
 
-	subscriptions, err := opml.Parse(file)
-	if err != nil {
-		return nil, "invalid OPML file"
+		data.ItemTitles = append(data.ItemTitles, fallbackItemTitle(item.Title))
 	}
 
-	return subscriptions, ""
+	return data, nil
 }
 
-func (a *App) importOPMLSubscriptions(ctx context.Context, subscriptions []opml.Subscription) opmlImportCounts {
-	var counts opmlImportCounts
+// handleFeedDiagnose fetches a feed URL without subscribing and reports, as
+// JSON, how the server answered and why parsing failed, if it did. Like the
+// preview, it refuses URLs on private or loopback hosts, including ones a
+// redirect leads to.
+func (a *App) handleFeedDiagnose(w http.ResponseWriter, r *http.Request) {
+	feedURL, err := feed.NormalizeURL(r.URL.Query().Get("url"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 
-	for _, subscription := range subscriptions {
-		feedURL, err := feed.NormalizeURL(subscription.URL)
-		if err != nil {
-			counts.skipped++
+		return
+	}
 
-			continue
-		}
+	target, err := url.Parse(feedURL)
+	if err != nil || !content.IsAllowedResolvedProxyURL(r.Context(), target, a.imageProxyLookup) {
+		http.Error(w, errFeedPreviewURLBlocked.Error(), http.StatusBadRequest)
 
-		feedTitle := subscribeFeedTitle(subscription.Title, feedURL)
+		return
+	}
 
-		_, upsertErr := store.UpsertFeed(ctx, a.db, feedURL, feedTitle)
-		if upsertErr != nil {
-			counts.skipped++
+	writeJSON(w, feed.Diagnose(r.Context(), feedURL, a.imageProxyLookup))
+}
 
-			continue
-		}
+func fallbackItemTitle(title string) string {
+	trimmed := strings.TrimSpace(title)
+	if trimmed == "" {
+		return "(untitled)"
+	}
 
-		counts.imported++
+	return trimmed
+}
+
+func (a *App) renderSubscribeError(w http.ResponseWriter, err error) {
+	var data subscribeResponseData
+
+	data.Message = err.Error()
+	if errors.Is(err, store.ErrFeedLimitReached) {
+		data.Message = "Feed limit reached: remove a feed before subscribing to another."
 	}
 
-	return counts
+	data.MessageClass = "error"
+	data.Update = false
+	a.renderTemplate(w, "subscribe_response", data)
 }
 
-func (a *App) renderOPMLImportResponse(
-	w http.ResponseWriter,
-	r *http.Request,
-	imported,
-	skipped int,
-	messageClass,
-	fallbackMessage string,
-) {
-	feeds, err := store.ListFeeds(r.Context(), a.db)
+// handleCreateMailboxFeed adds a newsletter feed and reports the ingest
+// endpoint a mail gateway should post its messages to.
+func (a *App) handleCreateMailboxFeed(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
+
+		return
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		a.renderSubscribeError(w, errMailboxTitleRequired)
+
+		return
+	}
+
+	token, err := randomToken(ingestTokenBytes)
+	if err != nil {
+		a.renderSubscribeError(w, err)
+
+		return
+	}
+
+	feedID, err := store.CreateMailboxFeed(r.Context(), a.db, title, token)
+	if err != nil {
+		a.renderSubscribeError(w, err)
+
+		return
+	}
+
+	data, err := a.buildSubscribeResponseData(r.Context(), r, feedID)
+	if err != nil {
+		a.renderSubscribeError(w, err)
+
+		return
+	}
+
+	data.Message = "Send newsletters to POST /ingest/" + token
+	a.renderTemplate(w, "subscribe_response", data)
+}
+
+// handleIngestMessage stores one raw RFC 5322 message as an item of the
+// newsletter feed that owns the token. The token is the only credential, so
+// the route is exempt from session auth; instead it is rate limited per client
+// IP, and repeated unknown tokens lock the client out like failed logins. The
+// feed keeps at most store.MaxItemsPerFeed messages, dropping the oldest.
+func (a *App) handleIngestMessage(w http.ResponseWriter, r *http.Request) {
+	ip := requestRealIP(r)
+	if !a.ingestRateLimiter.allow(ip, time.Now().UTC()) {
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+
+		return
+	}
+
+	feedID, err := store.GetFeedIDByIngestToken(r.Context(), a.db, r.PathValue("token"))
+	if errors.Is(err, sql.ErrNoRows) {
+		a.ingestRateLimiter.recordFailure(ip)
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, "failed to look up feed", http.StatusInternalServerError)
+
+		return
+	}
+
+	item, err := mailbox.ParseMessage(http.MaxBytesReader(w, r.Body, maxIngestMessageBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "message too large", http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		http.Error(w, "invalid message", http.StatusBadRequest)
+
+		return
+	}
+
+	_, err = store.UpsertItems(r.Context(), a.db, feedID, []*gofeed.Item{item})
+	if err != nil {
+		slog.Error("ingest upsert item failed", "feed_id", feedID, "err", err)
+		http.Error(w, "failed to store message", http.StatusInternalServerError)
+
+		return
+	}
+
+	a.itemNotifier.notify(feedID)
+
+	enforceErr := store.EnforceItemLimit(r.Context(), a.db, feedID)
+	if enforceErr != nil {
+		slog.Warn("ingest enforce item limit failed", "feed_id", feedID, "err", enforceErr)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleSaveLink stores a URL in the Saved feed, for example from a
+// bookmarklet. Without a title the page's own <title> is used, and an
+// optional note becomes the item body.
+func (a *App) handleSaveLink(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
+
+		return
+	}
+
+	pageURL, ok := normalizeWebURL(r.FormValue("url"))
+	if !ok {
+		a.renderError(w, r, http.StatusBadRequest, errSavedLinkURLInvalid.Error())
+
+		return
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		title = a.fetchPageTitle(r.Context(), pageURL)
+	}
+
+	if title == "" {
+		title = pageURL
+	}
+
+	feedID, err := store.EnsureSavedFeed(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load saved feed")
+
+		return
+	}
+
+	now := time.Now().UTC()
+	item := &gofeed.Item{
+		Title:           title,
+		Link:            pageURL,
+		GUID:            pageURL,
+		PublishedParsed: &now,
+	}
+
+	if note := strings.TrimSpace(r.FormValue("note")); note != "" {
+		item.Content = "<p>" + template.HTMLEscapeString(note) + "</p>"
+	}
+
+	_, err = store.UpsertItems(r.Context(), a.db, feedID, []*gofeed.Item{item})
+	if err != nil {
+		slog.Error("save link upsert item failed", "err", err)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to save link")
+
+		return
+	}
+
+	a.itemNotifier.notify(feedID)
+
+	http.Redirect(w, r, "/?feed="+strconv.FormatInt(feedID, 10), http.StatusSeeOther)
+}
+
+// normalizeWebURL accepts an http or https URL typed by the user, assuming
+// https when no scheme is given, and drops its fragment.
+func normalizeWebURL(raw string) (string, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if !strings.Contains(trimmed, "://") {
+		trimmed = "https://" + trimmed
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", false
+	}
+
+	parsed.Fragment = ""
+
+	return parsed.String(), true
+}
+
+// fetchPageTitle returns the <title> of pageURL, or "" when the page cannot
+// be fetched. The image proxy's client and host checks are reused so a saved
+// link cannot be used to reach private addresses.
+func (a *App) fetchPageTitle(ctx context.Context, pageURL string) string {
+	target, err := url.Parse(pageURL)
+	if err != nil || !content.IsAllowedResolvedProxyURL(ctx, target, a.imageProxyLookup) {
+		return ""
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), http.NoBody)
+	if err != nil {
+		return ""
+	}
+
+	req.Header.Set("User-Agent", content.ImageProxyUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+
+	resp, err := a.imageProxyClient.Do(req)
+	if err != nil {
+		slog.Debug("saved link title fetch failed", "target_host", target.Host, "err", err)
+
+		return ""
+	}
+
+	defer func() {
+		closeErr := resp.Body.Close()
+		if closeErr != nil {
+			log.Printf("saved link close body: %v", closeErr)
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return ""
+	}
+
+	return content.PageTitle(io.LimitReader(resp.Body, maxSavedPageBytes))
+}
+
+// handleExportOPML writes the subscription list. Feeds whose URL is marked
+// secret are left out unless ?include_secret=1 asks for them.
+func (a *App) handleExportOPML(w http.ResponseWriter, r *http.Request) {
+	tag, ok := opmlExportTag(r)
+	if !ok {
+		http.Error(w, "invalid tag", http.StatusBadRequest)
+
+		return
+	}
+
+	var (
+		feeds []view.FeedView
+		err   error
+	)
+
+	if tag == "" {
+		feeds, err = store.ListFeeds(r.Context(), a.db)
+	} else {
+		feeds, err = store.ListFeedsByTag(r.Context(), a.db, tag)
+	}
+
+	if err != nil {
+		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+
+		return
+	}
+
+	includeSecret := r.URL.Query().Get("include_secret") == "1"
+
+	subscriptions := make([]opml.Subscription, 0, len(feeds))
+	for _, listedFeed := range feeds {
+		if store.IsLocalFeedURL(listedFeed.URL) || (listedFeed.SecretURL && !includeSecret) {
+			continue
+		}
+
+		subscriptions = append(subscriptions, opml.Subscription{
+			Title:       listedFeed.Title,
+			URL:         listedFeed.URL,
+			UnreadCount: listedFeed.UnreadCount,
+		})
+	}
+
+	title := "Pulse RSS Subscriptions"
+	filename := "pulse-rss-subscriptions-"
+
+	if tag != "" {
+		title += " (" + tag + ")"
+		filename += tag + "-"
+	}
+
+	filename += time.Now().UTC().Format("20060102") + ".opml"
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	err = opml.Write(w, title, subscriptions)
+	if err != nil {
+		http.Error(w, "failed to export opml", http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// opmlExportTag returns the normalized tag an export is limited to, or "" to
+// export every feed. Tags double as categories, so ?category= is accepted as
+// an alias for ?tag=.
+func opmlExportTag(r *http.Request) (string, bool) {
+	raw := strings.TrimSpace(r.URL.Query().Get("tag"))
+	if raw == "" {
+		raw = strings.TrimSpace(r.URL.Query().Get("category"))
+	}
+
+	if raw == "" {
+		return "", true
+	}
+
+	return store.NormalizeTag(raw)
+}
+
+func (a *App) handleGetShortcuts(w http.ResponseWriter, r *http.Request) {
+	shortcuts, err := loadShortcuts(r.Context(), a.db)
+	if err != nil {
+		http.Error(w, "failed to load shortcuts", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, shortcuts)
+}
+
+func (a *App) handleSaveShortcuts(w http.ResponseWriter, r *http.Request) {
+	var posted map[string]string
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxShortcutPrefsBytes))
+
+	err := decoder.Decode(&posted)
+	if err != nil {
+		http.Error(w, "invalid shortcuts payload", http.StatusBadRequest)
+
+		return
+	}
+
+	shortcuts, err := mergeShortcuts(posted)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	encoded, err := json.Marshal(shortcuts)
+	if err != nil {
+		http.Error(w, "failed to save shortcuts", http.StatusInternalServerError)
+
+		return
+	}
+
+	err = store.SetUserPref(r.Context(), a.db, shortcutsPrefKey, string(encoded))
+	if err != nil {
+		http.Error(w, "failed to save shortcuts", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, shortcuts)
+}
+
+// handleSaveFeedOrder saves the sidebar ordering and re-renders the feed list
+// in that order.
+func (a *App) handleSaveFeedOrder(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
+
+		return
+	}
+
+	err = store.SetFeedOrder(r.Context(), a.db, strings.TrimSpace(r.PostForm.Get("order")))
+	if errors.Is(err, store.ErrInvalidFeedOrder) {
+		a.renderError(w, r, http.StatusBadRequest, "invalid feed order")
+
+		return
+	}
+
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to save feed order")
+
+		return
+	}
+
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	var data itemListResponseData
+
+	data.Feeds = feeds
+	data.SelectedFeedID = parseSelectedFeedID(r)
+	data.FeedEditMode = feedEditModeEnabled(r)
+	a.renderTemplate(w, "feed_list", data)
+}
+
+// handleSaveAccentColor saves the accent color, or clears it when the form's
+// reset button was used, and returns to the app so the new color applies.
+func (a *App) handleSaveAccentColor(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
+
+		return
+	}
+
+	accent := ""
+	if !r.PostForm.Has("reset") {
+		accent, err = normalizeAccentColor(r.PostForm.Get("accent"))
+		if err != nil {
+			a.renderError(w, r, http.StatusBadRequest, err.Error())
+
+			return
+		}
+	}
+
+	err = store.SetUserPref(r.Context(), a.db, accentPrefKey, accent)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to save accent color")
+
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// loadAccentColor returns the saved accent color, or "" to keep the
+// stylesheet default. Stored values are validated again before use.
+func loadAccentColor(ctx context.Context, db *sql.DB) string {
+	raw, ok, err := store.GetUserPref(ctx, db, accentPrefKey)
+	if err != nil {
+		slog.Warn("load accent color failed", "err", err)
+
+		return ""
+	}
+
+	if !ok || raw == "" {
+		return ""
+	}
+
+	accent, err := normalizeAccentColor(raw)
+	if err != nil {
+		slog.Warn("ignoring invalid saved accent color", "err", err)
+
+		return ""
+	}
+
+	return accent
+}
+
+// handleSaveMarkReadOnLeave turns marking the items scrolled past in a feed
+// read on leaving it on or off.
+func (a *App) handleSaveMarkReadOnLeave(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
+
+		return
+	}
+
+	value := ""
+	if r.PostForm.Get("enabled") == "1" {
+		value = "1"
+	}
+
+	err = store.SetUserPref(r.Context(), a.db, markReadOnLeavePrefKey, value)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to save preference")
+
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// loadMarkReadOnLeave reports whether leaving a feed marks the items scrolled
+// past in it read. It is off unless saved on.
+func loadMarkReadOnLeave(ctx context.Context, db *sql.DB) bool {
+	raw, ok, err := store.GetUserPref(ctx, db, markReadOnLeavePrefKey)
+	if err != nil {
+		slog.Warn("load mark read on leave failed", "err", err)
+
+		return false
+	}
+
+	return ok && raw == "1"
+}
+
+// normalizeAccentColor validates a hex color and expands it to lowercase
+// #rrggbb, the form color inputs expect.
+func normalizeAccentColor(raw string) (string, error) {
+	accent := strings.ToLower(strings.TrimSpace(raw))
+	if !accentColorPattern.MatchString(accent) {
+		return "", errInvalidAccentColor
+	}
+
+	if len(accent) == len("#rgb") {
+		accent = string([]byte{'#', accent[1], accent[1], accent[2], accent[2], accent[3], accent[3]})
+	}
+
+	return accent, nil
+}
+
+// loadShortcuts returns the saved shortcut map, falling back to the defaults
+// when nothing is stored or the stored value no longer validates.
+func loadShortcuts(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	raw, ok, err := store.GetUserPref(ctx, db, shortcutsPrefKey)
+	if err != nil {
+		return nil, fmt.Errorf("load shortcuts: %w", err)
+	}
+
+	if !ok {
+		return maps.Clone(defaultShortcuts), nil
+	}
+
+	var saved map[string]string
+
+	err = json.Unmarshal([]byte(raw), &saved)
+	if err == nil {
+		var shortcuts map[string]string
+
+		shortcuts, err = mergeShortcuts(saved)
+		if err == nil {
+			return shortcuts, nil
+		}
+	}
+
+	slog.Warn("ignoring invalid saved shortcuts", "err", err)
+
+	return maps.Clone(defaultShortcuts), nil
+}
+
+// mergeShortcuts validates a posted shortcut map and fills in defaults for
+// actions it leaves out. Keys are lowercased to match how the frontend reads
+// key events.
+func mergeShortcuts(posted map[string]string) (map[string]string, error) {
+	shortcuts := maps.Clone(defaultShortcuts)
+
+	for action, key := range posted {
+		if _, ok := defaultShortcuts[action]; !ok {
+			return nil, fmt.Errorf("%w %q", errUnknownShortcutAction, action)
+		}
+
+		normalized, ok := normalizeShortcutKey(key)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q for %s", errInvalidShortcutKey, key, action)
+		}
+
+		shortcuts[action] = normalized
+	}
+
+	assigned := make(map[string]string, len(shortcuts))
+	for _, action := range slices.Sorted(maps.Keys(shortcuts)) {
+		key := shortcuts[action]
+		if other, taken := assigned[key]; taken {
+			return nil, fmt.Errorf("%w: %q (%s, %s)", errDuplicateShortcutKey, key, other, action)
+		}
+
+		assigned[key] = action
+	}
+
+	return shortcuts, nil
+}
+
+func normalizeShortcutKey(key string) (string, bool) {
+	r, size := utf8.DecodeRuneInString(key)
+	if size == 0 || size != len(key) || r == utf8.RuneError {
+		return "", false
+	}
+
+	if !unicode.IsPrint(r) || unicode.IsSpace(r) {
+		return "", false
+	}
+
+	return string(unicode.ToLower(r)), true
+}
+
+type opmlImportCounts struct {
+	imported int
+	skipped  int
+	// limited counts feeds left out because the feed limit was reached.
+	limited int
+}
+
+func (a *App) handleImportOPML(w http.ResponseWriter, r *http.Request) {
+	subscriptions, message := parseOPMLUpload(w, r)
+	if message != "" {
+		a.renderOPMLImportResponse(w, r, "error", message)
+
+		return
+	}
+
+	a.renderOPMLImportResult(w, r, a.importOPMLSubscriptions(r.Context(), subscriptions))
+}
+
+// handleImportOPMLURL imports a subscription list hosted online. The fetch
+// goes through the image proxy's client and host checks so the URL cannot be
+// used to reach private addresses.
+func (a *App) handleImportOPMLURL(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		a.renderOPMLImportResponse(w, r, "error", "invalid form")
+
+		return
+	}
+
+	opmlURL, ok := normalizeWebURL(r.FormValue("url"))
+	if !ok {
+		a.renderOPMLImportResponse(w, r, "error", "OPML URL must be an http or https URL")
+
+		return
+	}
+
+	subscriptions, message := a.fetchOPML(r.Context(), opmlURL)
+	if message != "" {
+		a.renderOPMLImportResponse(w, r, "error", message)
+
+		return
+	}
+
+	a.renderOPMLImportResult(w, r, a.importOPMLSubscriptions(r.Context(), subscriptions))
+}
+
+//nolint:gocritic // Tuple return mirrors parseOPMLUpload.
+func (a *App) fetchOPML(ctx context.Context, opmlURL string) ([]opml.Subscription, string) {
+	target, err := url.Parse(opmlURL)
+	if err != nil || !content.IsAllowedResolvedProxyURL(ctx, target, a.imageProxyLookup) {
+		return nil, "OPML URL points to a disallowed host"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), http.NoBody)
+	if err != nil {
+		return nil, "invalid OPML URL"
+	}
+
+	req.Header.Set("User-Agent", content.ImageProxyUserAgent)
+	req.Header.Set("Accept", "text/x-opml,application/xml,text/xml;q=0.9,*/*;q=0.5")
+
+	resp, err := a.imageProxyClient.Do(req)
+	if err != nil {
+		slog.Warn("opml url fetch failed", "target_host", target.Host, "err", err)
+
+		return nil, "failed to fetch OPML"
+	}
+
+	defer func() {
+		closeErr := resp.Body.Close()
+		if closeErr != nil {
+			log.Printf("opml url close body: %v", closeErr)
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, "OPML URL returned status " + strconv.Itoa(resp.StatusCode)
+	}
+
+	// opml.Parse stops reading past its own size limit.
+	subscriptions, err := opml.Parse(resp.Body)
+	if errors.Is(err, opml.ErrDocumentRejected) {
+		return nil, "OPML file is too large or uses unsupported XML features"
+	}
+
+	if err != nil {
+		return nil, "invalid OPML file"
+	}
+
+	return subscriptions, ""
+}
+
+//nolint:gocritic // Tuple return keeps upload parsing call sites simple.
+func parseOPMLUpload(w http.ResponseWriter, r *http.Request) ([]opml.Subscription, string) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxOPMLUploadBytes)
+
+	parseErr := r.ParseMultipartForm(maxOPMLUploadBytes)
+	if parseErr != nil {
+		return nil, "invalid OPML upload"
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, "missing OPML file"
+	}
+
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			log.Printf("opml upload close: %v", closeErr)
+		}
+	}()
+
+	subscriptions, err := opml.Parse(file)
+	if errors.Is(err, opml.ErrDocumentRejected) {
+		return nil, "OPML file is too large or uses unsupported XML features"
+	}
+
+	if err != nil {
+		return nil, "invalid OPML file"
+	}
+
+	return subscriptions, ""
+}
+
+func (a *App) importOPMLSubscriptions(ctx context.Context, subscriptions []opml.Subscription) opmlImportCounts {
+	var counts opmlImportCounts
+
+	for _, subscription := range subscriptions {
+		feedURL, err := feed.NormalizeURL(subscription.URL)
+		if err != nil {
+			counts.skipped++
+
+			continue
+		}
+
+		feedTitle := subscribeFeedTitle(subscription.Title, feedURL)
+
+		_, upsertErr := store.UpsertFeed(ctx, a.db, feedURL, feedTitle)
+		if errors.Is(upsertErr, store.ErrFeedLimitReached) {
+			counts.limited++
+
+			continue
+		}
+
+		if upsertErr != nil {
+			counts.skipped++
+
+			continue
+		}
+
+		counts.imported++
+	}
+
+	return counts
+}
+
+// renderOPMLImportResult reports an import, as an error when no feed from
+// the list was imported.
+func (a *App) renderOPMLImportResult(w http.ResponseWriter, r *http.Request, counts opmlImportCounts) {
+	messageClass := "success"
+	if counts.imported == 0 {
+		messageClass = "error"
+	}
+
+	a.renderOPMLImportResponse(w, r, messageClass, opmlImportMessage(counts))
+}
+
+func (a *App) renderOPMLImportResponse(w http.ResponseWriter, r *http.Request, messageClass, message string) {
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
 
 		return
 	}
 
-	message := opmlImportMessage(imported, skipped, fallbackMessage)
 	update := messageClass == "success"
 
 	var data subscribeResponseData
@@ -501,17 +1688,239 @@ func (a *App) renderOPMLImportResponse(
 	a.renderTemplate(w, "opml_import_response", data)
 }
 
-func opmlImportMessage(imported, skipped int, fallbackMessage string) string {
-	message := fallbackMessage
-	if message == "" {
-		message = "Imported " + strconv.Itoa(imported) + " feed"
-		if imported != 1 {
+func opmlImportMessage(counts opmlImportCounts) string {
+	var message string
+
+	switch {
+	case counts.imported > 0:
+		message = "Imported " + strconv.Itoa(counts.imported) + " feed"
+		if counts.imported != 1 {
 			message += "s"
 		}
+	case counts.limited > 0:
+		message = "feed limit reached; no feeds imported"
+	default:
+		message = "no valid feeds found in OPML"
+	}
+
+	var notes []string
+
+	if counts.skipped > 0 {
+		notes = append(notes, strconv.Itoa(counts.skipped)+" skipped")
+	}
+
+	if counts.limited > 0 {
+		notes = append(notes, strconv.Itoa(counts.limited)+" skipped over the feed limit")
+	}
+
+	if len(notes) > 0 {
+		message += " (" + strings.Join(notes, ", ") + ")"
+	}
+
+	return message
+}
+
+func (a *App) handleExportBackup(w http.ResponseWriter, r *http.Request) {
+	feeds, err := store.ListBackupFeeds(r.Context(), a.db)
+	if err != nil {
+		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+
+		return
+	}
+
+	now := time.Now().UTC()
+	filename := "pulse-rss-backup-" + now.Format("20060102") + ".json"
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	// The response is already committed once streaming starts, so a failure
+	// part way through can only be logged; the truncated JSON will not parse.
+	err = backup.Write(w, now, feeds, a.backupItems(r.Context()))
+	if err != nil {
+		log.Printf("export backup: %v", err)
+	}
+}
+
+// backupItems yields every stored item a page at a time so the export never
+// holds the whole items table in memory.
+func (a *App) backupItems(ctx context.Context) iter.Seq2[backup.Item, error] {
+	return func(yield func(backup.Item, error) bool) {
+		var afterID int64
+
+		for {
+			items, lastID, err := store.ListBackupItems(ctx, a.db, afterID, backupItemPageSize)
+			if err != nil {
+				yield(backup.Item{}, err)
+
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(items) < backupItemPageSize {
+				return
+			}
+
+			afterID = lastID
+		}
+	}
+}
+
+type backupImportCounts struct {
+	feeds   int
+	items   int
+	skipped int
+}
+
+func (a *App) handleImportBackup(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBackupUploadBytes)
+
+	upload, err := backupUploadReader(r)
+	if err != nil {
+		a.renderBackupImportResponse(w, r, "error", err.Error())
+
+		return
+	}
+
+	counts, err := a.restoreBackup(r.Context(), upload)
+	if err != nil {
+		log.Printf("import backup: %v", err)
+
+		message := "failed to restore backup"
+		if errors.Is(err, backup.ErrInvalidDocument) {
+			message = "invalid backup file"
+		}
+
+		if errors.Is(err, store.ErrFeedLimitReached) {
+			message = "feed limit reached"
+		}
+
+		if counts.feeds > 0 || counts.items > 0 {
+			message += " after " + backupImportMessage(counts)
+		}
+
+		a.renderBackupImportResponse(w, r, "error", message)
+
+		return
+	}
+
+	a.renderBackupImportResponse(w, r, "success", "Restored "+backupImportMessage(counts))
+}
+
+// backupUploadReader returns the uploaded document: the "file" field of a
+// multipart form, as sent by the import button, or the raw request body so the
+// endpoint also accepts a plain JSON POST.
+func backupUploadReader(r *http.Request) (io.Reader, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, errBackupFileMissing
+	}
+
+	for {
+		part, partErr := reader.NextPart()
+		if partErr != nil {
+			return nil, errBackupFileMissing
+		}
+
+		if part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
+
+// restoreBackup applies feeds and items as they are decoded. Items whose feed
+// was skipped or is missing from the document are skipped too.
+func (a *App) restoreBackup(ctx context.Context, upload io.Reader) (backupImportCounts, error) {
+	var counts backupImportCounts
+
+	feedIDs := make(map[string]int64)
+
+	err := backup.Read(upload, func(entry *backup.Feed) error {
+		if !store.IsLocalFeedURL(entry.URL) {
+			normalized, normalizeErr := feed.NormalizeURL(entry.URL)
+			if normalizeErr != nil {
+				counts.skipped++
+
+				return nil
+			}
+
+			entry.URL = normalized
+		}
+
+		feedID, restoreErr := store.RestoreBackupFeed(ctx, a.db, entry)
+		if restoreErr != nil {
+			return restoreErr
+		}
+
+		feedIDs[entry.URL] = feedID
+		counts.feeds++
+
+		return nil
+	}, func(entry *backup.Item) error {
+		feedID, ok := feedIDs[entry.FeedURL]
+		if !ok || strings.TrimSpace(entry.GUID) == "" {
+			counts.skipped++
+
+			return nil
+		}
+
+		restoreErr := store.RestoreBackupItem(ctx, a.db, feedID, entry)
+		if restoreErr != nil {
+			return restoreErr
+		}
+
+		counts.items++
+
+		return nil
+	})
+	if err != nil {
+		return counts, fmt.Errorf("restore backup: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (a *App) renderBackupImportResponse(w http.ResponseWriter, r *http.Request, messageClass, message string) {
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	var data subscribeResponseData
+
+	data.Message = message
+	data.MessageClass = messageClass
+	data.Feeds = feeds
+	data.Update = true
+	data.FeedEditMode = feedEditModeEnabled(r)
+	a.renderTemplate(w, "opml_import_response", data)
+}
+
+func backupImportMessage(counts backupImportCounts) string {
+	message := strconv.Itoa(counts.feeds) + " feed"
+	if counts.feeds != 1 {
+		message += "s"
+	}
+
+	message += " and " + strconv.Itoa(counts.items) + " item"
+	if counts.items != 1 {
+		message += "s"
 	}
 
-	if skipped > 0 {
-		message += " (" + strconv.Itoa(skipped) + " skipped)"
+	if counts.skipped > 0 {
+		message += " (" + strconv.Itoa(counts.skipped) + " skipped)"
 	}
 
 	return message
@@ -522,7 +1931,7 @@ func (a *App) handleEnterFeedEditMode(w http.ResponseWriter, r *http.Request) {
 
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
 
 		return
 	}
@@ -541,7 +1950,7 @@ func (a *App) handleCancelFeedEditMode(w http.ResponseWriter, r *http.Request) {
 
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
 
 		return
 	}
@@ -558,7 +1967,7 @@ func (a *App) handleCancelFeedEditMode(w http.ResponseWriter, r *http.Request) {
 func (a *App) handleSaveFeedEditMode(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "invalid form", http.StatusBadRequest)
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
 
 		return
 	}
@@ -567,7 +1976,7 @@ func (a *App) handleSaveFeedEditMode(w http.ResponseWriter, r *http.Request) {
 
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
 
 		return
 	}
@@ -580,23 +1989,44 @@ func (a *App) handleSaveFeedEditMode(w http.ResponseWriter, r *http.Request) {
 
 	updates := parseFeedTitleUpdates(r.PostForm)
 
-	titleErr := a.applyFeedTitleUpdates(r.Context(), updates, deleteByID, titles)
-	if titleErr != nil {
-		http.Error(w, "failed to rename feed", http.StatusInternalServerError)
+	titleErr := a.applyFeedTitleUpdates(r.Context(), updates, deleteByID, titles)
+	if titleErr != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to rename feed")
+
+		return
+	}
+
+	tagErr := a.applyFeedTagUpdates(r.Context(), parseFeedTagUpdates(r.PostForm), deleteByID, feeds)
+	if tagErr != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to save feed tags")
+
+		return
+	}
+
+	colorErr := a.applyFeedColorUpdates(r.Context(), parseFeedColorUpdates(r.PostForm), deleteByID, feeds)
+	if colorErr != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to save feed colors")
+
+		return
+	}
+
+	timezoneErr := a.applyFeedTimezoneUpdates(r.Context(), parseFeedTimezoneUpdates(r.PostForm), deleteByID, feeds)
+	if timezoneErr != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to save feed time zones")
 
 		return
 	}
 
 	selectedFeedDeleted, err := a.applyFeedDeletes(r.Context(), deleteUpdates, deleteByID, selectedFeedID)
 	if err != nil {
-		http.Error(w, "failed to delete feed", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to delete feed")
 
 		return
 	}
 
 	reorderErr := a.applyFeedReorder(r.Context(), orderUpdates, deleteByID)
 	if reorderErr != nil {
-		http.Error(w, "failed to reorder feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to reorder feeds")
 
 		return
 	}
@@ -619,14 +2049,14 @@ func (a *App) renderFeedEditSaveResponse(
 ) {
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
 
 		return
 	}
 
 	selectedFeedID, itemList, err := a.feedEditSelection(r.Context(), selectedFeedID, deletedFeedID, feeds)
 	if err != nil {
-		http.Error(w, "failed to load items", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load items")
 
 		return
 	}
@@ -700,6 +2130,98 @@ func (a *App) applyFeedTitleUpdates(
 	return nil
 }
 
+func (a *App) applyFeedTagUpdates(
+	ctx context.Context,
+	updates map[int64][]string,
+	deleteByID map[int64]struct{},
+	feeds []view.FeedView,
+) error {
+	for _, listedFeed := range feeds {
+		nextTags, submitted := updates[listedFeed.ID]
+		if !submitted {
+			continue
+		}
+
+		if _, markedForDelete := deleteByID[listedFeed.ID]; markedForDelete {
+			continue
+		}
+
+		for _, tag := range nextTags {
+			if slices.Contains(listedFeed.Tags, tag) {
+				continue
+			}
+
+			err := store.AddFeedTag(ctx, a.db, listedFeed.ID, tag)
+			if err != nil {
+				return fmt.Errorf("add feed tag for %d: %w", listedFeed.ID, err)
+			}
+		}
+
+		for _, tag := range listedFeed.Tags {
+			if slices.Contains(nextTags, tag) {
+				continue
+			}
+
+			err := store.RemoveFeedTag(ctx, a.db, listedFeed.ID, tag)
+			if err != nil {
+				return fmt.Errorf("remove feed tag for %d: %w", listedFeed.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *App) applyFeedColorUpdates(
+	ctx context.Context,
+	updates map[int64]string,
+	deleteByID map[int64]struct{},
+	feeds []view.FeedView,
+) error {
+	for _, listedFeed := range feeds {
+		nextColor, submitted := updates[listedFeed.ID]
+		if !submitted || nextColor == listedFeed.Color {
+			continue
+		}
+
+		if _, markedForDelete := deleteByID[listedFeed.ID]; markedForDelete {
+			continue
+		}
+
+		err := store.SetFeedColor(ctx, a.db, listedFeed.ID, nextColor)
+		if err != nil {
+			return fmt.Errorf("set feed color for %d: %w", listedFeed.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *App) applyFeedTimezoneUpdates(
+	ctx context.Context,
+	updates map[int64]string,
+	deleteByID map[int64]struct{},
+	feeds []view.FeedView,
+) error {
+	for _, listedFeed := range feeds {
+		nextTimezone, submitted := updates[listedFeed.ID]
+		if !submitted || nextTimezone == listedFeed.Timezone {
+			continue
+		}
+
+		if _, markedForDelete := deleteByID[listedFeed.ID]; markedForDelete {
+			continue
+		}
+
+		err := store.SetFeedTimezone(ctx, a.db, listedFeed.ID, nextTimezone)
+		if err != nil {
+			return fmt.Errorf("set feed time zone for %d: %w", listedFeed.ID, err)
+		}
+	}
+
+	return nil
+}
+
 func feedTitleUpdate(nextTitle, currentTitle, originalTitle string) (string, bool) {
 	if nextTitle == currentTitle {
 		return "", false
@@ -725,7 +2247,7 @@ func (a *App) applyFeedDeletes(
 			continue
 		}
 
-		deleteErr := store.DeleteFeed(ctx, a.db, feedID)
+		deleteErr := a.deleteFeed(ctx, feedID)
 		if deleteErr != nil {
 			return false, fmt.Errorf("delete feed %d: %w", feedID, deleteErr)
 		}
@@ -760,6 +2282,14 @@ func (a *App) applyFeedReorder(ctx context.Context, orderUpdates []int64, delete
 	return nil
 }
 
+func (a *App) deleteFeed(ctx context.Context, feedID int64) error {
+	if a.keepHistoryOnDelete {
+		return store.DeleteFeedKeepingHistory(ctx, a.db, feedID)
+	}
+
+	return store.DeleteFeed(ctx, a.db, feedID)
+}
+
 func (a *App) feedEditSelection(
 	ctx context.Context,
 	selectedFeedID int64,
@@ -771,7 +2301,7 @@ func (a *App) feedEditSelection(
 		return nextFeedID, nil, nil
 	}
 
-	itemList, err := store.LoadItemList(ctx, a.db, nextFeedID)
+	itemList, err := store.LoadItemList(ctx, a.db, nextFeedID, a.itemPageSize)
 	if err != nil {
 		return 0, nil, fmt.Errorf("load item list for feed %d: %w", nextFeedID, err)
 	}
@@ -779,6 +2309,9 @@ func (a *App) feedEditSelection(
 	return nextFeedID, itemList, nil
 }
 
+// handleFeedItems renders a feed's items, or writes them as JSON when the
+// request asks for it. A JSON read is not a visit: it leaves the feed's visit
+// time and the remembered selection alone.
 func (a *App) handleFeedItems(w http.ResponseWriter, r *http.Request) {
 	feedID, ok := parsePathInt64(r, "feedID")
 	if !ok {
@@ -787,7 +2320,245 @@ func (a *App) handleFeedItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Add("Vary", "Accept")
+
+	if wantsJSON(r) {
+		itemList, err := a.loadItemList(r, feedID)
+		if err != nil {
+			http.Error(w, "failed to load items", http.StatusInternalServerError)
+
+			return
+		}
+
+		writeJSON(w, newItemListJSON(itemList))
+
+		return
+	}
+
+	a.renderVisitedFeedItems(w, r, feedID)
+}
+
+// handleFeedPeek renders the feed's newest headlines for the sidebar popover.
+// Unlike opening the feed, it leaves the feed's visit time alone.
+func (a *App) handleFeedPeek(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	items, err := store.ListRecentItems(r.Context(), a.db, feedID, sidebarPeekItemLimit)
+	if err != nil {
+		http.Error(w, "failed to load items", http.StatusInternalServerError)
+
+		return
+	}
+
+	a.renderTemplate(w, "feed_peek", items)
+}
+
+// renderVisitedFeedItems renders the feed's items against its previous visit
+// time, then records this visit so later arrivals are flagged as new and the
+// feed is reopened after a reload.
+func (a *App) renderVisitedFeedItems(w http.ResponseWriter, r *http.Request, feedID int64) {
+	visitedAt := time.Now().UTC()
+
 	a.renderItemListResponse(w, r, feedID)
+
+	err := store.MarkFeedVisited(r.Context(), a.db, feedID, visitedAt)
+	if err != nil {
+		slog.Warn("mark feed visited failed", "feed_id", feedID, "err", err)
+	}
+
+	err = store.SetUserPref(r.Context(), a.db, selectedFeedPrefKey, strconv.FormatInt(feedID, 10))
+	if err != nil {
+		slog.Warn("remember selected feed failed", "feed_id", feedID, "err", err)
+	}
+}
+
+func (a *App) handleTagItems(w http.ResponseWriter, r *http.Request) {
+	tag, ok := store.NormalizeTag(r.PathValue("tag"))
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	taggedFeeds, err := store.ListFeedsByTag(r.Context(), a.db, tag)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load tagged feeds")
+
+		return
+	}
+
+	items, err := store.ListItemsByTag(r.Context(), a.db, tag)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load items")
+
+		return
+	}
+
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	data := tagItemListResponseData{
+		TagList:        &view.TagItemListData{Tag: tag, Feeds: taggedFeeds, Items: items},
+		Feeds:          feeds,
+		SelectedFeedID: 0,
+		FeedEditMode:   feedEditModeEnabled(r),
+	}
+	a.renderTemplate(w, "tag_item_list_response", data)
+}
+
+// handleTodayItems lists the unread items published since midnight in the
+// configured time zone, grouped by feed.
+func (a *App) handleTodayItems(w http.ResponseWriter, r *http.Request) {
+	items, err := store.ListItemsSince(r.Context(), a.db, startOfDay(time.Now(), a.location))
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load items")
+
+		return
+	}
+
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	data := todayItemListResponseData{
+		Today:          &view.TodayItemListData{Groups: groupItemsByFeed(items, feeds), Count: len(items)},
+		Feeds:          feeds,
+		SelectedFeedID: 0,
+		FeedEditMode:   feedEditModeEnabled(r),
+	}
+	a.renderTemplate(w, "today_item_list_response", data)
+}
+
+func startOfDay(now time.Time, location *time.Location) time.Time {
+	local := now.In(location)
+	year, month, day := local.Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// groupItemsByFeed splits items, already ordered feed by feed, into one group
+// per feed titled from feeds.
+func groupItemsByFeed(items []view.ItemView, feeds []view.FeedView) []view.FeedItemGroup {
+	titles := make(map[int64]string, len(feeds))
+	for idx := range feeds {
+		titles[feeds[idx].ID] = feeds[idx].Title
+	}
+
+	var groups []view.FeedItemGroup
+
+	for idx := range items {
+		item := items[idx]
+		if len(groups) == 0 || groups[len(groups)-1].FeedID != item.FeedID {
+			groups = append(groups, view.FeedItemGroup{Title: titles[item.FeedID], FeedID: item.FeedID})
+		}
+
+		last := &groups[len(groups)-1]
+		last.Items = append(last.Items, item)
+	}
+
+	return groups
+}
+
+func (a *App) handleNextUnreadFeed(w http.ResponseWriter, r *http.Request) {
+	afterFeedID, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("after")), 10, 64)
+	if err != nil {
+		afterFeedID = 0
+	}
+
+	feedID, err := store.NextFeedWithUnread(r.Context(), a.db, afterFeedID)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to find next unread feed")
+
+		return
+	}
+
+	if feedID == 0 {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	a.renderVisitedFeedItems(w, r, feedID)
+}
+
+type feedSearchResult struct {
+	Title  string `json:"title"`
+	Path   string `json:"path"`
+	ID     int64  `json:"id"`
+	Unread int    `json:"unread"`
+}
+
+// handleFeedSearch backs the jump-to-feed palette: it lists the feeds whose
+// display title contains q, ignoring case, ranked by searchFeeds.
+func (a *App) handleFeedSearch(w http.ResponseWriter, r *http.Request) {
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, searchFeeds(feeds, r.URL.Query().Get("q"), feedSearchLimit))
+}
+
+// searchFeeds returns up to limit feeds whose title contains query. Titles
+// starting with the query come first, then those with more unread items;
+// ties keep the sidebar order. An empty query matches every feed.
+func searchFeeds(feeds []view.FeedView, query string, limit int) []feedSearchResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	type match struct {
+		feed   view.FeedView
+		prefix bool
+	}
+
+	var matches []match
+
+	for _, candidate := range feeds {
+		title := strings.ToLower(candidate.Title)
+		if !strings.Contains(title, query) {
+			continue
+		}
+
+		matches = append(matches, match{feed: candidate, prefix: strings.HasPrefix(title, query)})
+	}
+
+	slices.SortStableFunc(matches, func(left, right match) int {
+		if left.prefix != right.prefix {
+			if left.prefix {
+				return -1
+			}
+
+			return 1
+		}
+
+		return right.feed.UnreadCount - left.feed.UnreadCount
+	})
+
+	results := make([]feedSearchResult, 0, min(len(matches), limit))
+
+	for _, found := range matches[:min(len(matches), limit)] {
+		results = append(results, feedSearchResult{
+			Title:  found.feed.Title,
+			Path:   fmt.Sprintf("/feeds/%d/items", found.feed.ID),
+			ID:     found.feed.ID,
+			Unread: found.feed.UnreadCount,
+		})
+	}
+
+	return results
 }
 
 func (a *App) handleFeedItemsPoll(w http.ResponseWriter, r *http.Request) {
@@ -807,6 +2578,120 @@ func (a *App) handleFeedItemsPoll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	a.renderPollResponse(w, r, feedID, count)
+}
+
+// handleFeedItemsWait is a long-poll variant of handleFeedItemsPoll for a feed
+// being watched closely: it answers as soon as the feed has items after
+// ?after= (or after_id), waking when a refresh or ingest stores items, and
+// answers 204 No Content once itemWaitTimeout passes without any.
+func (a *App) handleFeedItemsWait(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	afterID, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("after")), 10, 64)
+	if err != nil {
+		afterID = parseAfterID(r)
+	}
+
+	deadlineErr := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(a.itemWaitTimeout + itemWaitWriteSlack))
+	if deadlineErr != nil && !errors.Is(deadlineErr, http.ErrNotSupported) {
+		slog.Warn("extend long poll write deadline failed", "feed_id", feedID, "err", deadlineErr)
+	}
+
+	timeout := time.NewTimer(a.itemWaitTimeout)
+	defer timeout.Stop()
+
+	for {
+		changed := a.itemNotifier.wait(feedID)
+
+		count, countErr := store.CountItemsAfter(r.Context(), a.db, feedID, afterID)
+		if countErr != nil {
+			http.Error(w, "failed to check new items", http.StatusInternalServerError)
+
+			return
+		}
+
+		if count > 0 {
+			a.renderPollResponse(w, r, feedID, count)
+
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-timeout.C:
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+type unreadCountResponse struct {
+	Unread int `json:"unread"`
+}
+
+// handleUnreadCount reports the unread total across every feed, for tab
+// titles and badges kept current outside the page.
+func (a *App) handleUnreadCount(w http.ResponseWriter, r *http.Request) {
+	count, err := store.CountUnreadItems(r.Context(), a.db)
+	if err != nil {
+		http.Error(w, "failed to count unread items", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, unreadCountResponse{Unread: count})
+}
+
+type consistencyResponse struct {
+	OrphanItems        int64 `json:"orphan_items"`
+	OrphanTombstones   int64 `json:"orphan_tombstones"`
+	ShadowedTombstones int64 `json:"shadowed_tombstones"`
+	OrphanTags         int64 `json:"orphan_tags"`
+	OrphanSeenTitles   int64 `json:"orphan_seen_titles"`
+	Repaired           int64 `json:"repaired"`
+}
+
+// handleVerifyConsistency removes rows left pointing at deleted feeds, and
+// tombstones shadowing live items, and reports what it removed.
+func (a *App) handleVerifyConsistency(w http.ResponseWriter, r *http.Request) {
+	report, err := store.VerifyConsistency(r.Context(), a.db)
+	if err != nil {
+		http.Error(w, "failed to verify consistency", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, consistencyResponse{
+		OrphanItems:        report.OrphanItems,
+		OrphanTombstones:   report.OrphanTombstones,
+		ShadowedTombstones: report.ShadowedTombstones,
+		OrphanTags:         report.OrphanTags,
+		OrphanSeenTitles:   report.OrphanSeenTitles,
+		Repaired:           report.Total(),
+	})
+}
+
+// totalUnread sums the listed feeds' unread counts, which the pages already
+// load, so the tab title needs no extra query.
+func totalUnread(feeds []view.FeedView) int {
+	total := 0
+	for _, listedFeed := range feeds {
+		total += listedFeed.UnreadCount
+	}
+
+	return total
+}
+
+func (a *App) renderPollResponse(w http.ResponseWriter, r *http.Request, feedID int64, count int) {
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
 		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
@@ -828,6 +2713,7 @@ func (a *App) handleFeedItemsPoll(w http.ResponseWriter, r *http.Request) {
 
 	data.Banner = view.NewItemsData{FeedID: feedID, Count: count, SwapOOB: false}
 	data.Feeds = feeds
+	data.UnreadTotal = totalUnread(feeds)
 	data.RefreshDisplay = refreshDisplay
 	data.SelectedFeedID = feedID
 	data.FeedEditMode = feedEditModeEnabled(r)
@@ -866,6 +2752,37 @@ func (a *App) handleFeedItemsNew(w http.ResponseWriter, r *http.Request) {
 	a.renderTemplate(w, "item_new_response", data)
 }
 
+// handleFeedItemsPage renders the page of a feed's items after ?before= for
+// the list's "Load more" control, which it replaces with the items and a
+// control for the page after them.
+func (a *App) handleFeedItemsPage(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	beforeID, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("before")), 10, 64)
+	if err != nil || beforeID <= 0 {
+		a.renderError(w, r, http.StatusBadRequest, "invalid page")
+
+		return
+	}
+
+	items, nextPage, err := store.LoadItemPage(r.Context(), a.db, feedID, beforeID, a.itemPageSize)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load items")
+
+		return
+	}
+
+	a.renderTemplate(w, "item_page_response", itemPageResponseData{
+		Items:    items,
+		NextPage: nextPage,
+	})
+}
+
 func (a *App) handleItemExpanded(w http.ResponseWriter, r *http.Request) {
 	itemID, ok := parsePathInt64(r, "itemID")
 	if !ok {
@@ -874,14 +2791,27 @@ func (a *App) handleItemExpanded(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Add("Vary", "Accept")
+
 	item, err := store.GetItem(r.Context(), a.db, itemID)
 	if err != nil {
-		http.Error(w, "item not found", http.StatusNotFound)
+		if wantsJSON(r) {
+			http.Error(w, "item not found", http.StatusNotFound)
+		} else {
+			a.renderError(w, r, http.StatusNotFound, "item not found")
+		}
 
 		return
 	}
 
 	item.IsActive = parseSelectedItemID(r) == item.ID
+
+	if wantsJSON(r) {
+		writeJSON(w, newItemJSON(item))
+
+		return
+	}
+
 	a.renderTemplate(w, "item_expanded", item)
 }
 
@@ -895,7 +2825,7 @@ func (a *App) handleItemCompact(w http.ResponseWriter, r *http.Request) {
 
 	item, err := store.GetItem(r.Context(), a.db, itemID)
 	if err != nil {
-		http.Error(w, "item not found", http.StatusNotFound)
+		a.renderError(w, r, http.StatusNotFound, "item not found")
 
 		return
 	}
@@ -915,7 +2845,7 @@ func (a *App) handleToggleRead(w http.ResponseWriter, r *http.Request) {
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "invalid form", http.StatusBadRequest)
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
 
 		return
 	}
@@ -924,7 +2854,7 @@ func (a *App) handleToggleRead(w http.ResponseWriter, r *http.Request) {
 
 	err = store.ToggleRead(r.Context(), a.db, itemID)
 	if err != nil {
-		http.Error(w, "failed to update item", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to update item")
 
 		return
 	}
@@ -933,14 +2863,14 @@ func (a *App) handleToggleRead(w http.ResponseWriter, r *http.Request) {
 
 	feedID, err := store.GetFeedIDByItem(r.Context(), a.db, itemID)
 	if err != nil {
-		http.Error(w, "item not found", http.StatusNotFound)
+		a.renderError(w, r, http.StatusNotFound, "item not found")
 
 		return
 	}
 
 	item, err := store.GetItem(r.Context(), a.db, itemID)
 	if err != nil {
-		http.Error(w, "item not found", http.StatusNotFound)
+		a.renderError(w, r, http.StatusNotFound, "item not found")
 
 		return
 	}
@@ -949,7 +2879,7 @@ func (a *App) handleToggleRead(w http.ResponseWriter, r *http.Request) {
 
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
 
 		return
 	}
@@ -964,6 +2894,143 @@ func (a *App) handleToggleRead(w http.ResponseWriter, r *http.Request) {
 	a.renderTemplate(w, "item_toggle_response", data)
 }
 
+// handleDismissItem hides an item without marking it read. The response
+// carries only the refreshed feed list, so the item's card is swapped out.
+func (a *App) handleDismissItem(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := parsePathInt64(r, "itemID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	feedID, err := store.GetFeedIDByItem(r.Context(), a.db, itemID)
+	if err != nil {
+		a.renderError(w, r, http.StatusNotFound, "item not found")
+
+		return
+	}
+
+	err = store.DismissItem(r.Context(), a.db, itemID)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to dismiss item")
+
+		return
+	}
+
+	slog.Info("item dismissed", "item_id", itemID)
+
+	feeds, err := store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	data := itemListResponseData{
+		ItemList:       nil,
+		RefreshResult:  nil,
+		Feeds:          feeds,
+		SelectedFeedID: feedID,
+		FeedEditMode:   feedEditModeEnabled(r),
+	}
+	a.renderTemplate(w, "item_dismiss_response", data)
+}
+
+// handleReadNext marks an item read and swaps in the next unread item of the
+// same feed, expanded and active. When none is left the feed's caught-up
+// notice is shown instead.
+func (a *App) handleReadNext(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := parsePathInt64(r, "itemID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	err := store.MarkRead(r.Context(), a.db, itemID)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to update item")
+
+		return
+	}
+
+	item, err := store.GetItem(r.Context(), a.db, itemID)
+	if err != nil {
+		a.renderError(w, r, http.StatusNotFound, "item not found")
+
+		return
+	}
+
+	data := readNextResponseData{
+		Item:           item,
+		SelectedFeedID: item.FeedID,
+		FeedEditMode:   feedEditModeEnabled(r),
+	}
+
+	next, err := store.NextUnreadItem(r.Context(), a.db, itemID)
+
+	switch {
+	case err == nil:
+		next.IsActive = true
+		next.SwapOOB = true
+		data.Next = &next
+	case errors.Is(err, sql.ErrNoRows):
+	default:
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load next item")
+
+		return
+	}
+
+	data.Feeds, err = store.ListFeeds(r.Context(), a.db)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
+
+		return
+	}
+
+	slog.Info("item read and advanced", "item_id", itemID, "caught_up", data.Next == nil)
+
+	a.renderTemplate(w, "item_read_next_response", data)
+}
+
+// handleMarkAllReadConfirm swaps the "Mark all read" button for a prompt
+// naming how many unread items it would mark, or back with ?cancel=1. Once the
+// prompt has been dismissed with "Don't ask again", it submits itself on load.
+func (a *App) handleMarkAllReadConfirm(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if r.URL.Query().Get("cancel") == "1" {
+		a.renderTemplate(w, "mark_read_button", feedID)
+
+		return
+	}
+
+	feed, err := store.GetFeed(r.Context(), a.db, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feed")
+
+		return
+	}
+
+	a.renderTemplate(w, "mark_read_confirm", markReadConfirmData{
+		FeedID:      feedID,
+		Count:       feed.UnreadCount,
+		SkipConfirm: skipReadConfirmEnabled(r),
+	})
+}
+
 //nolint:gosec // Mark-all-read logs include request-derived feed IDs for operational visibility.
 func (a *App) handleMarkAllRead(w http.ResponseWriter, r *http.Request) {
 	feedID, ok := parsePathInt64(r, "feedID")
@@ -973,9 +3040,13 @@ func (a *App) handleMarkAllRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.FormValue("skip_confirm") == "1" {
+		setSkipReadConfirmCookie(w)
+	}
+
 	err := store.MarkAllRead(r.Context(), a.db, feedID)
 	if err != nil {
-		http.Error(w, "failed to update items", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to update items")
 
 		return
 	}
@@ -985,6 +3056,136 @@ func (a *App) handleMarkAllRead(w http.ResponseWriter, r *http.Request) {
 	a.renderItemListResponse(w, r, feedID)
 }
 
+// handleMarkReadAbove marks read the items listed at or above the item named
+// by item_id, for catching up on part of a feed, and re-renders its list.
+//
+//nolint:gosec // Read-above logs include request-derived feed IDs for operational visibility.
+func (a *App) handleMarkReadAbove(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	itemID, err := strconv.ParseInt(r.FormValue("item_id"), 10, 64)
+	if err != nil || itemID <= 0 {
+		a.renderError(w, r, http.StatusBadRequest, "invalid item ID")
+
+		return
+	}
+
+	marked, err := store.MarkReadAbove(r.Context(), a.db, feedID, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.renderError(w, r, http.StatusNotFound, "item not found")
+
+		return
+	}
+
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to update items")
+
+		return
+	}
+
+	slog.Info("feed items above marked read", "feed_id", feedID, "item_id", itemID, "marked", marked)
+
+	a.renderItemListResponse(w, r, feedID)
+}
+
+// handleMarkItemsRead marks the items named by repeated item_id fields read,
+// typically the ones the page rendered once the reader scrolled past them
+// all, and re-renders the feed's list.
+//
+//nolint:gosec // Batch-read logs include request-derived feed IDs for operational visibility.
+func (a *App) handleMarkItemsRead(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
+
+		return
+	}
+
+	itemIDs, ok := formItemIDs(r)
+	if !ok {
+		a.renderError(w, r, http.StatusBadRequest, "invalid item ID")
+
+		return
+	}
+
+	marked, err := store.MarkItemsRead(r.Context(), a.db, feedID, itemIDs)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to update items")
+
+		return
+	}
+
+	slog.Info("feed items batch marked read", "feed_id", feedID, "marked", marked)
+
+	a.renderItemListResponse(w, r, feedID)
+}
+
+// handleMarkSeenItemsRead marks the items the reader scrolled past read as
+// they leave the feed. The frontend sends it just before switching feeds, so
+// nothing is rendered: the next feed's response brings the updated counts.
+func (a *App) handleMarkSeenItemsRead(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+
+		return
+	}
+
+	itemIDs, ok := formItemIDs(r)
+	if !ok {
+		http.Error(w, "invalid item ID", http.StatusBadRequest)
+
+		return
+	}
+
+	marked, err := store.MarkItemsRead(r.Context(), a.db, feedID, itemIDs)
+	if err != nil {
+		http.Error(w, "failed to update items", http.StatusInternalServerError)
+
+		return
+	}
+
+	slog.Info("feed seen items marked read", "feed_id", feedID, "marked", marked)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// formItemIDs returns the item_id values of a parsed form, reporting false
+// when any is not a positive ID.
+func formItemIDs(r *http.Request) ([]int64, bool) {
+	itemIDs := make([]int64, 0, len(r.PostForm["item_id"]))
+
+	for _, raw := range r.PostForm["item_id"] {
+		itemID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || itemID <= 0 {
+			return nil, false
+		}
+
+		itemIDs = append(itemIDs, itemID)
+	}
+
+	return itemIDs, true
+}
+
 //nolint:gosec // Sweep logs include request-derived feed IDs for operational visibility.
 func (a *App) handleSweepRead(w http.ResponseWriter, r *http.Request) {
 	feedID, ok := parsePathInt64(r, "feedID")
@@ -996,7 +3197,7 @@ func (a *App) handleSweepRead(w http.ResponseWriter, r *http.Request) {
 
 	deleted, err := store.SweepReadItems(r.Context(), a.db, feedID)
 	if err != nil {
-		http.Error(w, "failed to remove read items", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to remove read items")
 
 		return
 	}
@@ -1015,34 +3216,157 @@ func (a *App) handleRefreshFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	a.refreshMu.Lock()
-	_, err := feed.Refresh(r.Context(), a.db, feedID)
-	a.refreshMu.Unlock()
+	inserted, err := a.refreshFeed(r.Context(), feedID)
+	if err != nil {
+		slog.Warn("manual refresh failed", "feed_id", feedID, "err", err)
+	}
+
+	a.renderItemList(w, r, feedID, "", newRefreshResultView(inserted, err))
+}
+
+// newRefreshResultView summarizes a manual refresh for the refresh toast.
+func newRefreshResultView(inserted int, err error) *refreshResultView {
+	if err != nil {
+		message := "Refresh failed"
+		if code := feed.ErrorStatusCode(err); code != 0 {
+			message += fmt.Sprintf(": HTTP %d", code)
+		}
+
+		return &refreshResultView{Message: message, Failed: true}
+	}
+
+	switch inserted {
+	case 0:
+		return &refreshResultView{Message: "No new items", Failed: false}
+	case 1:
+		return &refreshResultView{Message: "1 new item", Failed: false}
+	default:
+		return &refreshResultView{Message: fmt.Sprintf("%d new items", inserted), Failed: false}
+	}
+}
+
+func (a *App) handleSaveFeedFetchSettings(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
+
+		return
+	}
+
+	settings, formErr := parseFeedFetchSettings(r.PostForm)
+	if formErr != "" {
+		a.renderItemListWithFetchSettingsError(w, r, feedID, formErr)
+
+		return
+	}
+
+	err = store.UpdateFeedFetchSettings(r.Context(), a.db, feedID, settings)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to save fetch settings")
+
+		return
+	}
+
+	a.renderItemListResponse(w, r, feedID)
+}
+
+// parseFeedFetchSettings reads the fetch settings form, returning a message
+// for the form when a value is invalid.
+func parseFeedFetchSettings(form url.Values) (store.FeedFetchSettings, string) {
+	settings := store.FeedFetchSettings{
+		UserAgent:               strings.TrimSpace(form.Get("user_agent")),
+		HTTPProxy:               strings.TrimSpace(form.Get("http_proxy")),
+		HTTPSOnly:               form.Get("https_only") == "1",
+		SuppressDuplicateTitles: form.Get("suppress_duplicate_titles") == "1",
+		SummarizeInList:         form.Get("summarize_in_list") == "1",
+		ImageReferrer:           "",
+		StripLeadingImage:       form.Get("strip_leading_image") == "1",
+		ImagesOnly:              form.Get("images_only") == "1",
+		CacheEnclosures:         form.Get("cache_enclosures") == "1",
+		SkipTombstones:          form.Get("skip_tombstones") == "1",
+		SecretURL:               form.Get("secret_url") == "1",
+		StableGUIDs:             form.Get("stable_guids") == "1",
+		DigestItems:             form.Get("digest_items") == "1",
+		MinAgeMinutes:           0,
+		SortByUpdated:           form.Get("sort_by_updated") == "1",
+	}
+
+	referrer, ok := content.NormalizeImageReferrer(form.Get("image_referrer"))
+	if !ok {
+		return settings, "unknown image referrer policy"
+	}
+
+	// The default sends no Referer, so it is stored as NULL like other unset overrides.
+	if referrer != content.ImageReferrerNone {
+		settings.ImageReferrer = referrer
+	}
+
+	if settings.HTTPProxy != "" {
+		_, err := feed.ParseProxyURL(settings.HTTPProxy)
+		if err != nil {
+			return settings, err.Error()
+		}
+	}
+
+	if raw := strings.TrimSpace(form.Get("min_age_minutes")); raw != "" {
+		minAge, err := strconv.Atoi(raw)
+		if err != nil || minAge < 0 || minAge > maxFeedMinAgeMinutes {
+			return settings, "minimum age must be a whole number of minutes from 0 to " +
+				strconv.Itoa(maxFeedMinAgeMinutes)
+		}
 
-	if err != nil {
-		slog.Warn("manual refresh failed", "feed_id", feedID, "err", err)
+		settings.MinAgeMinutes = minAge
 	}
 
-	a.renderItemListResponse(w, r, feedID)
+	return settings, ""
 }
 
 func (a *App) renderItemListResponse(w http.ResponseWriter, r *http.Request, feedID int64) {
-	itemList, err := store.LoadItemList(r.Context(), a.db, feedID)
+	a.renderItemList(w, r, feedID, "", nil)
+}
+
+func (a *App) renderItemListWithFetchSettingsError(
+	w http.ResponseWriter,
+	r *http.Request,
+	feedID int64,
+	fetchSettingsError string,
+) {
+	a.renderItemList(w, r, feedID, fetchSettingsError, nil)
+}
+
+func (a *App) renderItemList(
+	w http.ResponseWriter,
+	r *http.Request,
+	feedID int64,
+	fetchSettingsError string,
+	refreshResult *refreshResultView,
+) {
+	itemList, err := a.loadItemList(r, feedID)
 	if err != nil {
-		http.Error(w, "failed to load items", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load items")
 
 		return
 	}
 
+	itemList.FetchSettingsError = fetchSettingsError
+
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
 
 		return
 	}
 
 	data := itemListResponseData{
 		ItemList:       itemList,
+		RefreshResult:  refreshResult,
 		Feeds:          feeds,
 		SelectedFeedID: feedID,
 		FeedEditMode:   feedEditModeEnabled(r),
@@ -1050,6 +3374,31 @@ func (a *App) renderItemListResponse(w http.ResponseWriter, r *http.Request, fee
 	a.renderTemplate(w, "item_list_response", data)
 }
 
+// loadItemList loads the first page of a feed's items, narrowed to one of the
+// feed's own categories when the request names one.
+func (a *App) loadItemList(r *http.Request, feedID int64) (*view.ItemListData, error) {
+	itemList, err := store.LoadItemList(r.Context(), a.db, feedID, a.itemPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("load item list for feed %d: %w", feedID, err)
+	}
+
+	// Category chips in expanded items link here to narrow the list to
+	// items sharing one of the feed's own categories.
+	if category := strings.TrimSpace(r.URL.Query().Get("category")); category != "" {
+		itemList.Items, err = store.ListItemsInCategory(r.Context(), a.db, feedID, category)
+		if err != nil {
+			return nil, fmt.Errorf("list feed %d items in category: %w", feedID, err)
+		}
+
+		itemList.Category = category
+		itemList.NextPage.BeforeID = 0
+	}
+
+	itemList.Refresh.Refreshing = a.refreshes.running(feedID)
+
+	return itemList, nil
+}
+
 //nolint:gosec // Delete logs include request-derived feed IDs for operational visibility.
 func (a *App) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
 	feedID, ok := parsePathInt64(r, "feedID")
@@ -1061,36 +3410,86 @@ func (a *App) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
 
 	err := r.ParseForm()
 	if err != nil {
-		http.Error(w, "invalid form", http.StatusBadRequest)
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
 
 		return
 	}
 
 	selectedFeedID := parseSelectedFeedID(r)
 
-	err = store.DeleteFeed(r.Context(), a.db, feedID)
+	err = a.deleteFeed(r.Context(), feedID)
 	if err != nil {
-		http.Error(w, "failed to delete feed", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to delete feed")
 
 		return
 	}
 
 	slog.Info("feed deleted", "feed_id", feedID)
 
+	a.renderFeedsAfterDelete(w, r, selectedFeedID)
+}
+
+// handleDeleteFeeds deletes every feed named by a feed_id form value in one
+// transaction. IDs of feeds that no longer exist are skipped, so a stale list
+// still deletes the rest; an ID that is not a number fails the whole request.
+//
+//nolint:gosec // Delete logs include request-derived feed IDs for operational visibility.
+func (a *App) handleDeleteFeeds(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		a.renderError(w, r, http.StatusBadRequest, "invalid form")
+
+		return
+	}
+
+	feedIDs := make([]int64, 0, len(r.PostForm["feed_id"]))
+
+	for _, raw := range r.PostForm["feed_id"] {
+		feedID, parseErr := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if parseErr != nil || feedID <= 0 {
+			a.renderError(w, r, http.StatusBadRequest, "invalid feed id")
+
+			return
+		}
+
+		feedIDs = append(feedIDs, feedID)
+	}
+
+	if len(feedIDs) == 0 {
+		a.renderError(w, r, http.StatusBadRequest, "no feeds selected")
+
+		return
+	}
+
+	deleted, err := store.DeleteFeeds(r.Context(), a.db, feedIDs, a.keepHistoryOnDelete)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to delete feeds")
+
+		return
+	}
+
+	slog.Info("feeds deleted", "feed_ids", deleted, "skipped", len(feedIDs)-len(deleted))
+
+	a.renderFeedsAfterDelete(w, r, parseSelectedFeedID(r))
+}
+
+// renderFeedsAfterDelete re-renders the feed list once feeds are gone,
+// keeping the selected feed when it remains and otherwise moving to the first.
+func (a *App) renderFeedsAfterDelete(w http.ResponseWriter, r *http.Request, selectedFeedID int64) {
 	feeds, err := store.ListFeeds(r.Context(), a.db)
 	if err != nil {
-		http.Error(w, "failed to load feeds", http.StatusInternalServerError)
+		a.renderError(w, r, http.StatusInternalServerError, "failed to load feeds")
 
 		return
 	}
 
-	selectedFeedID = store.SelectRemainingFeed(selectedFeedID, feedID, feeds)
+	selectedFeedID = store.SelectRemainingFeed(selectedFeedID, 0, feeds)
 
 	var itemList *view.ItemListData
 	if selectedFeedID != 0 {
-		itemList, err = store.LoadItemList(r.Context(), a.db, selectedFeedID)
+		itemList, err = store.LoadItemList(r.Context(), a.db, selectedFeedID, a.itemPageSize)
 		if err != nil {
-			http.Error(w, "failed to load items", http.StatusInternalServerError)
+			a.renderError(w, r, http.StatusInternalServerError, "failed to load items")
 
 			return
 		}
@@ -1098,6 +3497,7 @@ func (a *App) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
 
 	data := itemListResponseData{
 		ItemList:       itemList,
+		RefreshResult:  nil,
 		Feeds:          feeds,
 		SelectedFeedID: selectedFeedID,
 		FeedEditMode:   feedEditModeEnabled(r),
@@ -1120,21 +3520,26 @@ func (a *App) handleImageProxy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	target, err := url.Parse(raw)
-	if err != nil || !content.IsAllowedResolvedProxyURL(r.Context(), target, a.imageProxyLookup) {
-		http.Error(w, "invalid url", http.StatusBadRequest)
+	referrer, ok := content.VerifyImageProxyQuery(r.URL.Query(), raw)
+	if !ok {
+		http.Error(w, "invalid signature", http.StatusForbidden)
 
 		return
 	}
 
-	req, err := content.BuildImageProxyRequest(r.Context(), target)
-	if err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+	target, err := url.Parse(raw)
+	if err != nil || !content.IsAllowedResolvedProxyURL(r.Context(), target, a.imageProxyLookup) {
+		http.Error(w, "invalid url", http.StatusBadRequest)
 
 		return
 	}
 
-	resp, err := a.imageProxyClient.Do(req)
+	// The deadline covers every upstream attempt and the body read, so retries
+	// never stretch a request past the proxy timeout.
+	ctx, cancel := context.WithTimeout(r.Context(), content.ImageProxyTimeout)
+	defer cancel()
+
+	resp, err := a.fetchImageUpstream(ctx, target, referrer, r.Header)
 	if err != nil {
 		http.Error(w, "upstream fetch failed", http.StatusBadGateway)
 
@@ -1148,6 +3553,13 @@ func (a *App) handleImageProxy(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	if resp.StatusCode == http.StatusNotModified {
+		setImageProxyCacheHeaders(w, resp)
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		slog.Debug(
 			"image proxy upstream non-2xx",
@@ -1170,16 +3582,12 @@ func (a *App) handleImageProxy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	contentType := resp.Header.Get("Content-Type")
-	if contentType == "" || !strings.HasPrefix(strings.ToLower(contentType), "image/") {
-		detected := http.DetectContentType(sniff)
-		if !strings.HasPrefix(detected, "image/") {
-			http.Error(w, "upstream did not return image content", http.StatusUnsupportedMediaType)
-
-			return
-		}
+	// The upstream Content-Type is not trusted; only allowlisted raster formats pass.
+	contentType, ok := content.DetectImageContentType(sniff)
+	if !ok {
+		http.Error(w, "upstream did not return a supported image", http.StatusUnsupportedMediaType)
 
-		contentType = detected
+		return
 	}
 
 	body, err := io.ReadAll(io.LimitReader(reader, content.ImageProxyMaxBodyBytes+1))
@@ -1196,7 +3604,16 @@ func (a *App) handleImageProxy(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", contentType)
+	setImageProxyCacheHeaders(w, resp)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+
+	_, writeErr := w.Write(body)
+	if writeErr != nil {
+		log.Printf("image proxy copy: %v", writeErr)
+	}
+}
 
+func setImageProxyCacheHeaders(w http.ResponseWriter, resp *http.Response) {
 	if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "" {
 		w.Header().Set("Cache-Control", cacheControl)
 	} else {
@@ -1210,13 +3627,103 @@ func (a *App) handleImageProxy(w http.ResponseWriter, r *http.Request) {
 	if modified := resp.Header.Get("Last-Modified"); modified != "" {
 		w.Header().Set("Last-Modified", modified)
 	}
+}
 
-	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+// fetchImageUpstream requests the proxied image, forwarding the client's
+// conditional headers so an unchanged image comes back as a bodyless 304.
+// With SetImageHTTPSUpgrade on, plain-http targets are tried over https first
+// so the upstream hop is encrypted when the host supports it, falling back to
+// the original URL when the upgraded request fails; that attempt's short dial
+// timeout leaves the fallback most of ctx's budget. The original URL is retried once after a short
+// delay when it fails to connect or answers 5xx. referrer is the feed's
+// verified image referrer policy, applied to whichever URL is requested.
+func (a *App) fetchImageUpstream(
+	ctx context.Context,
+	target *url.URL,
+	referrer string,
+	clientHeader http.Header,
+) (*http.Response, error) {
+	if upgraded, ok := content.UpgradeToHTTPS(target); ok && a.imageHTTPSUpgrade {
+		resp, err := a.doImageProxyRequest(ctx, a.imageUpgradeClient, upgraded, referrer, clientHeader)
+		if err == nil && imageUpstreamUsable(resp.StatusCode) {
+			return resp, nil
+		}
 
-	_, writeErr := w.Write(body)
-	if writeErr != nil {
-		log.Printf("image proxy copy: %v", writeErr)
+		if err == nil {
+			closeErr := resp.Body.Close()
+			if closeErr != nil {
+				log.Printf("image proxy close body: %v", closeErr)
+			}
+		}
+
+		slog.Debug("image proxy https upgrade failed", "target_host", target.Host)
+	}
+
+	resp, err := a.doImageProxyRequest(ctx, a.imageProxyClient, target, referrer, clientHeader)
+	if !imageUpstreamRetryable(resp, err) || ctx.Err() != nil {
+		return resp, err
+	}
+
+	if err == nil {
+		closeErr := resp.Body.Close()
+		if closeErr != nil {
+			log.Printf("image proxy close body: %v", closeErr)
+		}
+	}
+
+	slog.Debug("image proxy retrying upstream", "target_host", target.Host)
+
+	timer := time.NewTimer(imageProxyRetryDelay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("retry image upstream: %w", ctx.Err())
+	case <-timer.C:
+	}
+
+	return a.doImageProxyRequest(ctx, a.imageProxyClient, target, referrer, clientHeader)
+}
+
+func imageUpstreamUsable(status int) bool {
+	return status == http.StatusNotModified || (status >= http.StatusOK && status < http.StatusMultipleChoices)
+}
+
+// imageUpstreamRetryable reports whether an upstream image fetch failed in a
+// way a second attempt might fix: a connection that could not be made or was
+// cut short, or a 5xx answer. 4xx answers and blocked redirects are final.
+func imageUpstreamRetryable(resp *http.Response, err error) bool {
+	if err == nil {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (*App) doImageProxyRequest(
+	ctx context.Context,
+	client *http.Client,
+	target *url.URL,
+	referrer string,
+	clientHeader http.Header,
+) (*http.Response, error) {
+	req, err := content.BuildImageProxyRequest(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("build image proxy request: %w", err)
+	}
+
+	content.SetImageReferrer(req.Header, target, referrer)
+
+	content.CopyConditionalHeaders(req.Header, clientHeader)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch image upstream: %w", err)
 	}
+
+	return resp, nil
 }
 
 func (a *App) renderTemplate(w http.ResponseWriter, name string, data any) {
@@ -1231,6 +3738,94 @@ func (a *App) renderTemplate(w http.ResponseWriter, name string, data any) {
 	}
 }
 
+// handleEnclosure plays an item's enclosure from the local cache when it has
+// been downloaded there, and otherwise redirects to the original URL.
+func (a *App) handleEnclosure(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := parsePathInt64(r, "itemID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	enclosure, err := store.GetItemEnclosure(r.Context(), a.db, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, "failed to load enclosure", http.StatusInternalServerError)
+
+		return
+	}
+
+	if a.enclosureCache != nil && enclosure.Path != "" && a.serveCachedEnclosure(w, r, enclosure) {
+		return
+	}
+
+	target, err := url.Parse(enclosure.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (a *App) serveCachedEnclosure(w http.ResponseWriter, r *http.Request, enclosure store.Enclosure) bool {
+	file, err := os.Open(a.enclosureCache.Path(enclosure.Path))
+	if err != nil {
+		slog.Warn("cached enclosure unavailable", "item_id", enclosure.ItemID, "err", err)
+
+		return false
+	}
+
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			slog.Warn("cached enclosure close failed", "item_id", enclosure.ItemID, "err", closeErr)
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	err = store.TouchItemEnclosure(r.Context(), a.db, enclosure.ItemID, time.Now().UTC())
+	if err != nil {
+		slog.Warn("cached enclosure touch failed", "item_id", enclosure.ItemID, "err", err)
+	}
+
+	// Episodes take longer to stream than the server's write timeout allows.
+	err = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil {
+		slog.Debug("enclosure write deadline unchanged", "err", err)
+	}
+
+	w.Header().Set("Content-Type", enclosureContentType(enclosure.Type))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+
+	return true
+}
+
+// enclosureContentType passes through the audio and video types feeds declare
+// for their enclosures. Anything else, which a feed could use to have the
+// reader serve its own pages, is sent as an opaque download.
+func enclosureContentType(raw string) string {
+	mediaType, _, err := mime.ParseMediaType(raw)
+	if err == nil && (strings.HasPrefix(mediaType, "audio/") || strings.HasPrefix(mediaType, "video/")) {
+		return mediaType
+	}
+
+	return "application/octet-stream"
+}
+
 func parsePathInt64(r *http.Request, key string) (int64, bool) {
 	raw := strings.TrimSpace(r.PathValue(key))
 	if raw == "" {
@@ -1289,7 +3884,13 @@ func parseSelectedItemID(r *http.Request) int64 {
 		return 0
 	}
 
-	raw := strings.TrimSpace(r.FormValue("selected_item_id"))
+	return parseItemElementID(r.FormValue("selected_item_id"))
+}
+
+// parseItemElementID parses an item ID given as a number or as the item
+// card's element ID, such as "item-42". It returns 0 when raw is neither.
+func parseItemElementID(raw string) int64 {
+	raw = strings.TrimSpace(raw)
 	if raw == "" {
 		return 0
 	}
@@ -1373,6 +3974,73 @@ func parseFeedTitleUpdates(values url.Values) feedTitleUpdates {
 	return result
 }
 
+// parseFeedTagUpdates reads comma-separated tag inputs keyed by feed. Invalid
+// tags are dropped rather than failing the whole edit-mode save.
+func parseFeedTagUpdates(values url.Values) map[int64][]string {
+	result := make(map[int64][]string)
+
+	for key, rawValues := range values {
+		feedID, ok := parseFeedIDFromKey(key, "feed_tags_")
+		if !ok {
+			continue
+		}
+
+		tags := make([]string, 0)
+
+		for rawTag := range strings.SplitSeq(firstTrimmedValue(rawValues), ",") {
+			tag, valid := store.NormalizeTag(rawTag)
+			if valid && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		result[feedID] = tags
+	}
+
+	return result
+}
+
+// parseFeedColorUpdates reads the feed_color_<id> fields, skipping colors
+// outside the palette so a tampered value leaves the feed's color unchanged.
+func parseFeedColorUpdates(values url.Values) map[int64]string {
+	result := make(map[int64]string)
+
+	for key, rawValues := range values {
+		feedID, ok := parseFeedIDFromKey(key, "feed_color_")
+		if !ok {
+			continue
+		}
+
+		color, valid := store.NormalizeFeedColor(firstTrimmedValue(rawValues))
+		if valid {
+			result[feedID] = color
+		}
+	}
+
+	return result
+}
+
+// parseFeedTimezoneUpdates reads the feed_timezone_<id> fields, skipping
+// names the time zone database does not know so a typo leaves the feed's zone
+// unchanged.
+func parseFeedTimezoneUpdates(values url.Values) map[int64]string {
+	result := make(map[int64]string)
+
+	for key, rawValues := range values {
+		feedID, ok := parseFeedIDFromKey(key, "feed_timezone_")
+		if !ok {
+			continue
+		}
+
+		timezone, valid := store.NormalizeFeedTimezone(firstTrimmedValue(rawValues))
+		if valid {
+			result[feedID] = timezone
+		}
+	}
+
+	return result
+}
+
 func parseFeedIDFromKey(key, prefix string) (int64, bool) {
 	rawID, ok := strings.CutPrefix(key, prefix)
 	if !ok {
@@ -1433,7 +4101,7 @@ func (a *App) cleanupLoop() {
 }
 
 func (a *App) runCleanupIteration() {
-	err := store.CleanupReadItems(a.db)
+	err := store.CleanupReadItems(a.db, a.activeItems.active(time.Now(), a.activeItemRetention))
 	if err != nil {
 		slog.Error("cleanup error", "err", err)
 	}
@@ -1446,6 +4114,25 @@ func (a *App) runCleanupIteration() {
 	}
 }
 
+func (a *App) maintenanceLoop() {
+	ticker := time.NewTicker(a.maintenanceInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		a.runMaintenanceIteration()
+	}
+}
+
+func (a *App) runMaintenanceIteration() {
+	a.imageProxyClient.CloseIdleConnections()
+	a.imageUpgradeClient.CloseIdleConnections()
+
+	err := store.Maintain(context.Background(), a.db)
+	if err != nil {
+		slog.Error("database maintenance error", "err", err)
+	}
+}
+
 func (a *App) refreshLoop() {
 	ticker := time.NewTicker(feed.RefreshLoopInterval)
 	defer ticker.Stop()
@@ -1460,6 +4147,52 @@ func (a *App) refreshLoop() {
 	}
 }
 
+// refreshFeed refreshes a feed, joining a refresh of it already in flight
+// rather than starting another. It returns how many new items were stored.
+func (a *App) refreshFeed(ctx context.Context, feedID int64) (int, error) {
+	return a.refreshes.do(ctx, feedID, func(refreshCtx context.Context) (int, error) {
+		return a.fetchAndStoreFeed(refreshCtx, feedID)
+	})
+}
+
+// fetchAndStoreFeed fetches a feed without holding refreshMu, so a slow
+// upstream never delays other refreshes, and then stores the result under
+// the lock.
+func (a *App) fetchAndStoreFeed(ctx context.Context, feedID int64) (int, error) {
+	fetched, err := feed.FetchForRefresh(ctx, a.db, feedID)
+	if err != nil {
+		return 0, fmt.Errorf("fetch feed %d: %w", feedID, err)
+	}
+
+	a.refreshMu.Lock()
+	defer a.refreshMu.Unlock()
+
+	_, err = feed.StoreRefresh(ctx, a.db, fetched)
+	if err != nil {
+		return 0, fmt.Errorf("store feed %d: %w", feedID, err)
+	}
+
+	a.itemNotifier.notify(feedID)
+	a.syncEnclosures()
+
+	return fetched.Inserted(), nil
+}
+
+// syncEnclosures downloads newly stored enclosures in the background, so a
+// long episode never holds up the refresh loop.
+func (a *App) syncEnclosures() {
+	if a.enclosureCache == nil {
+		return
+	}
+
+	go func() {
+		err := a.enclosureCache.Sync(context.Background(), a.db)
+		if err != nil {
+			slog.Error("enclosure cache sync error", "err", err)
+		}
+	}()
+}
+
 func (a *App) refreshDueFeeds() error {
 	ids, err := store.ListDueFeeds(a.db, time.Now().UTC(), feed.RefreshBatchSize)
 	if err != nil {
@@ -1471,10 +4204,7 @@ func (a *App) refreshDueFeeds() error {
 	}
 
 	for _, id := range ids {
-		a.refreshMu.Lock()
-		_, refreshErr := feed.Refresh(context.Background(), a.db, id)
-		a.refreshMu.Unlock()
-
+		_, refreshErr := a.refreshFeed(context.Background(), id)
 		if refreshErr != nil {
 			slog.Error("refresh feed error", "feed_id", id, "err", refreshErr)
 		}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"rss/internal/store"
+)
+
+const (
+	shareTokenBytes = 24
+	// sharedFeedItemLimit bounds how many of a feed's newest items its share
+	// page lists.
+	sharedFeedItemLimit = 50
+)
+
+// handleShareFeed gives a feed a new share token, so any link handed out
+// before stops working, and re-renders the feed with the new link.
+func (a *App) handleShareFeed(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	token, err := randomToken(shareTokenBytes)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to create share link")
+
+		return
+	}
+
+	err = store.SetFeedShareToken(r.Context(), a.db, feedID, token)
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to create share link")
+
+		return
+	}
+
+	a.renderItemListResponse(w, r, feedID)
+}
+
+// handleRevokeFeedShare stops sharing a feed.
+func (a *App) handleRevokeFeedShare(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := parsePathInt64(r, "feedID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	err := store.SetFeedShareToken(r.Context(), a.db, feedID, "")
+	if err != nil {
+		a.renderError(w, r, http.StatusInternalServerError, "failed to revoke share link")
+
+		return
+	}
+
+	a.renderItemListResponse(w, r, feedID)
+}
+
+// handleSharedFeed renders the read-only page for a shared feed. It is open
+// without a session, so it is rate limited per client IP and shows only the
+// feed's title and newest items: no read state, counts, or other feeds.
+func (a *App) handleSharedFeed(w http.ResponseWriter, r *http.Request) {
+	if !a.shareRateLimiter.allow(requestRealIP(r), time.Now().UTC()) {
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+
+		return
+	}
+
+	feedID, err := store.GetFeedIDByShareToken(r.Context(), a.db, r.PathValue("token"))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if err != nil {
+		http.Error(w, "failed to load shared feed", http.StatusInternalServerError)
+
+		return
+	}
+
+	sharedFeed, err := store.GetFeed(r.Context(), a.db, feedID)
+	if err != nil {
+		http.Error(w, "failed to load shared feed", http.StatusInternalServerError)
+
+		return
+	}
+
+	items, err := store.ListRecentItems(r.Context(), a.db, feedID, sharedFeedItemLimit)
+	if err != nil {
+		http.Error(w, "failed to load shared feed", http.StatusInternalServerError)
+
+		return
+	}
+
+	slog.Info("shared feed viewed", "feed_id", feedID)
+
+	a.renderTemplate(w, "shared_feed", sharedFeedPageData{
+		Title:    sharedFeed.Title,
+		SiteURL:  sharedFeed.SiteURL,
+		Language: sharedFeed.Language,
+		Items:    items,
+	})
+}
@@ -3,11 +3,18 @@ package server
 import "rss/internal/view"
 
 type pageData struct {
-	ItemList       *view.ItemListData
-	CSRFToken      string
-	Feeds          []view.FeedView
-	SelectedFeedID int64
-	FeedEditMode   bool
+	ItemList        *view.ItemListData
+	Shortcuts       map[string]string
+	CSRFToken       string
+	Notice          string
+	FeedOrder       string
+	AccentColor     string
+	CSPNonce        string
+	Feeds           []view.FeedView
+	SelectedFeedID  int64
+	UnreadTotal     int
+	FeedEditMode    bool
+	MarkReadOnLeave bool
 }
 
 type subscribeResponseData struct {
@@ -20,22 +27,112 @@ type subscribeResponseData struct {
 	FeedEditMode   bool
 }
 
+// markReadConfirmData is the prompt shown before marking a feed's items read.
+// SkipConfirm submits it without asking.
+type markReadConfirmData struct {
+	FeedID      int64
+	Count       int
+	SkipConfirm bool
+}
+
+// errorPageData describes a failed request for the error partial and page.
+type errorPageData struct {
+	Message     string
+	RequestID   string
+	StatusText  string
+	Status      int
+	ServerError bool
+}
+
+type feedPreviewData struct {
+	FeedURL     string
+	Title       string
+	Description string
+	SiteURL     string
+	ItemTitles  []string
+	ItemCount   int
+}
+
+type feedHealthPageData struct {
+	Feeds   []view.FeedHealthView
+	Columns []feedHealthColumn
+}
+
+// feedHealthColumn is one header of the feed health table. Columns without a
+// Key cannot be sorted.
+type feedHealthColumn struct {
+	Key    string
+	Label  string
+	Order  string
+	Active bool
+}
+
 type newItemsResponseData struct {
 	Items    []view.ItemView
 	NewestID int64
 	Banner   view.NewItemsData
 }
 
+type itemPageResponseData struct {
+	Items    []view.ItemView
+	NextPage view.ItemPageData
+}
+
 type pollResponseData struct {
 	RefreshDisplay string
 	Feeds          []view.FeedView
 	Banner         view.NewItemsData
 	SelectedFeedID int64
+	UnreadTotal    int
 	FeedEditMode   bool
 }
 
 type itemListResponseData struct {
 	ItemList       *view.ItemListData
+	RefreshResult  *refreshResultView
+	Feeds          []view.FeedView
+	SelectedFeedID int64
+	FeedEditMode   bool
+}
+
+// refreshResultView is the outcome of a manual refresh, shown as a toast.
+type refreshResultView struct {
+	Message string
+	Failed  bool
+}
+
+type tagItemListResponseData struct {
+	TagList        *view.TagItemListData
+	Feeds          []view.FeedView
+	SelectedFeedID int64
+	FeedEditMode   bool
+}
+
+type todayItemListResponseData struct {
+	Today          *view.TodayItemListData
+	Feeds          []view.FeedView
+	SelectedFeedID int64
+	FeedEditMode   bool
+}
+
+// sharedFeedPageData is everything the public share page shows of a feed.
+type sharedFeedPageData struct {
+	Title    string
+	SiteURL  string
+	Language string
+	Items    []view.ItemView
+}
+
+// kioskPageData is the kiosk page: the newest unread headlines across feeds,
+// each shown with its feed's title.
+type kioskPageData struct {
+	Items          []view.ItemView
+	FeedTitles     map[int64]string
+	RefreshSeconds int
+}
+
+type discoverResponseData struct {
+	Topics         []recommendedTopic
 	Feeds          []view.FeedView
 	SelectedFeedID int64
 	FeedEditMode   bool
@@ -49,6 +146,14 @@ type toggleReadResponseData struct {
 	FeedEditMode   bool
 }
 
+type readNextResponseData struct {
+	Next           *view.ItemView
+	Feeds          []view.FeedView
+	Item           view.ItemView
+	SelectedFeedID int64
+	FeedEditMode   bool
+}
+
 type authLoginPageData struct {
 	Message string
 }
@@ -68,10 +173,20 @@ type authSecurityPageData struct {
 	RegistrationURL    string
 	RecoveryEnabledURL string
 	Message            string
+	Sessions           []authSessionView
 	PasskeyCount       int
 	HasRecoveryCode    bool
 }
 
+type authSessionView struct {
+	ID        string
+	SignedIn  string
+	LastSeen  string
+	IP        string
+	UserAgent string
+	Current   bool
+}
+
 type authRecoveryPageData struct {
 	Message string
 }
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"rss/internal/store"
+	"rss/internal/translate"
+	"rss/internal/view"
+)
+
+// handleTranslateItem renders an expanded item translated into the language
+// named by ?to=. The first request for a language calls the configured
+// endpoint and caches the result on the item; later ones reuse it until the
+// feed edits the item.
+func (a *App) handleTranslateItem(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := parsePathInt64(r, "itemID")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if a.translator == nil {
+		a.renderError(w, r, http.StatusNotFound, "translation is not configured")
+
+		return
+	}
+
+	lang := strings.TrimSpace(r.URL.Query().Get("to"))
+	if !translate.ValidLanguage(lang) {
+		a.renderError(w, r, http.StatusBadRequest, "unknown target language")
+
+		return
+	}
+
+	item, err := store.GetTranslatedItem(r.Context(), a.db, itemID, lang)
+	if err != nil {
+		a.renderError(w, r, http.StatusNotFound, "item not found")
+
+		return
+	}
+
+	if !item.Translated {
+		item, err = a.translateItem(r.Context(), itemID, lang)
+		if err != nil {
+			slog.Warn("translate item failed", "item_id", itemID, "lang", lang, "err", err)
+			a.renderError(w, r, http.StatusBadGateway, "translation failed; try again later")
+
+			return
+		}
+	}
+
+	item.IsActive = parseSelectedItemID(r) == item.ID
+	a.renderTemplate(w, "item_expanded", item)
+}
+
+// translateItem sends the item's text to the translator, caches the result,
+// and returns the item as translated.
+func (a *App) translateItem(ctx context.Context, itemID int64, lang string) (view.ItemView, error) {
+	title, body, err := store.GetItemTranslationSource(ctx, a.db, itemID)
+	if err != nil {
+		return view.ItemView{}, fmt.Errorf("load item %d text: %w", itemID, err)
+	}
+
+	translatedTitle, translatedBody, err := a.translator.Translate(ctx, lang, title, body)
+	if err != nil {
+		return view.ItemView{}, fmt.Errorf("translate item %d: %w", itemID, err)
+	}
+
+	err = store.SaveItemTranslation(ctx, a.db, itemID, lang, translatedTitle, translatedBody)
+	if err != nil {
+		return view.ItemView{}, fmt.Errorf("cache item %d translation: %w", itemID, err)
+	}
+
+	item, err := store.GetTranslatedItem(ctx, a.db, itemID, lang)
+	if err != nil {
+		return view.ItemView{}, fmt.Errorf("load translated item %d: %w", itemID, err)
+	}
+
+	return item, nil
+}
@@ -33,7 +33,8 @@ type AuthCredentialRecord struct {
 	BackupState    sql.NullBool
 }
 
-// AuthSessionRecord stores an authenticated browser session.
+// AuthSessionRecord stores an authenticated browser session. IP and
+// UserAgent describe the client that signed in, for the session list.
 type AuthSessionRecord struct {
 	CreatedAt        time.Time
 	ExpiresAt        time.Time
@@ -41,6 +42,8 @@ type AuthSessionRecord struct {
 	RevokedAt        sql.NullTime
 	SessionID        string
 	CSRFToken        string
+	IP               string
+	UserAgent        string
 	SessionTokenHash []byte
 	UserID           int64
 }
@@ -60,6 +63,7 @@ type AuthChallengeRecord struct {
 var (
 	ErrAuthChallengeMissing            = errors.New("auth challenge not found")
 	errUnsupportedAuthCredentialColumn = errors.New("unsupported auth credential column")
+	errUnsupportedAuthSessionColumn    = errors.New("unsupported auth session column")
 	errInvalidAuthCredentialSignCount  = errors.New("invalid auth credential sign count")
 )
 
@@ -96,6 +100,8 @@ CREATE TABLE IF NOT EXISTS auth_sessions (
 	expires_at DATETIME NOT NULL,
 	last_seen_at DATETIME NOT NULL,
 	revoked_at DATETIME,
+	ip TEXT,
+	user_agent TEXT,
 	FOREIGN KEY(user_id) REFERENCES auth_users(id) ON DELETE CASCADE
 );
 
@@ -140,6 +146,13 @@ func ensureAuthSchema(db *sql.DB) error {
 		return err
 	}
 
+	for _, column := range []string{"ip", "user_agent"} {
+		err = ensureAuthSessionColumn(db, column)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -183,6 +196,41 @@ func authCredentialAlterColumnStatement(column string) (string, error) {
 	}
 }
 
+func ensureAuthSessionColumn(db *sql.DB, column string) error {
+	var count int
+
+	err := db.QueryRowContext(
+		context.Background(),
+		`SELECT COUNT(*) FROM pragma_table_info('auth_sessions') WHERE name = ?`,
+		column,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check auth session column %q: %w", column, err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	var statement string
+
+	switch column {
+	case "ip":
+		statement = "ALTER TABLE auth_sessions ADD COLUMN ip TEXT"
+	case "user_agent":
+		statement = "ALTER TABLE auth_sessions ADD COLUMN user_agent TEXT"
+	default:
+		return fmt.Errorf("%w %q", errUnsupportedAuthSessionColumn, column)
+	}
+
+	_, err = db.ExecContext(context.Background(), statement)
+	if err != nil {
+		return fmt.Errorf("add auth session column %q: %w", column, err)
+	}
+
+	return nil
+}
+
 // AuthCredentialCount returns the number of registered credentials.
 func AuthCredentialCount(ctx context.Context, db *sql.DB) (int, error) {
 	ctx = contextOrBackground(ctx)
@@ -505,8 +553,8 @@ func CreateAuthSession(ctx context.Context, db *sql.DB, session *AuthSessionReco
 
 	_, err := db.ExecContext(ctx, `
 INSERT INTO auth_sessions
-(session_id, session_token_hash, csrf_token, user_id, created_at, expires_at, last_seen_at, revoked_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+(session_id, session_token_hash, csrf_token, user_id, created_at, expires_at, last_seen_at, revoked_at, ip, user_agent)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		session.SessionID,
 		session.SessionTokenHash,
@@ -516,6 +564,8 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		session.ExpiresAt,
 		session.LastSeenAt,
 		nullTimeToValue(session.RevokedAt),
+		nullString(session.IP),
+		nullString(session.UserAgent),
 	)
 	if err != nil {
 		return fmt.Errorf("create auth session: %w", err)
@@ -551,6 +601,82 @@ WHERE session_id = ?
 	return session, nil
 }
 
+// ListAuthSessions returns the user's sessions that are neither revoked nor
+// expired at now, most recently seen first. Token hashes and CSRF tokens are
+// left empty.
+func ListAuthSessions(ctx context.Context, db *sql.DB, userID int64, now time.Time) ([]AuthSessionRecord, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT session_id, user_id, created_at, expires_at, last_seen_at, ip, user_agent
+FROM auth_sessions
+WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+ORDER BY last_seen_at DESC, created_at DESC
+	`, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("list auth sessions for user %d: %w", userID, err)
+	}
+	defer closeRows(rows)
+
+	var sessions []AuthSessionRecord
+
+	for rows.Next() {
+		var (
+			session   AuthSessionRecord
+			ip        sql.NullString
+			userAgent sql.NullString
+		)
+
+		err = rows.Scan(
+			&session.SessionID,
+			&session.UserID,
+			&session.CreatedAt,
+			&session.ExpiresAt,
+			&session.LastSeenAt,
+			&ip,
+			&userAgent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan auth session: %w", err)
+		}
+
+		session.IP = ip.String
+		session.UserAgent = userAgent.String
+		sessions = append(sessions, session)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate auth sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeUserAuthSession revokes one of the user's sessions, reporting false
+// when the user has no active session with that ID.
+func RevokeUserAuthSession(ctx context.Context, db *sql.DB, userID int64, sessionID string) (bool, error) {
+	ctx = contextOrBackground(ctx)
+
+	result, err := db.ExecContext(
+		ctx,
+		`UPDATE auth_sessions SET revoked_at = ? WHERE session_id = ? AND user_id = ? AND revoked_at IS NULL`,
+		time.Now().UTC(),
+		sessionID,
+		userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("revoke auth session %q: %w", sessionID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("count revoked auth session %q: %w", sessionID, err)
+	}
+
+	return affected > 0, nil
+}
+
 // TouchAuthSession updates rolling session activity timestamps.
 func TouchAuthSession(ctx context.Context, db *sql.DB, sessionID string, lastSeenAt, expiresAt time.Time) error {
 	ctx = contextOrBackground(ctx)
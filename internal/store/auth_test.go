@@ -75,6 +75,60 @@ func TestAuthSessionLifecycle(t *testing.T) {
 	assertSessionRevoked(t, &revoked)
 }
 
+func TestListAuthSessionsSkipsRevokedAndExpired(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+
+	owner := mustCreateAuthOwner(t, db)
+	now := time.Now().UTC()
+
+	for index, sessionID := range []string{"active", "revoked", "expired"} {
+		session := AuthSessionRecord{
+			RevokedAt:        sql.NullTime{Time: time.Time{}, Valid: false},
+			SessionID:        sessionID,
+			SessionTokenHash: []byte(sessionID),
+			CSRFToken:        "csrf",
+			IP:               "192.0.2.1",
+			UserAgent:        "agent-" + sessionID,
+			UserID:           owner.ID,
+			CreatedAt:        now,
+			ExpiresAt:        now.Add(time.Hour),
+			LastSeenAt:       now.Add(time.Duration(index) * time.Minute),
+		}
+		if sessionID == "expired" {
+			session.ExpiresAt = now.Add(-time.Minute)
+		}
+
+		mustCreateAuthSession(t, db, &session)
+	}
+
+	mustRevokeAuthSession(t, db, "revoked")
+
+	sessions, err := ListAuthSessions(context.Background(), db, owner.ID, now)
+	if err != nil {
+		t.Fatalf("ListAuthSessions: %v", err)
+	}
+
+	if len(sessions) != 1 || sessions[0].SessionID != "active" {
+		t.Fatalf("expected only the active session, got %+v", sessions)
+	}
+
+	if sessions[0].IP != "192.0.2.1" || sessions[0].UserAgent != "agent-active" {
+		t.Fatalf("expected client details to round-trip, got %+v", sessions[0])
+	}
+
+	revoked, err := RevokeUserAuthSession(context.Background(), db, owner.ID+1, "active")
+	if err != nil || revoked {
+		t.Fatalf("expected another user's revoke to be a no-op, got %v, %v", revoked, err)
+	}
+
+	revoked, err = RevokeUserAuthSession(context.Background(), db, owner.ID, "active")
+	if err != nil || !revoked {
+		t.Fatalf("expected owner revoke to succeed, got %v, %v", revoked, err)
+	}
+}
+
 func mustCreateAuthOwner(t *testing.T, db *sql.DB) AuthUserRecord {
 	t.Helper()
 
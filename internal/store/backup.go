@@ -0,0 +1,287 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"rss/internal/backup"
+)
+
+// ListBackupFeeds returns every feed with the settings a backup restores, in
+// sidebar order so a restore into an empty database keeps that order.
+func ListBackupFeeds(ctx context.Context, db *sql.DB) ([]backup.Feed, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT f.url, f.title, f.custom_title, f.description, f.site_url, f.language,
+       f.user_agent, f.http_proxy, f.https_only, f.suppress_duplicate_titles, f.summarize_in_list,
+       f.strip_leading_image, f.images_only, f.image_referrer, f.ingest_token, f.color, f.timezone,
+       f.cache_enclosures_at IS NOT NULL, f.skip_tombstones, f.secret_url, f.stable_guids, f.digest_items,
+       f.archived_at IS NOT NULL, f.min_age_minutes, f.sort_by_updated,
+       `+feedTagsColumn+`
+FROM feeds f
+ORDER BY `+manualFeedOrderBy)
+	if err != nil {
+		return nil, fmt.Errorf("query backup feeds: %w", err)
+	}
+	defer closeRows(rows)
+
+	var feeds []backup.Feed
+
+	for rows.Next() {
+		var (
+			entry       backup.Feed
+			customTitle sql.NullString
+			description sql.NullString
+			siteURL     sql.NullString
+			language    sql.NullString
+			userAgent   sql.NullString
+			httpProxy   sql.NullString
+			referrer    sql.NullString
+			ingestToken sql.NullString
+			color       sql.NullString
+			timezone    sql.NullString
+			tags        sql.NullString
+		)
+
+		err = rows.Scan(
+			&entry.URL,
+			&entry.Title,
+			&customTitle,
+			&description,
+			&siteURL,
+			&language,
+			&userAgent,
+			&httpProxy,
+			&entry.HTTPSOnly,
+			&entry.SuppressDuplicateTitles,
+			&entry.SummarizeInList,
+			&entry.StripLeadingImage,
+			&entry.ImagesOnly,
+			&referrer,
+			&ingestToken,
+			&color,
+			&timezone,
+			&entry.CacheEnclosures,
+			&entry.SkipTombstones,
+			&entry.SecretURL,
+			&entry.StableGUIDs,
+			&entry.DigestItems,
+			&entry.Archived,
+			&entry.MinAgeMinutes,
+			&entry.SortByUpdated,
+			&tags,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan backup feed: %w", err)
+		}
+
+		entry.CustomTitle = customTitle.String
+		entry.Description = description.String
+		entry.SiteURL = siteURL.String
+		entry.Language = language.String
+		entry.UserAgent = userAgent.String
+		entry.HTTPProxy = httpProxy.String
+		entry.ImageReferrer = referrer.String
+		entry.IngestToken = ingestToken.String
+		entry.Color = color.String
+		entry.Timezone = timezone.String
+		entry.Tags = splitFeedTags(tags)
+		feeds = append(feeds, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate backup feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// ListBackupItems returns up to limit items with IDs after afterID, in ID
+// order, along with the last ID returned so the caller can fetch the next
+// page. Paging keeps the single connection free between pages while a large
+// backup streams to a slow client.
+func ListBackupItems(ctx context.Context, db *sql.DB, afterID int64, limit int) ([]backup.Item, int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT i.id, f.url, i.guid, i.title, i.link, i.summary, i.content, i.published_at, i.updated_at, i.read_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.id > ?
+ORDER BY i.id
+LIMIT ?`, afterID, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query backup items: %w", err)
+	}
+	defer closeRows(rows)
+
+	var items []backup.Item
+
+	lastID := afterID
+
+	for rows.Next() {
+		var (
+			entry       backup.Item
+			summary     sql.NullString
+			body        sql.NullString
+			publishedAt sql.NullTime
+			updatedAt   sql.NullTime
+			readAt      sql.NullTime
+		)
+
+		err = rows.Scan(
+			&lastID,
+			&entry.FeedURL,
+			&entry.GUID,
+			&entry.Title,
+			&entry.Link,
+			&summary,
+			&body,
+			&publishedAt,
+			&updatedAt,
+			&readAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan backup item: %w", err)
+		}
+
+		entry.Summary = summary.String
+		entry.Content = body.String
+		entry.PublishedAt = nullTimePointer(publishedAt)
+		entry.UpdatedAt = nullTimePointer(updatedAt)
+		entry.ReadAt = nullTimePointer(readAt)
+		items = append(items, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, 0, fmt.Errorf("iterate backup items: %w", err)
+	}
+
+	return items, lastID, nil
+}
+
+// RestoreBackupFeed subscribes to a feed from a backup, or updates the
+// existing subscription with the same URL, and reapplies its title, settings,
+// tags, color, time zone, newsletter token, and archived state. It returns the
+// feed's ID.
+func RestoreBackupFeed(ctx context.Context, db *sql.DB, entry *backup.Feed) (int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	feedID, err := UpsertFeed(ctx, db, entry.URL, fallbackString(entry.Title, entry.URL))
+	if err != nil {
+		return 0, err
+	}
+
+	// An unknown color or time zone from a hand-edited document is dropped
+	// rather than failing the restore.
+	color, _ := NormalizeFeedColor(entry.Color)
+	timezone, _ := NormalizeFeedTimezone(entry.Timezone)
+
+	_, err = db.ExecContext(ctx, `
+UPDATE feeds
+SET custom_title = ?, description = ?, site_url = ?, language = ?, ingest_token = ?, color = ?,
+    timezone = ?
+WHERE id = ?`,
+		nullString(entry.CustomTitle),
+		nullString(entry.Description),
+		nullString(entry.SiteURL),
+		nullString(entry.Language),
+		nullString(entry.IngestToken),
+		nullString(color),
+		nullString(timezone),
+		feedID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("restore feed %d: %w", feedID, err)
+	}
+
+	err = UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{
+		UserAgent:               entry.UserAgent,
+		HTTPProxy:               entry.HTTPProxy,
+		HTTPSOnly:               entry.HTTPSOnly,
+		SuppressDuplicateTitles: entry.SuppressDuplicateTitles,
+		SummarizeInList:         entry.SummarizeInList,
+		StripLeadingImage:       entry.StripLeadingImage,
+		ImagesOnly:              entry.ImagesOnly,
+		ImageReferrer:           entry.ImageReferrer,
+		CacheEnclosures:         entry.CacheEnclosures,
+		SkipTombstones:          entry.SkipTombstones,
+		SecretURL:               entry.SecretURL,
+		StableGUIDs:             entry.StableGUIDs,
+		DigestItems:             entry.DigestItems,
+		MinAgeMinutes:           entry.MinAgeMinutes,
+		SortByUpdated:           entry.SortByUpdated,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, tag := range entry.Tags {
+		err = AddFeedTag(ctx, db, feedID, tag)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if entry.Archived {
+		err = ArchiveFeed(ctx, db, feedID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return feedID, nil
+}
+
+// RestoreBackupItem inserts a backed-up item into feedID, or, when the feed
+// already has an item with the same GUID, keeps its stored content and only
+// restores the read state.
+func RestoreBackupItem(ctx context.Context, db *sql.DB, feedID int64, entry *backup.Item) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx, `
+INSERT INTO items
+(feed_id, guid, title, link, summary, content, published_at, updated_at, read_at, created_at, has_image)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(feed_id, guid) DO UPDATE SET read_at = excluded.read_at`,
+		feedID,
+		entry.GUID,
+		entry.Title,
+		entry.Link,
+		nullString(entry.Summary),
+		nullString(entry.Content),
+		timePointerValue(entry.PublishedAt),
+		timePointerValue(entry.UpdatedAt),
+		timePointerValue(entry.ReadAt),
+		time.Now().UTC(),
+		itemHasImage(entry.Summary, entry.Content),
+	)
+	if err != nil {
+		return fmt.Errorf("restore item %q in feed %d: %w", entry.GUID, feedID, err)
+	}
+
+	return nil
+}
+
+func nullTimePointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	utc := value.Time.UTC()
+
+	return &utc
+}
+
+func timePointerValue(value *time.Time) any {
+	if value == nil {
+		return nil
+	}
+
+	return value.UTC()
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+)
+
+// ConsistencyReport counts the rows VerifyConsistency found out of place and
+// removed. Unread counts are not listed: they are computed from items on every
+// read, so they follow once the items are sound.
+type ConsistencyReport struct {
+	OrphanItems        int64
+	OrphanTombstones   int64
+	ShadowedTombstones int64
+	OrphanTags         int64
+	OrphanSeenTitles   int64
+}
+
+// Total returns how many rows the report covers.
+func (r ConsistencyReport) Total() int64 {
+	return r.OrphanItems + r.OrphanTombstones + r.ShadowedTombstones + r.OrphanTags + r.OrphanSeenTitles
+}
+
+// VerifyConsistency removes rows that reference feeds which no longer exist,
+// which foreign keys prevent today but databases written without them may
+// hold, and tombstones that contradict a live item with the same GUID. All
+// repairs run in one transaction.
+func VerifyConsistency(ctx context.Context, db *sql.DB) (ConsistencyReport, error) {
+	ctx = contextOrBackground(ctx)
+
+	var report ConsistencyReport
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return report, fmt.Errorf("begin consistency transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			rollbackTx(tx)
+		}
+	}()
+
+	repairs := []struct {
+		count *int64
+		name  string
+		query string
+	}{
+		{&report.OrphanItems, "orphan items", `
+DELETE FROM items
+WHERE feed_id NOT IN (SELECT id FROM feeds)`},
+		{&report.OrphanTombstones, "orphan tombstones", `
+DELETE FROM tombstones
+WHERE feed_id NOT IN (SELECT id FROM feeds)`},
+		{&report.ShadowedTombstones, "shadowed tombstones", `
+DELETE FROM tombstones
+WHERE EXISTS (SELECT 1 FROM items i WHERE i.feed_id = tombstones.feed_id AND i.guid = tombstones.guid)`},
+		{&report.OrphanTags, "orphan feed tags", `
+DELETE FROM feed_tags
+WHERE feed_id NOT IN (SELECT id FROM feeds)`},
+		{&report.OrphanSeenTitles, "orphan seen titles", `
+DELETE FROM feed_seen_titles
+WHERE feed_id NOT IN (SELECT id FROM feeds)`},
+	}
+
+	for _, repair := range repairs {
+		var result sql.Result
+
+		result, err = tx.ExecContext(ctx, repair.query)
+		if err != nil {
+			return report, fmt.Errorf("remove %s: %w", repair.name, err)
+		}
+
+		*repair.count, err = result.RowsAffected()
+		if err != nil {
+			return report, fmt.Errorf("count %s: %w", repair.name, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return report, fmt.Errorf("commit consistency transaction: %w", err)
+	}
+
+	slog.Info("db verify consistency", "repaired", report.Total())
+
+	return report, nil
+}
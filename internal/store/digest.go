@@ -0,0 +1,246 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// digestGUIDPrefix marks the synthetic items combineDigestItems writes, one
+// per day and keyed by the date.
+const digestGUIDPrefix = "digest:"
+
+// digestEntry is an item folded into a digest.
+type digestEntry struct {
+	id      int64
+	title   string
+	link    string
+	summary string
+	body    string
+	at      time.Time
+	image   bool
+}
+
+// combineDigestItems folds the unread items inserted at now into one digest
+// item per day, for feeds with DigestItems set. The folded items are dismissed
+// rather than deleted, so later refreshes still recognize them and cleanup
+// tombstones them like any other dismissed item. Turning the setting off
+// leaves existing digests alone and lets new items through individually.
+func combineDigestItems(ctx context.Context, db *sql.DB, feedID int64, now time.Time, location *time.Location) error {
+	var enabled bool
+
+	err := db.QueryRowContext(ctx, "SELECT digest_items FROM feeds WHERE id = ?", feedID).Scan(&enabled)
+	if err != nil {
+		return fmt.Errorf("load digest setting for feed %d: %w", feedID, err)
+	}
+
+	if !enabled {
+		return nil
+	}
+
+	entries, err := listDigestEntries(ctx, db, feedID, now)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	var days []string
+
+	byDay := make(map[string][]digestEntry)
+
+	for _, entry := range entries {
+		day := entry.at.In(location).Format(time.DateOnly)
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+
+		byDay[day] = append(byDay[day], entry)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin digest transaction: %w", err)
+	}
+
+	for _, day := range days {
+		err = foldIntoDigest(ctx, tx, feedID, day, byDay[day], now)
+		if err != nil {
+			rollbackTx(tx)
+
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit digest transaction: %w", err)
+	}
+
+	return nil
+}
+
+// listDigestEntries returns the feed's unread items inserted at now, oldest
+// first, leaving out digests themselves.
+func listDigestEntries(ctx context.Context, db *sql.DB, feedID int64, now time.Time) ([]digestEntry, error) {
+	rows, err := db.QueryContext(ctx, `
+SELECT id, title, link, COALESCE(summary, ''), COALESCE(content, ''), published_at, created_at, has_image
+FROM items
+WHERE feed_id = ? AND created_at = ? AND read_at IS NULL AND dismissed_at IS NULL AND guid NOT LIKE ?
+ORDER BY COALESCE(published_at, created_at), id
+	`, feedID, now, digestGUIDPrefix+"%")
+	if err != nil {
+		return nil, fmt.Errorf("query digest entries for feed %d: %w", feedID, err)
+	}
+	defer closeRows(rows)
+
+	var entries []digestEntry
+
+	for rows.Next() {
+		var (
+			entry     digestEntry
+			published sql.NullTime
+		)
+
+		err = rows.Scan(
+			&entry.id, &entry.title, &entry.link, &entry.summary, &entry.body, &published, &entry.at, &entry.image,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan digest entry: %w", err)
+		}
+
+		if published.Valid {
+			entry.at = published.Time
+		}
+
+		entries = append(entries, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate digest entries for feed %d: %w", feedID, err)
+	}
+
+	return entries, nil
+}
+
+// foldIntoDigest appends entries to the day's digest item, creating it on the
+// day's first entries and marking it unread again otherwise, then dismisses
+// the entries.
+func foldIntoDigest(
+	ctx context.Context,
+	tx *sql.Tx,
+	feedID int64,
+	day string,
+	entries []digestEntry,
+	now time.Time,
+) error {
+	guid := digestGUIDPrefix + day
+	sections, titles, latest, image := digestSections(entries)
+
+	var (
+		existingID      int64
+		existingSummary string
+		existingBody    string
+		existingAt      sql.NullTime
+	)
+
+	err := tx.QueryRowContext(ctx, `
+SELECT id, COALESCE(summary, ''), COALESCE(content, ''), published_at
+FROM items
+WHERE feed_id = ? AND guid = ?
+	`, feedID, guid).Scan(&existingID, &existingSummary, &existingBody, &existingAt)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO items (feed_id, guid, title, link, summary, content, published_at, created_at, has_image)
+VALUES (?, ?, ?, '#', ?, ?, ?, ?, ?)
+		`, feedID, guid, digestTitle(day), titles, sections, latest, now, image)
+		if err != nil {
+			return fmt.Errorf("insert digest for feed %d on %s: %w", feedID, day, err)
+		}
+	case err != nil:
+		return fmt.Errorf("load digest for feed %d on %s: %w", feedID, day, err)
+	default:
+		_, err = tx.ExecContext(ctx, `
+UPDATE items
+SET summary = ?, content = ?, published_at = ?, has_image = has_image OR ?,
+    last_updated_at = ?, read_at = NULL, dismissed_at = NULL
+WHERE id = ?
+		`, joinDigestText(existingSummary, titles, "; "), joinDigestText(existingBody, sections, "\n"),
+			latestTime(existingAt.Time, latest), image, now, existingID)
+		if err != nil {
+			return fmt.Errorf("update digest for feed %d on %s: %w", feedID, day, err)
+		}
+	}
+
+	for _, entry := range entries {
+		_, err = tx.ExecContext(ctx, "UPDATE items SET dismissed_at = ? WHERE id = ?", now, entry.id)
+		if err != nil {
+			return fmt.Errorf("dismiss digest entry %d: %w", entry.id, err)
+		}
+	}
+
+	return nil
+}
+
+// digestSections renders entries as the digest's HTML sections and plain-text
+// title list, with the newest entry time and whether any entry has an image.
+func digestSections(entries []digestEntry) (string, string, time.Time, bool) {
+	var (
+		body   strings.Builder
+		titles []string
+		latest time.Time
+		image  bool
+	)
+
+	for _, entry := range entries {
+		title := html.EscapeString(entry.title)
+		if entry.link != "" && entry.link != "#" {
+			title = `<a href="` + html.EscapeString(entry.link) + `">` + title + `</a>`
+		}
+
+		body.WriteString("<section><h3>" + title + "</h3>\n")
+
+		text := entry.body
+		if text == "" {
+			text = entry.summary
+		}
+
+		body.WriteString(text + "\n</section>\n")
+
+		titles = append(titles, entry.title)
+		latest = latestTime(latest, entry.at)
+		image = image || entry.image
+	}
+
+	return strings.TrimSuffix(body.String(), "\n"), strings.Join(titles, "; "), latest, image
+}
+
+func digestTitle(day string) string {
+	date, err := time.Parse(time.DateOnly, day)
+	if err != nil {
+		return "Updates for " + day
+	}
+
+	return "Updates for " + date.Format("January 2, 2006")
+}
+
+func joinDigestText(existing, added, sep string) string {
+	if existing == "" {
+		return added
+	}
+
+	return existing + sep + added
+}
+
+func latestTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+
+	return a
+}
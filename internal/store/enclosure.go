@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Enclosure is an item's media attachment. Path names the cached copy inside
+// the enclosure cache directory; it is empty when the enclosure has not been
+// downloaded, or was downloaded and later evicted or failed to download.
+type Enclosure struct {
+	URL    string
+	Type   string
+	Path   string
+	ItemID int64
+}
+
+// CachedEnclosure is a downloaded enclosure, with when it was last downloaded
+// or played for least-recently-used eviction.
+type CachedEnclosure struct {
+	AccessedAt time.Time
+	Path       string
+	ItemID     int64
+}
+
+// itemEnclosure returns the URL and media type of the item's first http(s)
+// enclosure, or empty strings when it has none.
+func itemEnclosure(item *gofeed.Item) (string, string) {
+	for _, enclosure := range item.Enclosures {
+		if enclosure == nil {
+			continue
+		}
+
+		raw := strings.TrimSpace(enclosure.URL)
+
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			continue
+		}
+
+		return raw, strings.TrimSpace(enclosure.Type)
+	}
+
+	return "", ""
+}
+
+// ListPendingEnclosures returns up to limit enclosures, newest first, that
+// feeds caching enclosures have not tried to download yet. Only items stored
+// since the feed turned caching on are included, so enabling it does not pull
+// down the feed's whole back catalogue.
+func ListPendingEnclosures(ctx context.Context, db *sql.DB, limit int) ([]Enclosure, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT i.id, i.enclosure_url, i.enclosure_type
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE f.cache_enclosures_at IS NOT NULL
+  AND i.created_at >= f.cache_enclosures_at
+  AND i.enclosure_url IS NOT NULL
+  AND i.enclosure_path IS NULL
+ORDER BY i.created_at DESC, i.id DESC
+LIMIT ?
+`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query pending enclosures: %w", err)
+	}
+	defer closeRows(rows)
+
+	var enclosures []Enclosure
+
+	for rows.Next() {
+		var (
+			enclosure Enclosure
+			mediaType sql.NullString
+		)
+
+		err = rows.Scan(&enclosure.ItemID, &enclosure.URL, &mediaType)
+		if err != nil {
+			return nil, fmt.Errorf("scan pending enclosure: %w", err)
+		}
+
+		enclosure.Type = mediaType.String
+		enclosures = append(enclosures, enclosure)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate pending enclosures: %w", err)
+	}
+
+	return enclosures, nil
+}
+
+// GetItemEnclosure returns the item's enclosure, or sql.ErrNoRows when the
+// item does not exist or has no enclosure.
+func GetItemEnclosure(ctx context.Context, db *sql.DB, itemID int64) (Enclosure, error) {
+	ctx = contextOrBackground(ctx)
+
+	var (
+		enclosure Enclosure
+		mediaType sql.NullString
+		path      sql.NullString
+	)
+
+	err := db.QueryRowContext(ctx, `
+SELECT id, enclosure_url, enclosure_type, enclosure_path
+FROM items
+WHERE id = ? AND enclosure_url IS NOT NULL
+`, itemID).Scan(&enclosure.ItemID, &enclosure.URL, &mediaType, &path)
+	if err != nil {
+		return Enclosure{}, fmt.Errorf("get enclosure for item %d: %w", itemID, err)
+	}
+
+	enclosure.Type = mediaType.String
+	enclosure.Path = path.String
+
+	return enclosure, nil
+}
+
+// SetItemEnclosurePath records where the item's enclosure was cached. An
+// empty path records that it is not cached and should not be downloaded again.
+func SetItemEnclosurePath(ctx context.Context, db *sql.DB, itemID int64, path string, now time.Time) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE items SET enclosure_path = ?, enclosure_accessed_at = ? WHERE id = ?",
+		path, now, itemID,
+	)
+	if err != nil {
+		return fmt.Errorf("set enclosure path for item %d: %w", itemID, err)
+	}
+
+	return nil
+}
+
+// TouchItemEnclosure marks the item's cached enclosure as just played, moving
+// it to the back of the eviction order.
+func TouchItemEnclosure(ctx context.Context, db *sql.DB, itemID int64, now time.Time) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx, "UPDATE items SET enclosure_accessed_at = ? WHERE id = ?", now, itemID)
+	if err != nil {
+		return fmt.Errorf("touch enclosure for item %d: %w", itemID, err)
+	}
+
+	return nil
+}
+
+// ListCachedEnclosures returns every cached enclosure, least recently used
+// first.
+func ListCachedEnclosures(ctx context.Context, db *sql.DB) ([]CachedEnclosure, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT id, enclosure_path, enclosure_accessed_at
+FROM items
+WHERE enclosure_path <> ''
+ORDER BY enclosure_accessed_at ASC, id ASC
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query cached enclosures: %w", err)
+	}
+	defer closeRows(rows)
+
+	var cached []CachedEnclosure
+
+	for rows.Next() {
+		var (
+			entry      CachedEnclosure
+			accessedAt sql.NullTime
+		)
+
+		err = rows.Scan(&entry.ItemID, &entry.Path, &accessedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan cached enclosure: %w", err)
+		}
+
+		entry.AccessedAt = accessedAt.Time
+		cached = append(cached, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate cached enclosures: %w", err)
+	}
+
+	return cached, nil
+}
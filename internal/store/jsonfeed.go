@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"rss/internal/content"
+	"rss/internal/jsonfeed"
+)
+
+// ListJSONFeedItems returns at most limit of the feed's newest visible items,
+// in the order ListItems lists them, as JSON Feed items. Each item's body is
+// its content, or its summary when it has none, sanitized for readers that
+// render it as is.
+func ListJSONFeedItems(ctx context.Context, db *sql.DB, feedID int64, limit int) ([]jsonfeed.Item, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT i.guid, i.link, i.title, COALESCE(NULLIF(TRIM(i.content), ''), i.summary, ''), i.published_at, i.updated_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.feed_id = ? AND `+visibleItemFilter+`
+ORDER BY `+feedItemSortDate("i")+` DESC, i.id DESC
+LIMIT ?
+	`, feedID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query json feed items for feed %d: %w", feedID, err)
+	}
+	defer closeRows(rows)
+
+	var items []jsonfeed.Item
+
+	for rows.Next() {
+		var (
+			entry       jsonfeed.Item
+			body        string
+			publishedAt sql.NullTime
+			updatedAt   sql.NullTime
+		)
+
+		err = rows.Scan(&entry.ID, &entry.URL, &entry.Title, &body, &publishedAt, &updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan json feed item: %w", err)
+		}
+
+		entry.ContentHTML = content.SanitizeHTML(content.UnwrapXHTML(body))
+		entry.DatePublished = nullTimePointer(publishedAt)
+		entry.DateModified = nullTimePointer(updatedAt)
+		items = append(items, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate json feed items: %w", err)
+	}
+
+	return items, nil
+}
@@ -3,23 +3,140 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
 
 	_ "modernc.org/sqlite" // Register the sqlite database/sql driver.
 
+	"rss/internal/content"
 	"rss/internal/view"
 )
 
 const (
-	maxItemsPerFeed = 200
-	readRetention   = 30 * time.Minute
+	// MaxItemsPerFeed is how many items each feed keeps; fetches stop parsing
+	// once a feed has listed this many.
+	MaxItemsPerFeed = 200
+	// DefaultItemPageSize is how many items a feed's list shows before its
+	// "Load more" control.
+	DefaultItemPageSize   = 50
+	readRetention         = 30 * time.Minute
+	feedHistoryRetention  = 30 * 24 * time.Hour
+	incrementalVacuumStep = 256
+	// maxIncrementalVacuumSteps bounds how many incrementalVacuumStep batches
+	// one Maintain run frees; the next run picks up what is left.
+	maxIncrementalVacuumSteps = 16
+	maxTagLength              = 32
+	defaultCacheSizeKiB       = 16 << 10
+	defaultMmapSizeBytes      = 256 << 20
+	duplicateTitleWindow      = 7 * 24 * time.Hour
+	untitledItemTitle         = "(untitled)"
+	autoVacuumIncremental     = 2
+	// stableGUIDPrefix and stableGUIDBytes shape the GUIDs stableItemGUID
+	// derives: a marker and a hex-encoded truncated SHA-256.
+	stableGUIDPrefix = "title-date:"
+	stableGUIDBytes  = 16
+)
+
+const (
+	// itemRateWindow bounds the history averaged into a feed's items per day.
+	itemRateWindow = 30 * 24 * time.Hour
+	hoursPerDay    = 24
+	// fetchDurationWeight is how many fetches the rolling fetch duration
+	// average spans, roughly.
+	fetchDurationWeight = 4
+)
+
+var (
+	errUnsupportedFeedColumn = errors.New("unsupported feed column")
+	errUnsupportedItemColumn = errors.New("unsupported item column")
+
+	// ErrInvalidTag reports a tag that is empty or uses unsupported characters.
+	ErrInvalidTag = errors.New("invalid tag")
+	// ErrInvalidFeedColor reports a feed color NormalizeFeedColor rejects.
+	ErrInvalidFeedColor = errors.New("invalid feed color")
+	// ErrInvalidFeedTimezone reports a time zone NormalizeFeedTimezone rejects.
+	ErrInvalidFeedTimezone = errors.New("invalid feed time zone")
+
+	// ErrInvalidFeedOrder reports a sidebar ordering other than the FeedOrder values.
+	ErrInvalidFeedOrder = errors.New("invalid feed order")
+	// ErrFeedLimitReached reports a new subscription refused by the SetMaxFeeds cap.
+	ErrFeedLimitReached = errors.New("feed limit reached")
+)
+
+// maxFeeds is the cap set by SetMaxFeeds.
+var maxFeeds atomic.Int64
+
+// Sidebar orderings saved under the feed order user pref. FeedOrderManual,
+// the default, follows sort_order; FeedOrderRecentUnread floats the feeds with
+// the most recently published unread items to the top.
+const (
+	FeedOrderManual       = "manual"
+	FeedOrderRecentUnread = "recent_unread"
+	FeedOrderAdded        = "added"
+
+	feedOrderPrefKey  = "feed_order"
+	manualFeedOrderBy = `f.sort_order ASC, COALESCE(f.custom_title, f.title) COLLATE NOCASE, f.id ASC`
+	// Feeds without unread items sort as NULL, after every feed with some.
+	recentUnreadFeedOrderBy = `(
+    SELECT MAX(COALESCE(i.published_at, i.created_at))
+    FROM items i
+    WHERE i.feed_id = f.id AND i.read_at IS NULL AND i.dismissed_at IS NULL
+  ) DESC, ` + manualFeedOrderBy
+	addedFeedOrderBy = `f.created_at DESC, f.id DESC`
+)
+
+// itemOldEnoughFilter holds back items younger than their feed's minimum age,
+// so an edit or retraction soon after publishing lands before they are shown.
+// Stored times use Go's format, which datetime() only parses once the zone
+// suffix is cut; they are UTC.
+const itemOldEnoughFilter = `(f.min_age_minutes = 0 OR
+    datetime(substr(COALESCE(i.published_at, i.created_at), 1, 19))
+      <= datetime('now', '-' || f.min_age_minutes || ' minutes'))`
+
+// visibleItemFilter hides dismissed items, items younger than the feed's
+// minimum age, and items without images from feeds set to show only those.
+const visibleItemFilter = `(f.images_only = 0 OR i.has_image = 1) AND i.dismissed_at IS NULL AND ` +
+	itemOldEnoughFilter
+
+const (
+	feedTagsColumn = `(SELECT group_concat(t.tag, ',') FROM feed_tags t WHERE t.feed_id = f.id) AS tags`
+	// feedUnreadCountColumn counts nothing for archived feeds, keeping them
+	// out of unread badges and totals, and leaves out items still too new
+	// to show.
+	feedUnreadCountColumn = `(SELECT COUNT(*) FROM items i
+         WHERE i.feed_id = f.id AND f.archived_at IS NULL AND i.read_at IS NULL AND i.dismissed_at IS NULL
+           AND ` + itemOldEnoughFilter + `)
+         AS unread_count`
+	feedViewColumns = `f.id, COALESCE(f.custom_title, f.title) AS display_title, f.title, f.url,
+       (SELECT COUNT(*) FROM items i WHERE i.feed_id = f.id) AS item_count,
+       ` + feedUnreadCountColumn + `,
+       f.last_refreshed_at,
+       f.last_error,
+       f.last_error_at,
+       f.language,
+       f.created_at,
+       f.color,
+       f.secret_url,
+       f.timezone,
+       f.archived_at IS NOT NULL,
+       f.consecutive_errors,
+       f.last_error_code,
+       ` + feedTagsColumn
 )
 
 const initSchemaSQL = `
@@ -35,7 +152,36 @@ CREATE TABLE IF NOT EXISTS feeds (
 	last_refreshed_at DATETIME,
 	last_error TEXT,
 	unchanged_count INTEGER NOT NULL DEFAULT 0,
-	next_refresh_at DATETIME
+	next_refresh_at DATETIME,
+	description TEXT,
+	site_url TEXT,
+	user_agent TEXT,
+	http_proxy TEXT,
+	https_only INTEGER NOT NULL DEFAULT 0,
+	last_visited_at DATETIME,
+	suppress_duplicate_titles INTEGER NOT NULL DEFAULT 0,
+	summarize_in_list INTEGER NOT NULL DEFAULT 0,
+	language TEXT,
+	strip_leading_image INTEGER NOT NULL DEFAULT 0,
+	consecutive_errors INTEGER NOT NULL DEFAULT 0,
+	last_error_code INTEGER,
+	last_content_at DATETIME,
+	ingest_token TEXT,
+	last_error_at DATETIME,
+	images_only INTEGER NOT NULL DEFAULT 0,
+	image_referrer TEXT,
+	avg_fetch_ms INTEGER,
+	color TEXT,
+	cache_enclosures_at DATETIME,
+	skip_tombstones INTEGER NOT NULL DEFAULT 0,
+	secret_url INTEGER NOT NULL DEFAULT 0,
+	timezone TEXT,
+	share_token TEXT,
+	stable_guids INTEGER NOT NULL DEFAULT 0,
+	digest_items INTEGER NOT NULL DEFAULT 0,
+	archived_at DATETIME,
+	min_age_minutes INTEGER NOT NULL DEFAULT 0,
+	sort_by_updated INTEGER NOT NULL DEFAULT 0
 );
 
 CREATE TABLE IF NOT EXISTS items (
@@ -49,6 +195,20 @@ CREATE TABLE IF NOT EXISTS items (
 	published_at DATETIME,
 	read_at DATETIME,
 	created_at DATETIME NOT NULL,
+	last_updated_at DATETIME,
+	has_image INTEGER NOT NULL DEFAULT 0,
+	categories TEXT,
+	dismissed_at DATETIME,
+	enclosure_url TEXT,
+	enclosure_type TEXT,
+	enclosure_path TEXT,
+	enclosure_accessed_at DATETIME,
+	image_url TEXT,
+	translation_lang TEXT,
+	translated_title TEXT,
+	translated_content TEXT,
+	translated_at DATETIME,
+	updated_at DATETIME,
 	UNIQUE(feed_id, guid),
 	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
 );
@@ -65,13 +225,94 @@ CREATE TRIGGER IF NOT EXISTS tombstones_prune
 AFTER INSERT ON tombstones
 BEGIN
 	DELETE FROM tombstones
-	WHERE datetime(deleted_at) <= datetime('now', '-30 days');
+	WHERE datetime(substr(deleted_at, 1, 19)) <= datetime('now', '-30 days');
 END;
+
+CREATE TABLE IF NOT EXISTS feed_tags (
+	feed_id INTEGER NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (feed_id, tag),
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_feed_tags_tag ON feed_tags(tag);
+
+CREATE TABLE IF NOT EXISTS feed_seen_titles (
+	feed_id INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	seen_at DATETIME NOT NULL,
+	PRIMARY KEY (feed_id, title),
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS feed_history (
+	feed_url TEXT NOT NULL,
+	guid TEXT NOT NULL,
+	read_at DATETIME,
+	deleted_at DATETIME NOT NULL,
+	PRIMARY KEY (feed_url, guid)
+);
+
+CREATE TABLE IF NOT EXISTS user_prefs (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL,
+	updated_at DATETIME NOT NULL
+);
 `
 
-// Open is part of the store package API.
+// Pragmas tunes SQLite for the reader's read-heavy workload.
+type Pragmas struct {
+	// Synchronous is NORMAL or FULL. NORMAL is safe from corruption in WAL mode
+	// but may lose the last commits on power loss; FULL fsyncs every commit.
+	Synchronous string
+	// CacheSizeKiB bounds the page cache per connection. Larger caches keep
+	// hot item pages in memory at the cost of resident memory.
+	CacheSizeKiB int64
+	// MmapSizeBytes lets SQLite read pages through memory-mapped I/O, which
+	// avoids copies for large databases; zero disables mmap.
+	MmapSizeBytes int64
+}
+
+// DefaultPragmas returns the pragmas used by Open.
+func DefaultPragmas() Pragmas {
+	return Pragmas{
+		Synchronous:   "NORMAL",
+		CacheSizeKiB:  defaultCacheSizeKiB,
+		MmapSizeBytes: defaultMmapSizeBytes,
+	}
+}
+
+// Open opens the database with DefaultPragmas.
 func Open(path string) (*sql.DB, error) {
-	dsn := path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	return OpenWithPragmas(path, DefaultPragmas())
+}
+
+// OpenWithPragmas opens the database with tuned cache, mmap, and sync settings.
+// Invalid values fall back to their defaults.
+func OpenWithPragmas(path string, pragmas Pragmas) (*sql.DB, error) {
+	defaults := DefaultPragmas()
+
+	synchronous := strings.ToUpper(strings.TrimSpace(pragmas.Synchronous))
+	if synchronous != "NORMAL" && synchronous != "FULL" {
+		synchronous = defaults.Synchronous
+	}
+
+	cacheSizeKiB := pragmas.CacheSizeKiB
+	if cacheSizeKiB <= 0 {
+		cacheSizeKiB = defaults.CacheSizeKiB
+	}
+
+	mmapSize := max(pragmas.MmapSizeBytes, 0)
+
+	// A negative cache_size is measured in KiB rather than pages.
+	dsn := fmt.Sprintf(
+		"%s?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=auto_vacuum(2)"+
+			"&_pragma=synchronous(%s)&_pragma=cache_size(-%d)&_pragma=mmap_size(%d)",
+		path,
+		synchronous,
+		cacheSizeKiB,
+		mmapSize,
+	)
 
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
@@ -100,6 +341,105 @@ func Init(db *sql.DB) error {
 		return err
 	}
 
+	err = ensureItemLastUpdatedColumn(db)
+	if err != nil {
+		return err
+	}
+
+	err = ensureItemHasImageColumn(db)
+	if err != nil {
+		return err
+	}
+
+	err = ensureItemCategoriesColumn(db)
+	if err != nil {
+		return err
+	}
+
+	err = ensureItemDismissedAtColumn(db)
+	if err != nil {
+		return err
+	}
+
+	for _, column := range []string{
+		"enclosure_url",
+		"enclosure_type",
+		"enclosure_path",
+		"enclosure_accessed_at",
+		"image_url",
+		"translation_lang",
+		"translated_title",
+		"translated_content",
+		"translated_at",
+		"updated_at",
+	} {
+		err = ensureItemColumn(db, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, column := range []string{
+		"description",
+		"site_url",
+		"user_agent",
+		"http_proxy",
+		"https_only",
+		"last_visited_at",
+		"suppress_duplicate_titles",
+		"summarize_in_list",
+		"language",
+		"consecutive_errors",
+		"last_error_code",
+		"last_content_at",
+		"strip_leading_image",
+		"ingest_token",
+		"last_error_at",
+		"images_only",
+		"image_referrer",
+		"avg_fetch_ms",
+		"color",
+		"cache_enclosures_at",
+		"skip_tombstones",
+		"secret_url",
+		"timezone",
+		"share_token",
+		"stable_guids",
+		"digest_items",
+		"archived_at",
+		"min_age_minutes",
+		"sort_by_updated",
+	} {
+		err = ensureFeedColumn(db, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = db.ExecContext(context.Background(), `
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feeds_ingest_token ON feeds(ingest_token)
+`)
+	if err != nil {
+		return fmt.Errorf("create ingest token index: %w", err)
+	}
+
+	_, err = db.ExecContext(context.Background(), `
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feeds_share_token ON feeds(share_token)
+`)
+	if err != nil {
+		return fmt.Errorf("create share token index: %w", err)
+	}
+
+	err = ensureTombstoneSkipTrigger(db)
+	if err != nil {
+		return err
+	}
+
+	err = ensureFeedHistoryPruneTrigger(db)
+	if err != nil {
+		return err
+	}
+
 	err = ensureAuthSchema(db)
 	if err != nil {
 		return err
@@ -108,17 +448,34 @@ func Init(db *sql.DB) error {
 	return nil
 }
 
-// UpsertFeed is part of the store package API.
+// SetMaxFeeds caps how many feeds UpsertFeed subscribes to. Feeds already
+// subscribed can still be updated at the cap, and the saved items feed does
+// not count toward it. A non-positive limit, the default, removes the cap.
+func SetMaxFeeds(limit int) {
+	maxFeeds.Store(int64(limit))
+}
+
+// UpsertFeed is part of the store package API. It fails with
+// ErrFeedLimitReached when feedURL is new and the SetMaxFeeds cap is reached.
 func UpsertFeed(ctx context.Context, db *sql.DB, feedURL, title string) (int64, error) {
+	return upsertFeed(ctx, db, feedURL, title, maxFeeds.Load())
+}
+
+// upsertFeed adds or retitles a feed. The cap is checked in the insert
+// itself, so concurrent imports cannot overshoot it.
+func upsertFeed(ctx context.Context, db *sql.DB, feedURL, title string, limit int64) (int64, error) {
 	ctx = contextOrBackground(ctx)
 
 	now := time.Now().UTC()
 
 	_, err := db.ExecContext(ctx, `
 INSERT INTO feeds (url, title, sort_order, created_at)
-VALUES (?, ?, COALESCE((SELECT MAX(sort_order) + 1 FROM feeds), 1), ?)
+SELECT ?, ?, COALESCE((SELECT MAX(sort_order) + 1 FROM feeds), 1), ?
+WHERE ? <= 0
+	OR EXISTS (SELECT 1 FROM feeds WHERE url = ?)
+	OR (SELECT COUNT(*) FROM feeds WHERE url != ?) < ?
 ON CONFLICT(url) DO UPDATE SET title = excluded.title
-`, feedURL, title, now)
+`, feedURL, title, now, limit, feedURL, SavedFeedURL, limit)
 	if err != nil {
 		return 0, fmt.Errorf("upsert feed row: %w", err)
 	}
@@ -126,13 +483,38 @@ ON CONFLICT(url) DO UPDATE SET title = excluded.title
 	var id int64
 
 	err = db.QueryRowContext(ctx, "SELECT id FROM feeds WHERE url = ?", feedURL).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: at most %d feeds", ErrFeedLimitReached, limit)
+	}
+
 	if err != nil {
 		return 0, fmt.Errorf("lookup feed id by URL: %w", err)
 	}
 
+	err = restoreFeedTombstones(ctx, db, id, feedURL, now)
+	if err != nil {
+		return 0, err
+	}
+
 	return id, nil
 }
 
+// restoreFeedTombstones re-applies tombstones kept by DeleteFeedKeepingHistory
+// so items removed before the feed was deleted do not reappear.
+func restoreFeedTombstones(ctx context.Context, db *sql.DB, feedID int64, feedURL string, now time.Time) error {
+	_, err := db.ExecContext(ctx, `
+INSERT OR IGNORE INTO tombstones (feed_id, guid, deleted_at)
+SELECT ?, guid, deleted_at
+FROM feed_history
+WHERE feed_url = ? AND read_at IS NULL AND deleted_at > ?
+`, feedID, feedURL, now.Add(-feedHistoryRetention))
+	if err != nil {
+		return fmt.Errorf("restore tombstones for feed %d: %w", feedID, err)
+	}
+
+	return nil
+}
+
 // UpdateFeedTitle is part of the store package API.
 func UpdateFeedTitle(ctx context.Context, db *sql.DB, feedID int64, title string) error {
 	ctx = contextOrBackground(ctx)
@@ -145,13 +527,340 @@ func UpdateFeedTitle(ctx context.Context, db *sql.DB, feedID int64, title string
 	return nil
 }
 
-// DeleteFeed is part of the store package API.
+// UpdateFeedDetails stores the feed's own description, human-facing site link,
+// and declared language.
+func UpdateFeedDetails(ctx context.Context, db *sql.DB, feedID int64, description, siteURL, language string) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE feeds SET description = ?, site_url = ?, language = ? WHERE id = ?",
+		nullString(description),
+		nullString(siteURL),
+		nullString(language),
+		feedID,
+	)
+	if err != nil {
+		return fmt.Errorf("update feed details: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFeedVisited records when the feed's item list was last shown. Items
+// created after this time are flagged as new on the next visit.
+func MarkFeedVisited(ctx context.Context, db *sql.DB, feedID int64, visitedAt time.Time) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx, "UPDATE feeds SET last_visited_at = ? WHERE id = ?", visitedAt.UTC(), feedID)
+	if err != nil {
+		return fmt.Errorf("mark feed %d visited: %w", feedID, err)
+	}
+
+	return nil
+}
+
+// GetUserPref returns the stored value for a user preference key. The bool
+// reports whether the preference has been set.
+func GetUserPref(ctx context.Context, db *sql.DB, key string) (string, bool, error) {
+	ctx = contextOrBackground(ctx)
+
+	var value string
+
+	err := db.QueryRowContext(ctx, "SELECT value FROM user_prefs WHERE key = ?", key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("load user pref %q: %w", key, err)
+	}
+
+	return value, true, nil
+}
+
+// SetUserPref stores the value for a user preference key, replacing any previous value.
+func SetUserPref(ctx context.Context, db *sql.DB, key, value string) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx, `
+INSERT INTO user_prefs (key, value, updated_at) VALUES (?, ?, ?)
+ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+`, key, value, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("save user pref %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// FeedFetchSettings holds the per-feed overrides applied when fetching a feed
+// and storing its items. SuppressDuplicateTitles inserts items whose title
+// repeats one seen within duplicateTitleWindow as already read. SummarizeInList
+// shows a short plain-text preview under each collapsed item. StripLeadingImage
+// drops the image some feeds put atop every item body. ImageReferrer picks the
+// Referer the image proxy sends for the feed's images; empty means none.
+// SkipTombstones keeps no record of removed items, so those the feed still
+// lists return on its next refresh. SecretURL marks a URL carrying an access
+// token, which the UI masks and OPML export leaves out. StableGUIDs keys items
+// without a GUID by their title and published date instead of their link.
+// DigestItems folds each refresh's new items into one digest item per day.
+// MinAgeMinutes holds back items until they are that many minutes old; zero
+// shows them at once. SortByUpdated lists items by when the feed last updated
+// them rather than when it published them.
+type FeedFetchSettings struct {
+	UserAgent               string
+	HTTPProxy               string
+	HTTPSOnly               bool
+	SuppressDuplicateTitles bool
+	SummarizeInList         bool
+	ImageReferrer           string
+	StripLeadingImage       bool
+	ImagesOnly              bool
+	CacheEnclosures         bool
+	SkipTombstones          bool
+	SecretURL               bool
+	StableGUIDs             bool
+	DigestItems             bool
+	MinAgeMinutes           int
+	SortByUpdated           bool
+}
+
+// GetFeedOrder returns the saved sidebar ordering, or FeedOrderManual when
+// none is saved or the saved value is no longer recognized.
+func GetFeedOrder(ctx context.Context, db *sql.DB) (string, error) {
+	value, _, err := GetUserPref(ctx, db, feedOrderPrefKey)
+	if err != nil {
+		return "", err
+	}
+
+	switch value {
+	case FeedOrderRecentUnread, FeedOrderAdded:
+		return value, nil
+	default:
+		return FeedOrderManual, nil
+	}
+}
+
+// SetFeedOrder saves the sidebar ordering used by ListFeeds.
+func SetFeedOrder(ctx context.Context, db *sql.DB, order string) error {
+	switch order {
+	case FeedOrderManual, FeedOrderRecentUnread, FeedOrderAdded:
+		return SetUserPref(ctx, db, feedOrderPrefKey, order)
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidFeedOrder, order)
+	}
+}
+
+func feedOrderBy(ctx context.Context, db *sql.DB) (string, error) {
+	order, err := GetFeedOrder(ctx, db)
+	if err != nil {
+		return "", err
+	}
+
+	switch order {
+	case FeedOrderRecentUnread:
+		return recentUnreadFeedOrderBy, nil
+	case FeedOrderAdded:
+		return addedFeedOrderBy, nil
+	default:
+		return manualFeedOrderBy, nil
+	}
+}
+
+// UpdateFeedFetchSettings stores the feed's optional fetch overrides and item display settings.
+func UpdateFeedFetchSettings(ctx context.Context, db *sql.DB, feedID int64, settings FeedFetchSettings) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx, `
+UPDATE feeds
+SET user_agent = ?, http_proxy = ?, https_only = ?, suppress_duplicate_titles = ?, summarize_in_list = ?,
+    strip_leading_image = ?, images_only = ?, image_referrer = ?, skip_tombstones = ?,
+    secret_url = ?, stable_guids = ?, digest_items = ?, min_age_minutes = ?, sort_by_updated = ?,
+    cache_enclosures_at = CASE WHEN ? THEN COALESCE(cache_enclosures_at, ?) END
+WHERE id = ?`,
+		nullString(settings.UserAgent),
+		nullString(settings.HTTPProxy),
+		settings.HTTPSOnly,
+		settings.SuppressDuplicateTitles,
+		settings.SummarizeInList,
+		settings.StripLeadingImage,
+		settings.ImagesOnly,
+		nullString(settings.ImageReferrer),
+		settings.SkipTombstones,
+		settings.SecretURL,
+		settings.StableGUIDs,
+		settings.DigestItems,
+		max(settings.MinAgeMinutes, 0),
+		settings.SortByUpdated,
+		settings.CacheEnclosures,
+		time.Now().UTC(),
+		feedID,
+	)
+	if err != nil {
+		return fmt.Errorf("update feed fetch settings: %w", err)
+	}
+
+	if settings.SkipTombstones {
+		_, err = db.ExecContext(ctx, "DELETE FROM tombstones WHERE feed_id = ?", feedID)
+		if err != nil {
+			return fmt.Errorf("clear tombstones for feed %d: %w", feedID, err)
+		}
+	}
+
+	return nil
+}
+
+// RecordFetchDuration folds one fetch's duration into the feed's rolling
+// average, weighting it by 1/fetchDurationWeight so a single slow fetch moves
+// the average without replacing it. The first recorded fetch sets it outright.
+func RecordFetchDuration(ctx context.Context, db *sql.DB, feedID int64, duration time.Duration) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx, `
+UPDATE feeds
+SET avg_fetch_ms = CASE
+    WHEN avg_fetch_ms IS NULL THEN ?1
+    ELSE (avg_fetch_ms * (?2 - 1) + ?1) / ?2
+  END
+WHERE id = ?3`, duration.Milliseconds(), fetchDurationWeight, feedID)
+	if err != nil {
+		return fmt.Errorf("record fetch duration for feed %d: %w", feedID, err)
+	}
+
+	return nil
+}
+
+// DeleteFeed removes a feed with its items and tombstones, along with any
+// history kept from an earlier DeleteFeedKeepingHistory for the same URL.
 func DeleteFeed(ctx context.Context, db *sql.DB, feedID int64) error {
+	_, err := DeleteFeeds(ctx, db, []int64{feedID}, false)
+
+	return err
+}
+
+// DeleteFeedKeepingHistory removes a feed but first records its read state and
+// tombstones by feed URL, so re-subscribing to the same URL within the history
+// retention window restores them.
+func DeleteFeedKeepingHistory(ctx context.Context, db *sql.DB, feedID int64) error {
+	_, err := DeleteFeeds(ctx, db, []int64{feedID}, true)
+
+	return err
+}
+
+// DeleteFeeds removes the feeds in one transaction, like DeleteFeed, or like
+// DeleteFeedKeepingHistory when keepHistory is set. IDs of feeds that do not
+// exist are skipped. It returns the IDs of the feeds it deleted.
+func DeleteFeeds(ctx context.Context, db *sql.DB, feedIDs []int64, keepHistory bool) ([]int64, error) {
 	ctx = contextOrBackground(ctx)
 
-	_, err := db.ExecContext(ctx, "DELETE FROM feeds WHERE id = ?", feedID)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin delete feeds transaction: %w", err)
+	}
+
+	deleted := make([]int64, 0, len(feedIDs))
+
+	for _, feedID := range feedIDs {
+		ok, deleteErr := deleteFeedInTx(ctx, tx, feedID, keepHistory)
+		if deleteErr != nil {
+			rollbackTx(tx)
+
+			return nil, deleteErr
+		}
+
+		if ok {
+			deleted = append(deleted, feedID)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("commit delete feeds transaction: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// deleteFeedInTx deletes one feed, reporting whether it existed. With
+// keepHistory its read state and tombstones are recorded by URL first;
+// otherwise history kept for its URL earlier is cleared.
+func deleteFeedInTx(ctx context.Context, tx *sql.Tx, feedID int64, keepHistory bool) (bool, error) {
+	if keepHistory {
+		err := keepFeedHistoryInTx(ctx, tx, feedID)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		_, err := tx.ExecContext(ctx, `
+DELETE FROM feed_history
+WHERE feed_url = (SELECT url FROM feeds WHERE id = ?)
+`, feedID)
+		if err != nil {
+			return false, fmt.Errorf("clear feed history: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM feeds WHERE id = ?", feedID)
+	if err != nil {
+		return false, fmt.Errorf("delete feed %d: %w", feedID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("count deleted feed %d: %w", feedID, err)
+	}
+
+	return affected > 0, nil
+}
+
+// ensureFeedHistoryPruneTrigger (re)creates the trigger that forgets history
+// kept for deleted feeds after 30 days. It replaces any earlier version, which
+// compared datetime(deleted_at): that is NULL for the Go time format the
+// driver stores, so nothing was ever pruned. Cutting the zone suffix, as for
+// tombstones, makes it parse; the time is UTC.
+func ensureFeedHistoryPruneTrigger(db *sql.DB) error {
+	for _, statement := range []string{
+		`DROP TRIGGER IF EXISTS feed_history_prune`,
+		`
+CREATE TRIGGER feed_history_prune
+AFTER INSERT ON feed_history
+BEGIN
+	DELETE FROM feed_history
+	WHERE datetime(substr(deleted_at, 1, 19)) <= datetime('now', '-30 days');
+END`,
+	} {
+		_, err := db.ExecContext(context.Background(), statement)
+		if err != nil {
+			return fmt.Errorf("replace feed history prune trigger: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func keepFeedHistoryInTx(ctx context.Context, tx *sql.Tx, feedID int64) error {
+	now := time.Now().UTC()
+
+	_, err := tx.ExecContext(ctx, `
+INSERT OR REPLACE INTO feed_history (feed_url, guid, read_at, deleted_at)
+SELECT f.url, i.guid, i.read_at, ?
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.feed_id = ? AND i.read_at IS NOT NULL
+`, now, feedID)
+	if err != nil {
+		return fmt.Errorf("keep read history for feed %d: %w", feedID, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+INSERT OR REPLACE INTO feed_history (feed_url, guid, read_at, deleted_at)
+SELECT f.url, t.guid, NULL, ?
+FROM tombstones t
+JOIN feeds f ON f.id = t.feed_id
+WHERE t.feed_id = ?
+`, now, feedID)
 	if err != nil {
-		return fmt.Errorf("delete feed: %w", err)
+		return fmt.Errorf("keep tombstones for feed %d: %w", feedID, err)
 	}
 
 	return nil
@@ -319,8 +1028,9 @@ func UpsertItems(ctx context.Context, db *sql.DB, feedID int64, items []*gofeed.
 
 	stmt, err := db.PrepareContext(ctx, `
 INSERT OR IGNORE INTO items
-(feed_id, guid, title, link, summary, content, published_at, created_at)
-SELECT ?, ?, ?, ?, ?, ?, ?, ?
+(feed_id, guid, title, link, summary, content, published_at, updated_at, created_at, has_image, categories,
+ enclosure_url, enclosure_type, image_url)
+SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 WHERE NOT EXISTS (
 	SELECT 1 FROM tombstones WHERE feed_id = ? AND guid = ?
 )
@@ -336,40 +1046,195 @@ WHERE NOT EXISTS (
 		}
 	}()
 
-	inserted := 0
+	updateStmt, err := db.PrepareContext(ctx, `
+UPDATE items
+SET title = ?, link = ?, summary = ?, content = ?, has_image = ?, categories = ?, image_url = ?, updated_at = ?,
+    last_updated_at = CASE
+      WHEN title IS NOT ? OR link IS NOT ? OR summary IS NOT ? OR content IS NOT ? THEN ?
+      ELSE last_updated_at
+    END
+WHERE feed_id = ? AND guid = ?
+  AND (title IS NOT ? OR link IS NOT ? OR summary IS NOT ? OR content IS NOT ? OR categories IS NOT ?
+    OR updated_at IS NOT ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare item update statement: %w", err)
+	}
 
-	for idx, item := range items {
-		added, execErr := upsertItemWithStmt(ctx, stmt, feedID, idx, item, now)
-		if execErr != nil {
-			return inserted, execErr
+	defer func() {
+		closeErr := updateStmt.Close()
+		if closeErr != nil {
+			slog.Warn("stmt close failed", "err", closeErr)
 		}
+	}()
 
-		inserted += added
-	}
+	location, err := feedLocation(ctx, db, feedID)
+	if err != nil {
+		return 0, err
+	}
+
+	stableGUIDs, err := feedUsesStableGUIDs(ctx, db, feedID)
+	if err != nil {
+		return 0, err
+	}
+
+	bases, claims := itemGUIDBases(feedID, items, stableGUIDs)
+
+	owners, err := storedGUIDOwners(ctx, db, feedID, claims)
+	if err != nil {
+		return 0, err
+	}
+
+	inserted := 0
+	guids := itemGUIDs(items, bases, claims, owners)
+
+	for idx, item := range items {
+		guid := guids[idx]
+
+		added, execErr := upsertItemWithStmt(ctx, stmt, feedID, guid, item, now, location)
+		if execErr != nil {
+			return inserted, execErr
+		}
+
+		if added == 0 {
+			execErr = updateItemWithStmt(ctx, updateStmt, feedID, guid, item, now, location)
+			if execErr != nil {
+				return inserted, execErr
+			}
+		}
+
+		inserted += added
+	}
+
+	if inserted > 0 {
+		err = restoreReadHistory(ctx, db, feedID, now)
+		if err != nil {
+			return inserted, err
+		}
+
+		err = suppressDuplicateTitles(ctx, db, feedID, now)
+		if err != nil {
+			return inserted, err
+		}
+
+		err = combineDigestItems(ctx, db, feedID, now, location)
+		if err != nil {
+			return inserted, err
+		}
+	}
 
 	return inserted, nil
 }
 
+// suppressDuplicateTitles marks items inserted at now as read when their title
+// repeats one seen in the feed within duplicateTitleWindow, for feeds that opt
+// in. Seen titles are kept in feed_seen_titles because read items are cleaned
+// up long before the window ends.
+func suppressDuplicateTitles(ctx context.Context, db *sql.DB, feedID int64, now time.Time) error {
+	var enabled bool
+
+	err := db.QueryRowContext(ctx, "SELECT suppress_duplicate_titles FROM feeds WHERE id = ?", feedID).Scan(&enabled)
+	if err != nil {
+		return fmt.Errorf("load duplicate title setting for feed %d: %w", feedID, err)
+	}
+
+	if !enabled {
+		return nil
+	}
+
+	windowStart := now.Add(-duplicateTitleWindow)
+
+	_, err = db.ExecContext(ctx, `
+UPDATE items
+SET read_at = ?
+WHERE feed_id = ? AND created_at = ? AND read_at IS NULL AND title <> ? AND (
+	EXISTS (
+		SELECT 1 FROM feed_seen_titles s
+		WHERE s.feed_id = items.feed_id AND s.title = items.title AND s.seen_at >= ?
+	)
+	OR EXISTS (
+		SELECT 1 FROM items prior
+		WHERE prior.feed_id = items.feed_id AND prior.title = items.title
+		  AND prior.id < items.id AND prior.created_at >= ?
+	)
+)
+`, now, feedID, now, untitledItemTitle, windowStart, windowStart)
+	if err != nil {
+		return fmt.Errorf("suppress duplicate titles for feed %d: %w", feedID, err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+INSERT INTO feed_seen_titles (feed_id, title, seen_at)
+SELECT feed_id, title, ? FROM items WHERE feed_id = ? AND created_at = ?
+ON CONFLICT(feed_id, title) DO UPDATE SET seen_at = excluded.seen_at
+`, now, feedID, now)
+	if err != nil {
+		return fmt.Errorf("record seen titles for feed %d: %w", feedID, err)
+	}
+
+	_, err = db.ExecContext(ctx, "DELETE FROM feed_seen_titles WHERE feed_id = ? AND seen_at < ?", feedID, windowStart)
+	if err != nil {
+		return fmt.Errorf("prune seen titles for feed %d: %w", feedID, err)
+	}
+
+	return nil
+}
+
+// restoreReadHistory marks newly inserted items read when history kept by
+// DeleteFeedKeepingHistory recorded them as read under the same feed URL.
+func restoreReadHistory(ctx context.Context, db *sql.DB, feedID int64, now time.Time) error {
+	_, err := db.ExecContext(ctx, `
+UPDATE items
+SET read_at = (
+	SELECT h.read_at
+	FROM feed_history h
+	JOIN feeds f ON f.url = h.feed_url
+	WHERE f.id = items.feed_id AND h.guid = items.guid
+)
+WHERE feed_id = ? AND read_at IS NULL AND guid IN (
+	SELECT h.guid
+	FROM feed_history h
+	JOIN feeds f ON f.url = h.feed_url
+	WHERE f.id = ? AND h.read_at IS NOT NULL AND h.deleted_at > ?
+)
+`, feedID, feedID, now.Add(-feedHistoryRetention))
+	if err != nil {
+		return fmt.Errorf("restore read history for feed %d: %w", feedID, err)
+	}
+
+	return nil
+}
+
 func upsertItemWithStmt(
 	ctx context.Context,
 	stmt *sql.Stmt,
 	feedID int64,
-	idx int,
+	guid string,
 	item *gofeed.Item,
 	now time.Time,
+	location *time.Location,
 ) (int, error) {
-	guid := deriveItemGUID(feedID, idx, item)
-	publishedAt := deriveItemPublishedAt(item)
+	publishedAt := deriveItemPublishedAt(item, location)
+	summary := strings.TrimSpace(item.Description)
+	body := strings.TrimSpace(item.Content)
+	enclosureURL, enclosureType := itemEnclosure(item)
+	imageURL := itemImageURL(item, summary, body)
 
 	res, execErr := stmt.ExecContext(ctx,
 		feedID,
 		guid,
-		fallbackString(item.Title, "(untitled)"),
+		fallbackString(item.Title, untitledItemTitle),
 		fallbackString(item.Link, "#"),
-		strings.TrimSpace(item.Description),
-		strings.TrimSpace(item.Content),
+		summary,
+		body,
 		nullTimeToValue(publishedAt),
+		nullTimeToValue(deriveItemUpdatedAt(item, location)),
 		now,
+		itemHasImage(summary, body) || imageURL != "",
+		joinItemCategories(item.Categories),
+		nullString(enclosureURL),
+		nullString(enclosureType),
+		nullString(imageURL),
 		feedID,
 		guid,
 	)
@@ -389,7 +1254,283 @@ func upsertItemWithStmt(
 	return int(affected), nil
 }
 
-func deriveItemGUID(feedID int64, idx int, item *gofeed.Item) string {
+// itemImageURL picks the image shown as the item's thumbnail: a Media RSS
+// thumbnail or image, which gofeed leaves in the extensions (for Atom feeds
+// such as YouTube's, inside media:group), then the image gofeed found itself,
+// then the first inline image.
+func itemImageURL(item *gofeed.Item, summary, body string) string {
+	media := item.Extensions["media"]
+
+	groups := []map[string][]ext.Extension{media}
+	for _, group := range media["group"] {
+		groups = append(groups, group.Children)
+	}
+
+	for _, name := range []string{"thumbnail", "content"} {
+		for _, group := range groups {
+			for _, element := range group[name] {
+				if name == "content" && !isMediaImage(element) {
+					continue
+				}
+
+				if raw := strings.TrimSpace(element.Attrs["url"]); raw != "" {
+					return raw
+				}
+			}
+		}
+	}
+
+	if item.Image != nil && strings.TrimSpace(item.Image.URL) != "" {
+		return strings.TrimSpace(item.Image.URL)
+	}
+
+	if raw := content.FirstImageURL(body); raw != "" {
+		return raw
+	}
+
+	return content.FirstImageURL(summary)
+}
+
+func isMediaImage(element ext.Extension) bool {
+	return element.Attrs["medium"] == "image" || strings.HasPrefix(element.Attrs["type"], "image/")
+}
+
+// itemHasImage is stored as items.has_image so feeds showing only items with
+// images are filtered without scanning content at query time.
+func itemHasImage(summary, body string) bool {
+	return content.HasImage(body) || content.HasImage(summary)
+}
+
+// updateItemWithStmt rewrites an existing item when the feed has edited its
+// title, link or body, stamping last_updated_at so the UI can flag the edit.
+// A change to the item's categories alone is stored without flagging it.
+// Unchanged items are left alone and keep their previous timestamp.
+func updateItemWithStmt(
+	ctx context.Context,
+	stmt *sql.Stmt,
+	feedID int64,
+	guid string,
+	item *gofeed.Item,
+	now time.Time,
+	location *time.Location,
+) error {
+	title := fallbackString(item.Title, untitledItemTitle)
+	link := fallbackString(item.Link, "#")
+	summary := strings.TrimSpace(item.Description)
+	body := strings.TrimSpace(item.Content)
+	categories := joinItemCategories(item.Categories)
+	imageURL := itemImageURL(item, summary, body)
+	updatedAt := nullTimeToValue(deriveItemUpdatedAt(item, location))
+
+	_, err := stmt.ExecContext(ctx,
+		title, link, summary, body, itemHasImage(summary, body) || imageURL != "", categories, nullString(imageURL),
+		updatedAt,
+		title, link, summary, body, now,
+		feedID, guid,
+		title, link, summary, body, categories, updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("execute item update statement: %w", err)
+	}
+
+	return nil
+}
+
+// joinItemCategories stores an item's own <category> values comma-joined,
+// dropping blanks and case-insensitive repeats. Commas inside a category are
+// replaced so the stored list splits back cleanly.
+func joinItemCategories(categories []string) any {
+	seen := make(map[string]struct{}, len(categories))
+	kept := make([]string, 0, len(categories))
+
+	for _, category := range categories {
+		category = strings.Join(strings.Fields(strings.ReplaceAll(category, ",", " ")), " ")
+		if category == "" {
+			continue
+		}
+
+		key := strings.ToLower(category)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		kept = append(kept, category)
+	}
+
+	return nullString(strings.Join(kept, ","))
+}
+
+// itemGUIDBases returns the GUID each item would have on its own, along with
+// the distinct entries claiming each one. With stable set, items lacking a
+// GUID of their own are keyed by stableItemGUID rather than their link.
+func itemGUIDBases(feedID int64, items []*gofeed.Item, stable bool) ([]string, itemGUIDClaims) {
+	bases := make([]string, len(items))
+	claims := make(itemGUIDClaims, len(items))
+
+	for idx, item := range items {
+		bases[idx] = baseItemGUID(feedID, idx, item)
+		if stable {
+			if stableGUID, ok := stableItemGUID(item); ok {
+				bases[idx] = stableGUID
+			}
+		}
+
+		claims.add(bases[idx], item)
+	}
+
+	return bases, claims
+}
+
+// storedGUIDOwners returns, for each GUID that distinct entries of the payload
+// share, the itemEntryName of the item already stored under it, if any.
+func storedGUIDOwners(
+	ctx context.Context,
+	db *sql.DB,
+	feedID int64,
+	claims itemGUIDClaims,
+) (map[string]string, error) {
+	owners := make(map[string]string)
+
+	for guid := range claims {
+		if !claims.shared(guid) {
+			continue
+		}
+
+		var link, title string
+
+		err := db.QueryRowContext(ctx, "SELECT link, title FROM items WHERE feed_id = ? AND guid = ?",
+			feedID, guid).Scan(&link, &title)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("load item stored under guid %q: %w", guid, err)
+		}
+
+		if link == "#" {
+			link = ""
+		}
+
+		owners[guid] = itemEntryName(link, title)
+	}
+
+	return owners, nil
+}
+
+// itemGUIDs returns the stored GUID for each item. When distinct entries share
+// a GUID within one payload, the one already stored under it keeps it, or the
+// first when none is, so that item still updates in place. The others get it
+// suffixed with their link, or with itemEntryHash when the link is missing or
+// shared too, so the UNIQUE(feed_id, guid) constraint keeps all of them.
+// Repeats of one entry keep one GUID. Only the bare GUID's owner depends on
+// where entries sit in the payload, so entries the feed adds above one leave
+// its GUID alone.
+func itemGUIDs(items []*gofeed.Item, bases []string, claims itemGUIDClaims, owners map[string]string) []string {
+	for idx, item := range items {
+		if link := linkItemGUID(bases[idx], item); link != "" && claims.shared(bases[idx]) {
+			claims.add(link, item)
+		}
+	}
+
+	guids := make([]string, len(items))
+
+	for idx, item := range items {
+		guids[idx] = bases[idx]
+		if !claims.shared(bases[idx]) || claims.owns(bases[idx], item, owners) {
+			continue
+		}
+
+		link := linkItemGUID(bases[idx], item)
+		if link != "" && !claims.shared(link) {
+			guids[idx] = link
+		} else {
+			guids[idx] = bases[idx] + "#" + itemEntryHash(item)
+		}
+	}
+
+	return guids
+}
+
+// itemGUIDClaims lists the distinct entries of a payload using each GUID.
+type itemGUIDClaims map[string][]*gofeed.Item
+
+func (c itemGUIDClaims) add(guid string, item *gofeed.Item) {
+	for _, entry := range c[guid] {
+		if sameItemEntry(entry, item) {
+			return
+		}
+	}
+
+	c[guid] = append(c[guid], item)
+}
+
+func (c itemGUIDClaims) shared(guid string) bool {
+	return len(c[guid]) > 1
+}
+
+// owns reports whether item keeps the bare guid: it matches the item stored
+// under guid, or none of the claimants does and item is the first.
+func (c itemGUIDClaims) owns(guid string, item *gofeed.Item, owners map[string]string) bool {
+	if owner, ok := owners[guid]; ok {
+		for _, entry := range c[guid] {
+			if itemEntryName(entry.Link, entry.Title) == owner {
+				return sameItemEntry(entry, item)
+			}
+		}
+	}
+
+	return sameItemEntry(c[guid][0], item)
+}
+
+// linkItemGUID suffixes guid with the item's link, or returns "" when the item
+// has no link or the link is the GUID already.
+func linkItemGUID(guid string, item *gofeed.Item) string {
+	link := strings.TrimSpace(item.Link)
+	if link == "" || link == guid {
+		return ""
+	}
+
+	return guid + "#" + link
+}
+
+// itemEntryHash fingerprints an item by itemEntryKey. Unlike its position in
+// the payload or its body, these do not change when the feed adds entries
+// above it or edits it, so a disambiguated GUID stays the same across
+// refreshes.
+func itemEntryHash(item *gofeed.Item) string {
+	sum := sha256.Sum256([]byte(itemEntryKey(item)))
+
+	return hex.EncodeToString(sum[:stableGUIDBytes])
+}
+
+// itemEntryKey identifies an entry among those sharing a GUID by its
+// itemEntryName and published date, which feeds rarely change when they edit
+// an entry.
+func itemEntryKey(item *gofeed.Item) string {
+	published := ""
+	if item.PublishedParsed != nil {
+		published = item.PublishedParsed.UTC().Format(time.RFC3339Nano)
+	}
+
+	return itemEntryName(item.Link, item.Title) + "\n" + published
+}
+
+// itemEntryName is an entry's link, or its title when it has no link.
+func itemEntryName(link, title string) string {
+	if name := strings.TrimSpace(link); name != "" {
+		return name
+	}
+
+	return strings.TrimSpace(title)
+}
+
+func sameItemEntry(first, next *gofeed.Item) bool {
+	return itemEntryKey(first) == itemEntryKey(next)
+}
+
+func baseItemGUID(feedID int64, idx int, item *gofeed.Item) string {
 	candidates := []string{
 		strings.TrimSpace(item.GUID),
 		strings.TrimSpace(item.Link),
@@ -408,12 +1549,75 @@ func deriveItemGUID(feedID int64, idx int, item *gofeed.Item) string {
 	return fmt.Sprintf("feed-%d-item-%d", feedID, idx)
 }
 
-func deriveItemPublishedAt(item *gofeed.Item) sql.NullTime {
+// stableItemGUID derives a GUID from an item's title and published date for
+// feeds whose links change between fetches, such as by gaining tracking
+// parameters. It reports false when the item has a GUID of its own or lacks
+// either a title or a date.
+func stableItemGUID(item *gofeed.Item) (string, bool) {
+	title := strings.TrimSpace(item.Title)
+	if strings.TrimSpace(item.GUID) != "" || title == "" || item.PublishedParsed == nil {
+		return "", false
+	}
+
+	sum := sha256.Sum256([]byte(title + "\n" + item.PublishedParsed.UTC().Format(time.RFC3339)))
+
+	return stableGUIDPrefix + hex.EncodeToString(sum[:stableGUIDBytes]), true
+}
+
+// feedUsesStableGUIDs reports whether the feed opted in to stableItemGUID.
+func feedUsesStableGUIDs(ctx context.Context, db *sql.DB, feedID int64) (bool, error) {
+	var stable bool
+
+	err := db.QueryRowContext(ctx, "SELECT stable_guids FROM feeds WHERE id = ?", feedID).Scan(&stable)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("load GUID strategy for feed %d: %w", feedID, err)
+	}
+
+	return stable, nil
+}
+
+// feedLocation loads the time zone set with SetFeedTimezone, falling back to
+// UTC when none is set or the zone database no longer knows it.
+func feedLocation(ctx context.Context, db *sql.DB, feedID int64) (*time.Location, error) {
+	var name sql.NullString
+
+	err := db.QueryRowContext(ctx, "SELECT timezone FROM feeds WHERE id = ?", feedID).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.UTC, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("load time zone for feed %d: %w", feedID, err)
+	}
+
+	if !name.Valid || name.String == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(name.String)
+	if err != nil {
+		slog.Warn("feed time zone unavailable", "feed_id", feedID, "timezone", name.String, "err", err)
+
+		return time.UTC, nil
+	}
+
+	return location, nil
+}
+
+// deriveItemPublishedAt picks the item's published time, or its updated time
+// when the feed gives no published one, as many Atom feeds do not. gofeed
+// reads a date without a zone as UTC; for such dates the wall clock is
+// reinterpreted in location, the feed's configured zone.
+func deriveItemPublishedAt(item *gofeed.Item, location *time.Location) sql.NullTime {
 	switch {
 	case item.PublishedParsed != nil:
-		return sql.NullTime{Time: item.PublishedParsed.UTC(), Valid: true}
+		return sql.NullTime{Time: inFeedLocation(*item.PublishedParsed, item.Published, location), Valid: true}
 	case item.UpdatedParsed != nil:
-		return sql.NullTime{Time: item.UpdatedParsed.UTC(), Valid: true}
+		return sql.NullTime{Time: inFeedLocation(*item.UpdatedParsed, item.Updated, location), Valid: true}
 	default:
 		return sql.NullTime{
 			Time:  time.Time{},
@@ -422,6 +1626,35 @@ func deriveItemPublishedAt(item *gofeed.Item) sql.NullTime {
 	}
 }
 
+// deriveItemUpdatedAt picks the time the feed says it last updated the item,
+// reading a zoneless date like deriveItemPublishedAt.
+func deriveItemUpdatedAt(item *gofeed.Item, location *time.Location) sql.NullTime {
+	if item.UpdatedParsed == nil {
+		return sql.NullTime{Time: time.Time{}, Valid: false}
+	}
+
+	return sql.NullTime{Time: inFeedLocation(*item.UpdatedParsed, item.Updated, location), Valid: true}
+}
+
+// explicitZonePattern matches the end of a date that names its zone: "Z", a
+// numeric offset such as "+0200" or "-07:00", or an abbreviation like "GMT".
+var explicitZonePattern = regexp.MustCompile(`(?i)(\dZ|[+-]\d{2}:?\d{2}|\s[A-Z]{1,5})$`)
+
+// inFeedLocation returns parsed in UTC, first moving a zoneless date's wall
+// clock into location. raw is the date as the feed wrote it.
+func inFeedLocation(parsed time.Time, raw string, location *time.Location) time.Time {
+	if _, offset := parsed.Zone(); offset != 0 || location == nil || location == time.UTC ||
+		explicitZonePattern.MatchString(strings.TrimSpace(raw)) {
+		return parsed.UTC()
+	}
+
+	return time.Date(
+		parsed.Year(), parsed.Month(), parsed.Day(),
+		parsed.Hour(), parsed.Minute(), parsed.Second(), parsed.Nanosecond(),
+		location,
+	).UTC()
+}
+
 // EnforceItemLimit is part of the store package API.
 func EnforceItemLimit(
 	ctx context.Context,
@@ -448,13 +1681,14 @@ INSERT OR IGNORE INTO tombstones (feed_id, guid, deleted_at)
 SELECT feed_id, guid, ?
 FROM items
 WHERE feed_id = ?
+  AND feed_id NOT IN (SELECT id FROM feeds WHERE archived_at IS NOT NULL)
   AND id NOT IN (
 	SELECT id FROM items
 	WHERE feed_id = ?
 	ORDER BY COALESCE(published_at, created_at) DESC, id DESC
 	LIMIT ?
   )
-	`, now, feedID, feedID, maxItemsPerFeed)
+	`, now, feedID, feedID, MaxItemsPerFeed)
 	if err != nil {
 		return fmt.Errorf("insert tombstones for pruned items: %w", err)
 	}
@@ -462,13 +1696,14 @@ WHERE feed_id = ?
 	_, err = tx.ExecContext(ctx, `
 DELETE FROM items
 WHERE feed_id = ?
+  AND feed_id NOT IN (SELECT id FROM feeds WHERE archived_at IS NOT NULL)
   AND id NOT IN (
 	SELECT id FROM items
 	WHERE feed_id = ?
 	ORDER BY COALESCE(published_at, created_at) DESC, id DESC
 	LIMIT ?
   )
-	`, feedID, feedID, maxItemsPerFeed)
+	`, feedID, feedID, MaxItemsPerFeed)
 	if err != nil {
 		return fmt.Errorf("delete items beyond item limit: %w", err)
 	}
@@ -481,19 +1716,19 @@ WHERE feed_id = ?
 	return nil
 }
 
-// ListFeeds is part of the store package API.
+// ListFeeds returns every feed in sidebar order, as chosen by SetFeedOrder.
 func ListFeeds(ctx context.Context, db *sql.DB) ([]view.FeedView, error) {
 	ctx = contextOrBackground(ctx)
 
+	orderBy, err := feedOrderBy(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := db.QueryContext(ctx, `
-SELECT f.id, COALESCE(f.custom_title, f.title) AS display_title, f.title, f.url,
-       (SELECT COUNT(*) FROM items i WHERE i.feed_id = f.id) AS item_count,
-       (SELECT COUNT(*) FROM items i WHERE i.feed_id = f.id AND i.read_at IS NULL) AS unread_count,
-       f.last_refreshed_at,
-       f.last_error
+SELECT `+feedViewColumns+`
 FROM feeds f
-ORDER BY f.sort_order ASC, display_title COLLATE NOCASE, f.id ASC
-	`)
+ORDER BY `+orderBy)
 	if err != nil {
 		return nil, fmt.Errorf("query feeds: %w", err)
 	}
@@ -526,6 +1761,339 @@ ORDER BY f.sort_order ASC, display_title COLLATE NOCASE, f.id ASC
 	return feeds, nil
 }
 
+// ListFeedHealth returns every feed in sidebar order with its refresh
+// bookkeeping. Items per day averages the items stored over the last 30 days,
+// or since the feed was added when that is more recent.
+func ListFeedHealth(ctx context.Context, db *sql.DB) ([]view.FeedHealthView, error) {
+	ctx = contextOrBackground(ctx)
+
+	feeds, err := ListFeeds(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	rows, err := db.QueryContext(ctx, `
+SELECT f.id, f.created_at, f.last_refreshed_at, f.last_content_at, f.next_refresh_at,
+       f.consecutive_errors, f.last_error_code,
+       (SELECT COUNT(*) FROM items i WHERE i.feed_id = f.id AND i.created_at >= ?) AS recent_items
+FROM feeds f
+`, now.Add(-itemRateWindow))
+	if err != nil {
+		return nil, fmt.Errorf("query feed health: %w", err)
+	}
+
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			slog.Warn("rows close failed", "err", closeErr)
+		}
+	}()
+
+	healthByID := make(map[int64]view.FeedHealthView, len(feeds))
+	feedsByID := make(map[int64]view.FeedView, len(feeds))
+
+	for _, feed := range feeds {
+		feedsByID[feed.ID] = feed
+	}
+
+	for rows.Next() {
+		var (
+			id                int64
+			createdAt         time.Time
+			lastChecked       sql.NullTime
+			lastContent       sql.NullTime
+			nextRefresh       sql.NullTime
+			consecutiveErrors int
+			errorCode         sql.NullInt64
+			recentItems       int
+		)
+
+		scanErr := rows.Scan(
+			&id, &createdAt, &lastChecked, &lastContent, &nextRefresh, &consecutiveErrors, &errorCode, &recentItems,
+		)
+		if scanErr != nil {
+			return nil, fmt.Errorf("scan feed health row: %w", scanErr)
+		}
+
+		healthByID[id] = view.BuildFeedHealthView(
+			feedsByID[id],
+			lastChecked,
+			lastContent,
+			nextRefresh,
+			consecutiveErrors,
+			errorCode,
+			itemsPerDay(recentItems, createdAt, now),
+		)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return nil, fmt.Errorf("iterate feed health rows: %w", rowsErr)
+	}
+
+	health := make([]view.FeedHealthView, 0, len(feeds))
+	for _, feed := range feeds {
+		health = append(health, healthByID[feed.ID])
+	}
+
+	return health, nil
+}
+
+// itemsPerDay averages recentItems over the rate window, shortened to the
+// feed's age for new feeds but never below one day.
+func itemsPerDay(recentItems int, createdAt, now time.Time) float64 {
+	window := min(max(now.Sub(createdAt), hoursPerDay*time.Hour), itemRateWindow)
+
+	return float64(recentItems) / (window.Hours() / hoursPerDay)
+}
+
+// ListFeedsByTag returns the feeds carrying tag, in sidebar order.
+func ListFeedsByTag(ctx context.Context, db *sql.DB, tag string) ([]view.FeedView, error) {
+	ctx = contextOrBackground(ctx)
+
+	orderBy, err := feedOrderBy(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+SELECT `+feedViewColumns+`
+FROM feeds f
+JOIN feed_tags ft ON ft.feed_id = f.id
+WHERE ft.tag = ?
+ORDER BY `+orderBy, tag)
+	if err != nil {
+		return nil, fmt.Errorf("query feeds for tag %q: %w", tag, err)
+	}
+
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			slog.Warn("rows close failed", "err", closeErr)
+		}
+	}()
+
+	var feeds []view.FeedView
+
+	for rows.Next() {
+		nextFeed, scanErr := scanFeedView(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		feeds = append(feeds, nextFeed)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return nil, fmt.Errorf("iterate feeds for tag %q: %w", tag, rowsErr)
+	}
+
+	return feeds, nil
+}
+
+// AddFeedTag attaches a normalized tag to a feed. Adding a tag the feed
+// already has is a no-op.
+func AddFeedTag(ctx context.Context, db *sql.DB, feedID int64, tag string) error {
+	ctx = contextOrBackground(ctx)
+
+	normalized, ok := NormalizeTag(tag)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+	}
+
+	_, err := db.ExecContext(ctx, "INSERT OR IGNORE INTO feed_tags (feed_id, tag) VALUES (?, ?)", feedID, normalized)
+	if err != nil {
+		return fmt.Errorf("add tag %q to feed %d: %w", normalized, feedID, err)
+	}
+
+	return nil
+}
+
+// RemoveFeedTag detaches a tag from a feed.
+func RemoveFeedTag(ctx context.Context, db *sql.DB, feedID int64, tag string) error {
+	ctx = contextOrBackground(ctx)
+
+	normalized, ok := NormalizeTag(tag)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+	}
+
+	_, err := db.ExecContext(ctx, "DELETE FROM feed_tags WHERE feed_id = ? AND tag = ?", feedID, normalized)
+	if err != nil {
+		return fmt.Errorf("remove tag %q from feed %d: %w", normalized, feedID, err)
+	}
+
+	return nil
+}
+
+// NormalizeTag lowercases a tag and joins words with hyphens. It reports false
+// for empty or overlong tags and for anything other than letters, digits,
+// hyphens, and underscores.
+func NormalizeTag(raw string) (string, bool) {
+	tag := strings.Join(strings.Fields(strings.ToLower(raw)), "-")
+	if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+		return "", false
+	}
+
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", false
+		}
+	}
+
+	return tag, true
+}
+
+// SetFeedColor labels a feed with one of the sidebar colors, or clears its
+// label when color is empty.
+func SetFeedColor(ctx context.Context, db *sql.DB, feedID int64, color string) error {
+	ctx = contextOrBackground(ctx)
+
+	normalized, ok := NormalizeFeedColor(color)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidFeedColor, color)
+	}
+
+	_, err := db.ExecContext(ctx, "UPDATE feeds SET color = ? WHERE id = ?", nullString(normalized), feedID)
+	if err != nil {
+		return fmt.Errorf("set feed color: %w", err)
+	}
+
+	return nil
+}
+
+// SetFeedTimezone sets the IANA time zone used to read the feed's item dates
+// that carry no zone of their own, or restores UTC when name is empty.
+func SetFeedTimezone(ctx context.Context, db *sql.DB, feedID int64, name string) error {
+	ctx = contextOrBackground(ctx)
+
+	normalized, ok := NormalizeFeedTimezone(name)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidFeedTimezone, name)
+	}
+
+	_, err := db.ExecContext(ctx, "UPDATE feeds SET timezone = ? WHERE id = ?", nullString(normalized), feedID)
+	if err != nil {
+		return fmt.Errorf("set feed time zone: %w", err)
+	}
+
+	return nil
+}
+
+// NormalizeFeedTimezone returns the canonical name of an IANA time zone. It
+// reports false for names the zone database does not know; an empty name or
+// UTC is valid and normalizes to "", the default.
+func NormalizeFeedTimezone(raw string) (string, bool) {
+	name := strings.TrimSpace(raw)
+	if name == "" || strings.EqualFold(name, "UTC") {
+		return "", true
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil || strings.EqualFold(name, "Local") {
+		return "", false
+	}
+
+	return location.String(), true
+}
+
+// NormalizeFeedColor lowercases a feed color name. It reports false for
+// anything outside the fixed palette the stylesheet has feed-color-* rules for;
+// an empty color is valid and means no label.
+func NormalizeFeedColor(raw string) (string, bool) {
+	switch color := strings.ToLower(strings.TrimSpace(raw)); color {
+	case "", "red", "orange", "yellow", "green", "teal", "blue", "purple", "pink", "gray":
+		return color, true
+	default:
+		return "", false
+	}
+}
+
+// NextFeedWithUnread returns the first feed after afterFeedID in sort order
+// that has unread items, wrapping around to the start of the list. It returns
+// 0 when no feed has unread items.
+func NextFeedWithUnread(ctx context.Context, db *sql.DB, afterFeedID int64) (int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	orderBy, err := feedOrderBy(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+SELECT f.id,
+       f.archived_at IS NULL AND EXISTS (
+         SELECT 1 FROM items i WHERE i.feed_id = f.id AND i.read_at IS NULL AND i.dismissed_at IS NULL
+           AND `+itemOldEnoughFilter+`
+       ) AS has_unread
+FROM feeds f
+ORDER BY `+orderBy)
+	if err != nil {
+		return 0, fmt.Errorf("query feeds with unread: %w", err)
+	}
+
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			slog.Warn("rows close failed", "err", closeErr)
+		}
+	}()
+
+	var (
+		orderedIDs []int64
+		unreadIDs  = make(map[int64]struct{})
+	)
+
+	for rows.Next() {
+		var (
+			id        int64
+			hasUnread bool
+		)
+
+		scanErr := rows.Scan(&id, &hasUnread)
+		if scanErr != nil {
+			return 0, fmt.Errorf("scan feed unread state: %w", scanErr)
+		}
+
+		orderedIDs = append(orderedIDs, id)
+
+		if hasUnread {
+			unreadIDs[id] = struct{}{}
+		}
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return 0, fmt.Errorf("iterate feed unread rows: %w", rowsErr)
+	}
+
+	return selectNextUnreadFeed(orderedIDs, unreadIDs, afterFeedID), nil
+}
+
+func selectNextUnreadFeed(orderedIDs []int64, unreadIDs map[int64]struct{}, afterFeedID int64) int64 {
+	start := 0
+
+	for idx, id := range orderedIDs {
+		if id == afterFeedID {
+			start = idx + 1
+
+			break
+		}
+	}
+
+	for offset := range orderedIDs {
+		id := orderedIDs[(start+offset)%len(orderedIDs)]
+		if _, ok := unreadIDs[id]; ok {
+			return id
+		}
+	}
+
+	return 0
+}
+
 // SelectRemainingFeed is part of the store package API.
 func SelectRemainingFeed(selectedID, deletedID int64, feeds []view.FeedView) int64 {
 	if len(feeds) == 0 {
@@ -557,11 +2125,14 @@ func containsFeedID(feeds []view.FeedView, targetID int64) bool {
 	return false
 }
 
-// LoadItemList is part of the store package API.
+// LoadItemList loads a feed and the first pageSize of its items, noting
+// where the next page starts when more remain. A non-positive pageSize loads
+// every item.
 func LoadItemList(
 	ctx context.Context,
 	db *sql.DB,
 	feedID int64,
+	pageSize int,
 ) (*view.ItemListData, error) {
 	ctx = contextOrBackground(ctx)
 
@@ -570,21 +2141,87 @@ func LoadItemList(
 		return nil, err
 	}
 
-	items, err := ListItems(ctx, db, feedID)
+	limit := -1
+	if pageSize > 0 {
+		limit = pageSize + 1
+	}
+
+	items, err := ListItemsBefore(ctx, db, feedID, 0, limit)
 	if err != nil {
 		return nil, err
 	}
 
+	items, nextPage := splitItemPage(feedID, items, pageSize)
+
 	newestID := maxItemID(items)
+	if nextPage.BeforeID != 0 {
+		// Items on later pages can have higher IDs than any shown, and the
+		// poller must not announce them as new.
+		newestID, err = maxVisibleItemID(ctx, db, feedID)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &view.ItemListData{
 		Feed:     feed,
 		Items:    items,
 		NewestID: newestID,
 		NewItems: view.NewItemsData{FeedID: feed.ID, Count: 0, SwapOOB: false},
+		NextPage: nextPage,
+		Refresh:  view.RefreshStatusData{FeedID: feed.ID, Refreshing: false},
 	}, nil
 }
 
+// LoadItemPage returns the pageSize items listed after beforeID and where
+// the page after them starts.
+func LoadItemPage(
+	ctx context.Context,
+	db *sql.DB,
+	feedID int64,
+	beforeID int64,
+	pageSize int,
+) ([]view.ItemView, view.ItemPageData, error) {
+	items, err := ListItemsBefore(ctx, db, feedID, beforeID, pageSize+1)
+	if err != nil {
+		return nil, view.ItemPageData{}, err
+	}
+
+	items, nextPage := splitItemPage(feedID, items, pageSize)
+
+	return items, nextPage, nil
+}
+
+// splitItemPage trims items fetched one past pageSize back to pageSize,
+// pointing the next page at the last item kept when the extra one shows
+// more remain.
+func splitItemPage(feedID int64, items []view.ItemView, pageSize int) ([]view.ItemView, view.ItemPageData) {
+	nextPage := view.ItemPageData{FeedID: feedID, BeforeID: 0}
+	if pageSize <= 0 || len(items) <= pageSize {
+		return items, nextPage
+	}
+
+	items = items[:pageSize]
+	nextPage.BeforeID = items[pageSize-1].ID
+
+	return items, nextPage
+}
+
+func maxVisibleItemID(ctx context.Context, db *sql.DB, feedID int64) (int64, error) {
+	var maxID int64
+
+	err := db.QueryRowContext(ctx, `
+SELECT COALESCE(MAX(i.id), 0)
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.feed_id = ? AND `+visibleItemFilter, feedID).Scan(&maxID)
+	if err != nil {
+		return 0, fmt.Errorf("query newest item for feed %d: %w", feedID, err)
+	}
+
+	return maxID, nil
+}
+
 // GetFeed is part of the store package API.
 func GetFeed(
 	ctx context.Context,
@@ -596,9 +2233,35 @@ func GetFeed(
 	row := db.QueryRowContext(ctx, `
 SELECT f.id, COALESCE(f.custom_title, f.title) AS display_title, f.title, f.url,
        (SELECT COUNT(*) FROM items i WHERE i.feed_id = f.id) AS item_count,
-       (SELECT COUNT(*) FROM items i WHERE i.feed_id = f.id AND i.read_at IS NULL) AS unread_count,
+       `+feedUnreadCountColumn+`,
        f.last_refreshed_at,
-       f.last_error
+       f.last_error,
+       f.last_error_at,
+       f.description,
+       f.site_url,
+       f.language,
+       f.user_agent,
+       f.http_proxy,
+       f.https_only,
+       f.suppress_duplicate_titles,
+       f.summarize_in_list,
+       f.strip_leading_image,
+       f.images_only,
+       f.image_referrer,
+       f.ingest_token,
+       f.created_at,
+       f.color,
+       f.cache_enclosures_at IS NOT NULL,
+       f.skip_tombstones,
+       f.secret_url,
+       f.stable_guids,
+       f.digest_items,
+       f.archived_at IS NOT NULL,
+       f.min_age_minutes,
+       f.sort_by_updated,
+       f.timezone,
+       f.share_token,
+       `+feedTagsColumn+`
 FROM feeds f
 WHERE f.id = ?
 `, feedID)
@@ -612,32 +2275,257 @@ WHERE f.id = ?
 		unreadCount   int
 		lastChecked   sql.NullTime
 		lastError     sql.NullString
+		lastErrorAt   sql.NullTime
+		description   sql.NullString
+		siteURL       sql.NullString
+		language      sql.NullString
+		userAgent     sql.NullString
+		httpProxy     sql.NullString
+		httpsOnly     bool
+		suppressDups  bool
+		summarize     bool
+		stripImage    bool
+		imagesOnly    bool
+		imageReferrer sql.NullString
+		ingestToken   sql.NullString
+		createdAt     time.Time
+		color         sql.NullString
+		cacheEncl     bool
+		skipTombs     bool
+		secretURL     bool
+		stableGUIDs   bool
+		digestItems   bool
+		archived      bool
+		minAge        int
+		sortByUpdated bool
+		timezone      sql.NullString
+		shareToken    sql.NullString
+		tags          sql.NullString
 	)
 
-	err := row.Scan(&id, &title, &originalTitle, &url, &itemCount, &unreadCount, &lastChecked, &lastError)
+	err := row.Scan(
+		&id,
+		&title,
+		&originalTitle,
+		&url,
+		&itemCount,
+		&unreadCount,
+		&lastChecked,
+		&lastError,
+		&lastErrorAt,
+		&description,
+		&siteURL,
+		&language,
+		&userAgent,
+		&httpProxy,
+		&httpsOnly,
+		&suppressDups,
+		&summarize,
+		&stripImage,
+		&imagesOnly,
+		&imageReferrer,
+		&ingestToken,
+		&createdAt,
+		&color,
+		&cacheEncl,
+		&skipTombs,
+		&secretURL,
+		&stableGUIDs,
+		&digestItems,
+		&archived,
+		&minAge,
+		&sortByUpdated,
+		&timezone,
+		&shareToken,
+		&tags,
+	)
+	if err != nil {
+		return view.FeedView{}, fmt.Errorf("scan feed %d: %w", feedID, err)
+	}
+
+	slog.Info("db get feed", "feed_id", feedID)
+
+	feed := view.BuildFeedView(id, title, originalTitle, url, itemCount, unreadCount, lastChecked, lastError)
+	feed.SetLastErrorAt(lastErrorAt, time.Now())
+	feed.SetCreatedAt(createdAt, time.Now())
+	feed.Description = description.String
+	feed.SiteURL = siteURL.String
+	feed.Language = language.String
+	feed.UserAgent = userAgent.String
+	feed.HTTPProxy = httpProxy.String
+	feed.HTTPSOnly = httpsOnly
+	feed.SuppressDuplicateTitles = suppressDups
+	feed.SummarizeInList = summarize
+	feed.StripLeadingImage = stripImage
+	feed.ImagesOnly = imagesOnly
+	feed.CacheEnclosures = cacheEncl
+	feed.SkipTombstones = skipTombs
+	feed.StableGUIDs = stableGUIDs
+	feed.DigestItems = digestItems
+	feed.Archived = archived
+	feed.MinAgeMinutes = minAge
+	feed.SortByUpdated = sortByUpdated
+	feed.ImageReferrer = imageReferrer.String
+	feed.IngestToken = ingestToken.String
+	feed.Color = color.String
+	feed.Timezone = timezone.String
+	feed.ShareToken = shareToken.String
+	feed.Tags = splitFeedTags(tags)
+
+	if secretURL {
+		feed.MaskSecretURL()
+	}
+
+	return feed, nil
+}
+
+// GetFeedURL is part of the store package API.
+func GetFeedURL(
+	ctx context.Context,
+	db *sql.DB,
+	feedID int64,
+) (string, error) {
+	var u string
+
+	err := db.QueryRowContext(ctx, "SELECT url FROM feeds WHERE id = ?", feedID).Scan(&u)
+	if err != nil {
+		return "", fmt.Errorf("lookup feed URL for %d: %w", feedID, err)
+	}
+
+	return u, nil
+}
+
+// MailboxURLPrefix starts the placeholder URL of a newsletter feed, which is
+// filled by POST /ingest/{token} instead of being fetched.
+const MailboxURLPrefix = "mailbox:"
+
+// SavedFeedURL is the placeholder URL of the feed holding links saved by hand
+// through POST /saved. Its items are kept until they are deleted explicitly.
+const SavedFeedURL = "saved:links"
+
+const savedFeedTitle = "Saved"
+
+// IsMailboxURL reports whether feedURL belongs to a newsletter feed.
+func IsMailboxURL(feedURL string) bool {
+	return strings.HasPrefix(feedURL, MailboxURLPrefix)
+}
+
+// IsLocalFeedURL reports whether feedURL is a placeholder for a feed whose
+// items are added locally rather than fetched.
+func IsLocalFeedURL(feedURL string) bool {
+	return IsMailboxURL(feedURL) || feedURL == SavedFeedURL
+}
+
+// EnsureSavedFeed returns the feed that holds saved links, creating it on
+// first use.
+func EnsureSavedFeed(ctx context.Context, db *sql.DB) (int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	var feedID int64
+
+	err := db.QueryRowContext(ctx, "SELECT id FROM feeds WHERE url = ?", SavedFeedURL).Scan(&feedID)
+	if err == nil {
+		return feedID, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("lookup saved feed: %w", err)
+	}
+
+	return upsertFeed(ctx, db, SavedFeedURL, savedFeedTitle, 0)
+}
+
+// CreateMailboxFeed adds a newsletter feed whose items arrive by email
+// through the ingest endpoint for token.
+func CreateMailboxFeed(ctx context.Context, db *sql.DB, title, token string) (int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	feedID, err := UpsertFeed(ctx, db, MailboxURLPrefix+token, title)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.ExecContext(ctx, "UPDATE feeds SET ingest_token = ? WHERE id = ?", token, feedID)
+	if err != nil {
+		return 0, fmt.Errorf("set ingest token for feed %d: %w", feedID, err)
+	}
+
+	return feedID, nil
+}
+
+// GetFeedIDByIngestToken returns the newsletter feed that token delivers to,
+// or an error wrapping sql.ErrNoRows when no feed uses it. Archived feeds are
+// exempt from EnforceItemLimit, so they stop taking mail rather than grow
+// without bound.
+func GetFeedIDByIngestToken(ctx context.Context, db *sql.DB, token string) (int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	var feedID int64
+
+	err := db.QueryRowContext(ctx,
+		"SELECT id FROM feeds WHERE ingest_token = ? AND archived_at IS NULL", token).Scan(&feedID)
+	if err != nil {
+		return 0, fmt.Errorf("lookup feed by ingest token: %w", err)
+	}
+
+	return feedID, nil
+}
+
+// SetFeedShareToken sets the token that lets anyone read the feed's items at
+// its share page, replacing any earlier one. An empty token stops sharing.
+func SetFeedShareToken(ctx context.Context, db *sql.DB, feedID int64, token string) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx, "UPDATE feeds SET share_token = ? WHERE id = ?", nullString(token), feedID)
+	if err != nil {
+		return fmt.Errorf("set share token for feed %d: %w", feedID, err)
+	}
+
+	return nil
+}
+
+// GetFeedIDByShareToken returns the feed shared under token, or an error
+// wrapping sql.ErrNoRows when no feed is.
+func GetFeedIDByShareToken(ctx context.Context, db *sql.DB, token string) (int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	var feedID int64
+
+	err := db.QueryRowContext(ctx, "SELECT id FROM feeds WHERE share_token = ?", token).Scan(&feedID)
 	if err != nil {
-		return view.FeedView{}, fmt.Errorf("scan feed %d: %w", feedID, err)
+		return 0, fmt.Errorf("lookup feed by share token: %w", err)
 	}
 
-	slog.Info("db get feed", "feed_id", feedID)
+	return feedID, nil
+}
+
+// ArchiveFeed stops refreshing a feed and keeps its items as a read-only
+// archive: they stay listed but count as neither unread nor toward the item
+// limit, and cleanup leaves them alone.
+func ArchiveFeed(ctx context.Context, db *sql.DB, feedID int64) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE feeds SET archived_at = COALESCE(archived_at, ?) WHERE id = ?", time.Now().UTC(), feedID)
+	if err != nil {
+		return fmt.Errorf("archive feed %d: %w", feedID, err)
+	}
 
-	return view.BuildFeedView(id, title, originalTitle, url, itemCount, unreadCount, lastChecked, lastError), nil
+	return nil
 }
 
-// GetFeedURL is part of the store package API.
-func GetFeedURL(
-	ctx context.Context,
-	db *sql.DB,
-	feedID int64,
-) (string, error) {
-	var u string
+// UnarchiveFeed returns an archived feed to the active list and schedules it
+// for an immediate refresh.
+func UnarchiveFeed(ctx context.Context, db *sql.DB, feedID int64) error {
+	ctx = contextOrBackground(ctx)
 
-	err := db.QueryRowContext(ctx, "SELECT url FROM feeds WHERE id = ?", feedID).Scan(&u)
+	_, err := db.ExecContext(ctx,
+		"UPDATE feeds SET archived_at = NULL, next_refresh_at = NULL WHERE id = ? AND archived_at IS NOT NULL", feedID)
 	if err != nil {
-		return "", fmt.Errorf("lookup feed URL for %d: %w", feedID, err)
+		return fmt.Errorf("unarchive feed %d: %w", feedID, err)
 	}
 
-	return u, nil
+	return nil
 }
 
 // ListDueFeeds is part of the store package API.
@@ -645,10 +2533,11 @@ func ListDueFeeds(db *sql.DB, now time.Time, limit int) ([]int64, error) {
 	rows, err := db.QueryContext(context.Background(), `
 	SELECT id
 	FROM feeds
-	WHERE next_refresh_at IS NULL OR next_refresh_at <= ?
+	WHERE ingest_token IS NULL AND url <> ? AND archived_at IS NULL
+	  AND (next_refresh_at IS NULL OR next_refresh_at <= ?)
 	ORDER BY COALESCE(next_refresh_at, created_at)
 	LIMIT ?
-	`, now, limit)
+	`, SavedFeedURL, now, limit)
 	if err != nil {
 		return nil, fmt.Errorf("query due feeds: %w", err)
 	}
@@ -681,20 +2570,57 @@ func ListDueFeeds(db *sql.DB, now time.Time, limit int) ([]int64, error) {
 	return ids, nil
 }
 
+// feedItemSortDate is the date a feed's own list orders the item aliased
+// alias by: when it was published, or, for a feed f set to sort by updated,
+// when the feed last updated it. Items without either fall back to when they
+// were first seen.
+func feedItemSortDate(alias string) string {
+	return `CASE WHEN f.sort_by_updated = 1
+    THEN COALESCE(` + alias + `.updated_at, ` + alias + `.published_at, ` + alias + `.created_at)
+    ELSE COALESCE(` + alias + `.published_at, ` + alias + `.created_at) END`
+}
+
 // ListItems is part of the store package API.
 func ListItems(
 	ctx context.Context,
 	db *sql.DB,
 	feedID int64,
+) ([]view.ItemView, error) {
+	// SQLite reads a negative LIMIT as no limit.
+	return ListRecentItems(ctx, db, feedID, -1)
+}
+
+// ListRecentItems returns at most limit of the feed's newest visible items,
+// in the order ListItems lists them.
+func ListRecentItems(ctx context.Context, db *sql.DB, feedID int64, limit int) ([]view.ItemView, error) {
+	return ListItemsBefore(ctx, db, feedID, 0, limit)
+}
+
+// ListItemsBefore returns at most limit of the feed's visible items that
+// ListItems lists after the item beforeID, or from the newest when beforeID is
+// zero. It keys on the item's date and ID rather than an offset, so items
+// stored between pages do not shift later pages.
+func ListItemsBefore(
+	ctx context.Context,
+	db *sql.DB,
+	feedID int64,
+	beforeID int64,
+	limit int,
 ) ([]view.ItemView, error) {
 	ctx = contextOrBackground(ctx)
 
 	rows, err := db.QueryContext(ctx, `
-SELECT id, title, link, summary, content, published_at, read_at
-FROM items
-WHERE feed_id = ?
-ORDER BY COALESCE(published_at, created_at) DESC, id DESC
-	`, feedID)
+SELECT i.id, i.feed_id, i.title, i.link, i.summary, i.content, i.published_at, i.read_at, i.created_at,
+       i.last_updated_at, f.last_visited_at, f.summarize_in_list, f.language, f.strip_leading_image, i.categories,
+       f.image_referrer, i.enclosure_url IS NOT NULL, i.image_url, i.updated_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.feed_id = ? AND `+visibleItemFilter+`
+  AND (? = 0 OR (`+feedItemSortDate("i")+`, i.id) <
+    (SELECT `+feedItemSortDate("b")+`, b.id FROM items b WHERE b.id = ?))
+ORDER BY `+feedItemSortDate("i")+` DESC, i.id DESC
+LIMIT ?
+	`, feedID, beforeID, beforeID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("query items for feed %d: %w", feedID, err)
 	}
@@ -727,6 +2653,174 @@ ORDER BY COALESCE(published_at, created_at) DESC, id DESC
 	return items, nil
 }
 
+// ListItemsByTag returns the newest items across every feed carrying tag.
+func ListItemsByTag(ctx context.Context, db *sql.DB, tag string) ([]view.ItemView, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT i.id, i.feed_id, i.title, i.link, i.summary, i.content, i.published_at, i.read_at, i.created_at,
+       i.last_updated_at, f.last_visited_at, f.summarize_in_list, f.language, f.strip_leading_image, i.categories,
+       f.image_referrer, i.enclosure_url IS NOT NULL, i.image_url, i.updated_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+JOIN feed_tags ft ON ft.feed_id = i.feed_id
+WHERE ft.tag = ? AND `+visibleItemFilter+`
+ORDER BY COALESCE(i.published_at, i.created_at) DESC, i.id DESC
+LIMIT ?
+	`, tag, MaxItemsPerFeed)
+	if err != nil {
+		return nil, fmt.Errorf("query items for tag %q: %w", tag, err)
+	}
+
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			slog.Warn("rows close failed", "err", closeErr)
+		}
+	}()
+
+	var items []view.ItemView
+
+	for rows.Next() {
+		item, scanErr := scanItemView(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		items = append(items, item)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return nil, fmt.Errorf("iterate items for tag %q: %w", tag, rowsErr)
+	}
+
+	return items, nil
+}
+
+// ListItemsSince returns the unread items published, or first seen when
+// undated, at or after since across every feed. Items come grouped by feed in
+// manual sidebar order, newest first within each feed.
+func ListItemsSince(ctx context.Context, db *sql.DB, since time.Time) ([]view.ItemView, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT i.id, i.feed_id, i.title, i.link, i.summary, i.content, i.published_at, i.read_at, i.created_at,
+       i.last_updated_at, f.last_visited_at, f.summarize_in_list, f.language, f.strip_leading_image, i.categories,
+       f.image_referrer, i.enclosure_url IS NOT NULL, i.image_url, i.updated_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.read_at IS NULL AND COALESCE(i.published_at, i.created_at) >= ? AND `+visibleItemFilter+`
+ORDER BY `+manualFeedOrderBy+`, COALESCE(i.published_at, i.created_at) DESC, i.id DESC
+	`, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query items since %s: %w", since.Format(time.RFC3339), err)
+	}
+	defer closeRows(rows)
+
+	var items []view.ItemView
+
+	for rows.Next() {
+		item, scanErr := scanItemView(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		items = append(items, item)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate items since %s: %w", since.Format(time.RFC3339), err)
+	}
+
+	return items, nil
+}
+
+// ListRecentUnreadItems returns at most limit of the newest unread items
+// across every feed that is not archived, newest first.
+func ListRecentUnreadItems(ctx context.Context, db *sql.DB, limit int) ([]view.ItemView, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT i.id, i.feed_id, i.title, i.link, i.summary, i.content, i.published_at, i.read_at, i.created_at,
+       i.last_updated_at, f.last_visited_at, f.summarize_in_list, f.language, f.strip_leading_image, i.categories,
+       f.image_referrer, i.enclosure_url IS NOT NULL, i.image_url, i.updated_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.read_at IS NULL AND f.archived_at IS NULL AND `+visibleItemFilter+`
+ORDER BY COALESCE(i.published_at, i.created_at) DESC, i.id DESC
+LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query recent unread items: %w", err)
+	}
+	defer closeRows(rows)
+
+	var items []view.ItemView
+
+	for rows.Next() {
+		item, scanErr := scanItemView(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		items = append(items, item)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("iterate recent unread items: %w", err)
+	}
+
+	return items, nil
+}
+
+// ListItemsInCategory returns the feed's items that carry category among their
+// own <category> values, compared case-insensitively.
+func ListItemsInCategory(ctx context.Context, db *sql.DB, feedID int64, category string) ([]view.ItemView, error) {
+	ctx = contextOrBackground(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+SELECT i.id, i.feed_id, i.title, i.link, i.summary, i.content, i.published_at, i.read_at, i.created_at,
+       i.last_updated_at, f.last_visited_at, f.summarize_in_list, f.language, f.strip_leading_image, i.categories,
+       f.image_referrer, i.enclosure_url IS NOT NULL, i.image_url, i.updated_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.feed_id = ? AND instr(lower(',' || i.categories || ','), lower(',' || ? || ',')) > 0
+  AND `+visibleItemFilter+`
+ORDER BY `+feedItemSortDate("i")+` DESC, i.id DESC
+	`, feedID, category)
+	if err != nil {
+		return nil, fmt.Errorf("query items for feed %d in category %q: %w", feedID, category, err)
+	}
+
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			slog.Warn("rows close failed", "err", closeErr)
+		}
+	}()
+
+	var items []view.ItemView
+
+	for rows.Next() {
+		item, scanErr := scanItemView(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		items = append(items, item)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return nil, fmt.Errorf("iterate items for feed %d in category %q: %w", feedID, category, rowsErr)
+	}
+
+	return items, nil
+}
+
 // ListItemsAfter is part of the store package API.
 func ListItemsAfter(
 	ctx context.Context,
@@ -736,10 +2830,13 @@ func ListItemsAfter(
 	ctx = contextOrBackground(ctx)
 
 	rows, err := db.QueryContext(ctx, `
-SELECT id, title, link, summary, content, published_at, read_at
-FROM items
-WHERE feed_id = ? AND id > ?
-ORDER BY COALESCE(published_at, created_at) DESC, id DESC
+SELECT i.id, i.feed_id, i.title, i.link, i.summary, i.content, i.published_at, i.read_at, i.created_at,
+       i.last_updated_at, f.last_visited_at, f.summarize_in_list, f.language, f.strip_leading_image, i.categories,
+       f.image_referrer, i.enclosure_url IS NOT NULL, i.image_url, i.updated_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.feed_id = ? AND i.id > ? AND `+visibleItemFilter+`
+ORDER BY `+feedItemSortDate("i")+` DESC, i.id DESC
 	`, feedID, afterID)
 	if err != nil {
 		return nil, fmt.Errorf("query items for feed %d after %d: %w", feedID, afterID, err)
@@ -793,34 +2890,133 @@ WHERE feed_id = ? AND id > ?
 	return count, nil
 }
 
+// CountUnreadItems returns the number of unread items across every feed.
+func CountUnreadItems(ctx context.Context, db *sql.DB) (int, error) {
+	ctx = contextOrBackground(ctx)
+
+	var count int
+
+	err := db.QueryRowContext(ctx, `
+SELECT COUNT(*)
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.read_at IS NULL AND i.dismissed_at IS NULL AND f.archived_at IS NULL
+  AND `+itemOldEnoughFilter).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count unread items: %w", err)
+	}
+
+	return count, nil
+}
+
+// NewestItemTime returns the latest published time among the feed's stored
+// items, or the zero time when none of them carries a publish date.
+func NewestItemTime(ctx context.Context, db *sql.DB, feedID int64) (time.Time, error) {
+	ctx = contextOrBackground(ctx)
+
+	var published time.Time
+
+	err := db.QueryRowContext(ctx, `
+SELECT published_at
+FROM items
+WHERE feed_id = ? AND published_at IS NOT NULL
+ORDER BY published_at DESC
+LIMIT 1
+	`, feedID).Scan(&published)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("load newest item time for feed %d: %w", feedID, err)
+	}
+
+	return published, nil
+}
+
 // GetItem is part of the store package API.
 func GetItem(ctx context.Context, db *sql.DB, itemID int64) (view.ItemView, error) {
+	return getItem(ctx, db, itemID, "")
+}
+
+// getItem loads an item's view, showing its cached translation into lang
+// instead of the original when one is current. An empty lang never does.
+func getItem(ctx context.Context, db *sql.DB, itemID int64, lang string) (view.ItemView, error) {
 	ctx = contextOrBackground(ctx)
 
 	row := db.QueryRowContext(ctx, `
-SELECT id, title, link, summary, content, published_at, read_at
-FROM items
-WHERE id = ?
+SELECT i.id, i.feed_id, i.title, i.link, i.summary, i.content, i.published_at, i.read_at, i.created_at,
+       i.last_updated_at, f.last_visited_at, f.summarize_in_list, f.language, f.strip_leading_image, i.categories,
+       f.image_referrer, i.enclosure_url IS NOT NULL, i.image_url, i.updated_at,
+       i.translation_lang, i.translated_title, i.translated_content, i.translated_at
+FROM items i
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.id = ?
 `, itemID)
 
 	var (
-		id        int64
-		title     string
-		link      string
-		summary   sql.NullString
-		content   sql.NullString
-		published sql.NullTime
-		readAt    sql.NullTime
+		id          int64
+		feedID      int64
+		title       string
+		link        string
+		summary     sql.NullString
+		body        sql.NullString
+		published   sql.NullTime
+		readAt      sql.NullTime
+		createdAt   time.Time
+		lastUpdated sql.NullTime
+		lastVisited sql.NullTime
+		summarize   bool
+		language    sql.NullString
+		stripImage  bool
+		categories  sql.NullString
+		referrer    sql.NullString
+		enclosure   bool
+		imageURL    sql.NullString
+		updated     sql.NullTime
+		translation itemTranslation
 	)
 
-	err := row.Scan(&id, &title, &link, &summary, &content, &published, &readAt)
+	err := row.Scan(
+		&id, &feedID, &title, &link, &summary, &body, &published, &readAt, &createdAt,
+		&lastUpdated, &lastVisited, &summarize, &language, &stripImage, &categories, &referrer, &enclosure,
+		&imageURL, &updated, &translation.lang, &translation.title, &translation.body, &translation.translatedAt,
+	)
 	if err != nil {
 		return view.ItemView{}, fmt.Errorf("scan item %d: %w", itemID, err)
 	}
 
 	slog.Info("db get item", "item_id", itemID)
 
-	return view.BuildItemView(id, title, link, summary, content, published, readAt), nil
+	translated := translation.fresh(lang, createdAt, lastUpdated)
+	if translated {
+		title = translation.title.String
+		summary = sql.NullString{String: "", Valid: false}
+		body = translation.body
+		language = translation.lang
+	}
+
+	item := view.BuildItemView(
+		id, title, link, summary, body, published, readAt, createdAt, lastUpdated, lastVisited, stripImage,
+		referrer.String,
+	)
+	item.FeedID = feedID
+	item.Language = language.String
+	item.HasEnclosure = enclosure
+	item.ThumbnailURL = content.ProxiedImageURL(imageURL.String, link, referrer.String)
+	item.Categories = view.BuildItemCategories(feedID, categories)
+	item.UpdatedDisplay = view.UpdatedDisplay(published, updated)
+
+	if updated.Valid {
+		item.UpdatedAt = updated.Time.UTC()
+	}
+	item.Translated = translated
+
+	if summarize {
+		item.Preview = view.ItemPreview(summary, body)
+	}
+
+	return item, nil
 }
 
 // GetFeedIDByItem is part of the store package API.
@@ -857,22 +3053,151 @@ func ToggleRead(ctx context.Context, db *sql.DB, itemID int64) error {
 		return nil
 	}
 
-	_, err = db.ExecContext(ctx, "UPDATE items SET read_at = ? WHERE id = ?", time.Now().UTC(), itemID)
+	_, err = db.ExecContext(ctx, "UPDATE items SET read_at = ? WHERE id = ?", time.Now().UTC(), itemID)
+	if err != nil {
+		return fmt.Errorf("mark item %d read: %w", itemID, err)
+	}
+
+	return nil
+}
+
+// MarkRead marks an item read, leaving an existing read time untouched.
+func MarkRead(ctx context.Context, db *sql.DB, itemID int64) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE items SET read_at = ? WHERE id = ? AND read_at IS NULL",
+		time.Now().UTC(),
+		itemID,
+	)
+	if err != nil {
+		return fmt.Errorf("mark item %d read: %w", itemID, err)
+	}
+
+	return nil
+}
+
+// MarkItemsRead marks the listed items of feedID read in one transaction and
+// returns how many were unread. IDs belonging to other feeds are ignored.
+func MarkItemsRead(ctx context.Context, db *sql.DB, feedID int64, itemIDs []int64) (int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin mark items read transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			rollbackTx(tx)
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx,
+		"UPDATE items SET read_at = ? WHERE id = ? AND feed_id = ? AND read_at IS NULL")
+	if err != nil {
+		return 0, fmt.Errorf("prepare mark items read statement: %w", err)
+	}
+
+	defer func() {
+		closeErr := stmt.Close()
+		if closeErr != nil {
+			slog.Warn("stmt close failed", "err", closeErr)
+		}
+	}()
+
+	now := time.Now().UTC()
+
+	var marked int64
+
+	for _, itemID := range itemIDs {
+		var (
+			result   sql.Result
+			affected int64
+		)
+
+		result, err = stmt.ExecContext(ctx, now, itemID, feedID)
+		if err != nil {
+			return 0, fmt.Errorf("mark item %d read: %w", itemID, err)
+		}
+
+		affected, err = result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("count items marked read: %w", err)
+		}
+
+		marked += affected
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("commit mark items read transaction: %w", err)
+	}
+
+	return marked, nil
+}
+
+// DismissItem hides an item from item lists and unread counts without marking
+// it read, so it still counts as unread-but-dismissed rather than read. Like a
+// read item, it is removed by sweeps and read-item cleanup.
+func DismissItem(ctx context.Context, db *sql.DB, itemID int64) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE items SET dismissed_at = ? WHERE id = ? AND dismissed_at IS NULL",
+		time.Now().UTC(),
+		itemID,
+	)
+	if err != nil {
+		return fmt.Errorf("dismiss item %d: %w", itemID, err)
+	}
+
+	return nil
+}
+
+// NextUnreadItem returns the first unread item after itemID in its feed's list
+// order, wrapping around to the top of the list. It returns an error wrapping
+// sql.ErrNoRows when no other unread item remains.
+func NextUnreadItem(ctx context.Context, db *sql.DB, itemID int64) (view.ItemView, error) {
+	ctx = contextOrBackground(ctx)
+
+	var nextID int64
+
+	err := db.QueryRowContext(ctx, `
+WITH current AS (
+	SELECT id, feed_id, COALESCE(published_at, created_at) AS sort_at
+	FROM items
+	WHERE id = ?
+)
+SELECT i.id
+FROM items i
+JOIN current c ON c.feed_id = i.feed_id
+JOIN feeds f ON f.id = i.feed_id
+WHERE i.read_at IS NULL AND i.dismissed_at IS NULL AND i.id <> c.id AND `+itemOldEnoughFilter+`
+ORDER BY
+	COALESCE(i.published_at, i.created_at) > c.sort_at
+		OR (COALESCE(i.published_at, i.created_at) = c.sort_at AND i.id > c.id),
+	COALESCE(i.published_at, i.created_at) DESC,
+	i.id DESC
+LIMIT 1
+`, itemID).Scan(&nextID)
 	if err != nil {
-		return fmt.Errorf("mark item %d read: %w", itemID, err)
+		return view.ItemView{}, fmt.Errorf("find unread item after %d: %w", itemID, err)
 	}
 
-	return nil
+	return GetItem(ctx, db, nextID)
 }
 
-// MarkAllRead is part of the store package API.
+// MarkAllRead marks the feed's unread items read, except those still too new
+// to show, which stay unread until they appear.
 func MarkAllRead(ctx context.Context, db *sql.DB, feedID int64) error {
 	ctx = contextOrBackground(ctx)
 
 	_, err := db.ExecContext(ctx, `
-UPDATE items
+UPDATE items AS i
 SET read_at = ?
-WHERE feed_id = ? AND read_at IS NULL
+WHERE i.feed_id = ? AND i.read_at IS NULL
+  AND EXISTS (SELECT 1 FROM feeds f WHERE f.id = i.feed_id AND `+itemOldEnoughFilter+`)
 	`, time.Now().UTC(), feedID)
 	if err != nil {
 		return fmt.Errorf("mark all items read for feed %d: %w", feedID, err)
@@ -881,6 +3206,42 @@ WHERE feed_id = ? AND read_at IS NULL
 	return nil
 }
 
+// MarkReadAbove marks read the visible items of feedID listed at or above
+// itemID, in the feed's own list order, and returns how many were unread.
+// It fails wrapping sql.ErrNoRows when itemID is not one of the feed's items.
+func MarkReadAbove(ctx context.Context, db *sql.DB, feedID, itemID int64) (int64, error) {
+	ctx = contextOrBackground(ctx)
+
+	var found int
+
+	err := db.QueryRowContext(ctx, "SELECT 1 FROM items WHERE id = ? AND feed_id = ?", itemID, feedID).Scan(&found)
+	if err != nil {
+		return 0, fmt.Errorf("find item %d in feed %d: %w", itemID, feedID, err)
+	}
+
+	result, err := db.ExecContext(ctx, `
+UPDATE items AS i
+SET read_at = ?
+WHERE i.feed_id = ? AND i.read_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM feeds f
+    WHERE f.id = i.feed_id AND `+visibleItemFilter+`
+      AND (`+feedItemSortDate("i")+`, i.id) >=
+        (SELECT `+feedItemSortDate("b")+`, b.id FROM items b WHERE b.id = ?)
+  )
+	`, time.Now().UTC(), feedID, itemID)
+	if err != nil {
+		return 0, fmt.Errorf("mark items above %d read for feed %d: %w", itemID, feedID, err)
+	}
+
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("count items marked read above %d: %w", itemID, err)
+	}
+
+	return marked, nil
+}
+
 // SweepReadItems is part of the store package API.
 func SweepReadItems(ctx context.Context, db *sql.DB, feedID int64) (int64, error) {
 	ctx = contextOrBackground(ctx)
@@ -902,7 +3263,7 @@ func SweepReadItems(ctx context.Context, db *sql.DB, feedID int64) (int64, error
 INSERT OR IGNORE INTO tombstones (feed_id, guid, deleted_at)
 SELECT feed_id, guid, ?
 FROM items
-WHERE feed_id = ? AND read_at IS NOT NULL
+WHERE feed_id = ? AND (read_at IS NOT NULL OR dismissed_at IS NOT NULL)
 	`, now, feedID)
 	if err != nil {
 		return 0, fmt.Errorf("insert sweep tombstones for feed %d: %w", feedID, err)
@@ -910,7 +3271,7 @@ WHERE feed_id = ? AND read_at IS NOT NULL
 
 	deleteResult, err := tx.ExecContext(ctx, `
 DELETE FROM items
-WHERE feed_id = ? AND read_at IS NOT NULL
+WHERE feed_id = ? AND (read_at IS NOT NULL OR dismissed_at IS NOT NULL)
 	`, feedID)
 	if err != nil {
 		return 0, fmt.Errorf("delete read items for feed %d: %w", feedID, err)
@@ -929,11 +3290,13 @@ WHERE feed_id = ? AND read_at IS NOT NULL
 	return deleted, nil
 }
 
-// CleanupReadItems is part of the store package API.
-func CleanupReadItems(db *sql.DB) error {
+// CleanupReadItems deletes items read or dismissed more than readRetention
+// ago. The items in keepItemIDs, such as the ones readers have open, are kept
+// however long ago they were read.
+func CleanupReadItems(db *sql.DB, keepItemIDs []int64) error {
 	cutoff := time.Now().UTC().Add(-readRetention)
 
-	deleted, err := cleanupReadItemsBefore(context.Background(), db, cutoff)
+	deleted, err := cleanupReadItemsBefore(context.Background(), db, cutoff, keepItemIDs)
 	if err != nil {
 		return err
 	}
@@ -943,13 +3306,24 @@ func CleanupReadItems(db *sql.DB) error {
 	return nil
 }
 
-func cleanupReadItemsBefore(ctx context.Context, db *sql.DB, cutoff time.Time) (int64, error) {
+func cleanupReadItemsBefore(ctx context.Context, db *sql.DB, cutoff time.Time, keepItemIDs []int64) (int64, error) {
+	// A nil slice would encode as null, which json_each reads as one NULL
+	// row, and NOT IN a NULL keeps every item.
+	if keepItemIDs == nil {
+		keepItemIDs = []int64{}
+	}
+
+	keep, err := json.Marshal(keepItemIDs)
+	if err != nil {
+		return 0, fmt.Errorf("encode kept item ids: %w", err)
+	}
+
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, fmt.Errorf("begin cleanup read items transaction: %w", err)
 	}
 
-	deleteResult, err := cleanupReadItemsInTx(ctx, tx, cutoff)
+	deleteResult, err := cleanupReadItemsInTx(ctx, tx, cutoff, string(keep))
 	if err != nil {
 		rollbackTx(tx)
 
@@ -969,22 +3343,28 @@ func cleanupReadItemsBefore(ctx context.Context, db *sql.DB, cutoff time.Time) (
 	return deleted, nil
 }
 
-func cleanupReadItemsInTx(ctx context.Context, tx *sql.Tx, cutoff time.Time) (sql.Result, error) {
+// cleanupReadItemsInTx deletes items read or dismissed before cutoff, except
+// those in the saved links feed and archived feeds, which are kept on purpose,
+// and those whose IDs are in the JSON array keep.
+func cleanupReadItemsInTx(ctx context.Context, tx *sql.Tx, cutoff time.Time, keep string) (sql.Result, error) {
 	_, err := tx.ExecContext(ctx, `
 INSERT OR IGNORE INTO tombstones (feed_id, guid, deleted_at)
 SELECT feed_id, guid, ?
 FROM items
-WHERE read_at IS NOT NULL AND read_at <= ?
-	`, time.Now().UTC(), cutoff)
+WHERE COALESCE(read_at, dismissed_at) <= ?
+  AND feed_id NOT IN (SELECT id FROM feeds WHERE url = ? OR archived_at IS NOT NULL)
+  AND id NOT IN (SELECT value FROM json_each(?))
+	`, time.Now().UTC(), cutoff, SavedFeedURL, keep)
 	if err != nil {
 		return nil, fmt.Errorf("insert cleanup tombstones: %w", err)
 	}
 
-	deleteResult, err := tx.ExecContext(
-		ctx,
-		"DELETE FROM items WHERE read_at IS NOT NULL AND read_at <= ?",
-		cutoff,
-	)
+	deleteResult, err := tx.ExecContext(ctx, `
+DELETE FROM items
+WHERE COALESCE(read_at, dismissed_at) <= ?
+  AND feed_id NOT IN (SELECT id FROM feeds WHERE url = ? OR archived_at IS NOT NULL)
+  AND id NOT IN (SELECT value FROM json_each(?))
+	`, cutoff, SavedFeedURL, keep)
 	if err != nil {
 		return nil, fmt.Errorf("delete stale read items: %w", err)
 	}
@@ -1000,23 +3380,216 @@ func logCleanupReadItemsDeleted(deleted int64) {
 	slog.Info("cleanup read items", "deleted", deleted)
 }
 
+// Maintain reclaims free pages left behind by deleted items and truncates the
+// WAL. Free pages are released in small, bounded batches so reads on the
+// shared connection can interleave. Databases created before incremental
+// auto-vacuum was enabled keep their free pages until ConvertToIncrementalVacuum
+// runs, since that needs a full VACUUM.
+func Maintain(ctx context.Context, db *sql.DB) error {
+	ctx = contextOrBackground(ctx)
+
+	before, err := databaseSizeBytes(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	err = reclaimFreePages(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)")
+	if err != nil {
+		return fmt.Errorf("checkpoint wal: %w", err)
+	}
+
+	after, err := databaseSizeBytes(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("database maintenance", "size_bytes", after, "reclaimed_bytes", max(before-after, 0))
+
+	return nil
+}
+
+// ConvertToIncrementalVacuum switches a database created before incremental
+// auto-vacuum was enabled over to it, reporting whether it had to. The switch
+// rewrites the whole file with VACUUM, which holds the only connection for as
+// long as that takes, so it is meant to run at startup before serving.
+func ConvertToIncrementalVacuum(ctx context.Context, db *sql.DB) (bool, error) {
+	ctx = contextOrBackground(ctx)
+
+	mode, err := autoVacuumMode(ctx, db)
+	if err != nil || mode == autoVacuumIncremental {
+		return false, err
+	}
+
+	_, err = db.ExecContext(ctx, "PRAGMA auto_vacuum = INCREMENTAL")
+	if err != nil {
+		return false, fmt.Errorf("enable incremental auto_vacuum: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, "VACUUM")
+	if err != nil {
+		return false, fmt.Errorf("vacuum database: %w", err)
+	}
+
+	return true, nil
+}
+
+func autoVacuumMode(ctx context.Context, db *sql.DB) (int, error) {
+	var mode int
+
+	err := db.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&mode)
+	if err != nil {
+		return 0, fmt.Errorf("read auto_vacuum mode: %w", err)
+	}
+
+	return mode, nil
+}
+
+func reclaimFreePages(ctx context.Context, db *sql.DB) error {
+	mode, err := autoVacuumMode(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if mode != autoVacuumIncremental {
+		slog.Info("database maintenance skipped page reclaim: incremental auto_vacuum is off")
+
+		return nil
+	}
+
+	for range maxIncrementalVacuumSteps {
+		freePages, countErr := freelistCount(ctx, db)
+		if countErr != nil {
+			return countErr
+		}
+
+		if freePages == 0 {
+			return nil
+		}
+
+		err = incrementalVacuum(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		if freePages <= incrementalVacuumStep {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// incrementalVacuum frees at most incrementalVacuumStep pages. SQLite only
+// releases pages as the pragma is stepped, so its rows must be drained.
+func incrementalVacuum(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA incremental_vacuum(%d)", incrementalVacuumStep))
+	if err != nil {
+		return fmt.Errorf("incremental vacuum: %w", err)
+	}
+
+	defer func() {
+		closeErr := rows.Close()
+		if closeErr != nil {
+			slog.Warn("rows close failed", "err", closeErr)
+		}
+	}()
+
+	for rows.Next() {
+		continue
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("incremental vacuum: %w", err)
+	}
+
+	return nil
+}
+
+func freelistCount(ctx context.Context, db *sql.DB) (int64, error) {
+	var count int64
+
+	err := db.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("read freelist count: %w", err)
+	}
+
+	return count, nil
+}
+
+func databaseSizeBytes(ctx context.Context, db *sql.DB) (int64, error) {
+	var pageCount, pageSize int64
+
+	err := db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount)
+	if err != nil {
+		return 0, fmt.Errorf("read page count: %w", err)
+	}
+
+	err = db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize)
+	if err != nil {
+		return 0, fmt.Errorf("read page size: %w", err)
+	}
+
+	return pageCount * pageSize, nil
+}
+
 func scanItemView(rows *sql.Rows) (view.ItemView, error) {
 	var (
-		id        int64
-		title     string
-		link      string
-		summary   sql.NullString
-		content   sql.NullString
-		published sql.NullTime
-		readAt    sql.NullTime
+		id          int64
+		feedID      int64
+		title       string
+		link        string
+		summary     sql.NullString
+		body        sql.NullString
+		published   sql.NullTime
+		readAt      sql.NullTime
+		createdAt   time.Time
+		lastUpdated sql.NullTime
+		lastVisited sql.NullTime
+		summarize   bool
+		language    sql.NullString
+		stripImage  bool
+		categories  sql.NullString
+		referrer    sql.NullString
+		enclosure   bool
+		imageURL    sql.NullString
+		updated     sql.NullTime
 	)
 
-	err := rows.Scan(&id, &title, &link, &summary, &content, &published, &readAt)
+	err := rows.Scan(
+		&id, &feedID, &title, &link, &summary, &body, &published, &readAt, &createdAt,
+		&lastUpdated, &lastVisited, &summarize, &language, &stripImage, &categories, &referrer, &enclosure,
+		&imageURL, &updated,
+	)
 	if err != nil {
 		return view.ItemView{}, fmt.Errorf("scan item row: %w", err)
 	}
 
-	return view.BuildItemView(id, title, link, summary, content, published, readAt), nil
+	item := view.BuildItemView(
+		id, title, link, summary, body, published, readAt, createdAt, lastUpdated, lastVisited, stripImage,
+		referrer.String,
+	)
+	item.FeedID = feedID
+	item.Language = language.String
+	item.HasEnclosure = enclosure
+	item.ThumbnailURL = content.ProxiedImageURL(imageURL.String, link, referrer.String)
+	item.Categories = view.BuildItemCategories(feedID, categories)
+	item.UpdatedDisplay = view.UpdatedDisplay(published, updated)
+
+	if updated.Valid {
+		item.UpdatedAt = updated.Time.UTC()
+	}
+
+	if summarize {
+		item.Preview = view.ItemPreview(summary, body)
+	}
+
+	return item, nil
 }
 
 func scanFeedView(rows *sql.Rows) (view.FeedView, error) {
@@ -1029,14 +3602,27 @@ func scanFeedView(rows *sql.Rows) (view.FeedView, error) {
 		unreadCount   int
 		lastChecked   sql.NullTime
 		lastError     sql.NullString
+		lastErrorAt   sql.NullTime
+		language      sql.NullString
+		createdAt     time.Time
+		color         sql.NullString
+		secretURL     bool
+		timezone      sql.NullString
+		archived      bool
+		errorCount    int
+		errorCode     sql.NullInt64
+		tags          sql.NullString
 	)
 
-	err := rows.Scan(&id, &title, &originalTitle, &url, &itemCount, &unreadCount, &lastChecked, &lastError)
+	err := rows.Scan(
+		&id, &title, &originalTitle, &url, &itemCount, &unreadCount, &lastChecked, &lastError, &lastErrorAt, &language,
+		&createdAt, &color, &secretURL, &timezone, &archived, &errorCount, &errorCode, &tags,
+	)
 	if err != nil {
 		return view.FeedView{}, fmt.Errorf("scan feed row: %w", err)
 	}
 
-	return view.BuildFeedView(
+	feed := view.BuildFeedView(
 		id,
 		title,
 		originalTitle,
@@ -1045,7 +3631,32 @@ func scanFeedView(rows *sql.Rows) (view.FeedView, error) {
 		unreadCount,
 		lastChecked,
 		lastError,
-	), nil
+	)
+	feed.SetLastErrorAt(lastErrorAt, time.Now())
+	feed.SetErrorSeverity(errorCode, errorCount)
+	feed.SetCreatedAt(createdAt, time.Now())
+	feed.Language = language.String
+	feed.Color = color.String
+	feed.Timezone = timezone.String
+	feed.Archived = archived
+	feed.Tags = splitFeedTags(tags)
+
+	if secretURL {
+		feed.MaskSecretURL()
+	}
+
+	return feed, nil
+}
+
+func splitFeedTags(raw sql.NullString) []string {
+	if !raw.Valid || raw.String == "" {
+		return nil
+	}
+
+	tags := strings.Split(raw.String, ",")
+	slices.Sort(tags)
+
+	return tags
 }
 
 func maxItemID(items []view.ItemView) int64 {
@@ -1103,6 +3714,262 @@ SET sort_order = (
 	return nil
 }
 
+func ensureItemLastUpdatedColumn(db *sql.DB) error {
+	var count int
+
+	err := db.QueryRowContext(context.Background(), `
+SELECT COUNT(*)
+FROM pragma_table_info('items')
+WHERE name = 'last_updated_at'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check items.last_updated_at column: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.ExecContext(context.Background(), "ALTER TABLE items ADD COLUMN last_updated_at DATETIME")
+	if err != nil {
+		return fmt.Errorf("add items.last_updated_at column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureItemHasImageColumn adds items.has_image to older databases and
+// backfills it with a textual match, which UpsertItems refines as items are
+// next updated.
+func ensureItemHasImageColumn(db *sql.DB) error {
+	var count int
+
+	err := db.QueryRowContext(context.Background(), `
+SELECT COUNT(*)
+FROM pragma_table_info('items')
+WHERE name = 'has_image'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check items.has_image column: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.ExecContext(context.Background(), "ALTER TABLE items ADD COLUMN has_image INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return fmt.Errorf("add items.has_image column: %w", err)
+	}
+
+	_, err = db.ExecContext(context.Background(), `
+UPDATE items SET has_image = 1 WHERE content LIKE '%<img%' OR summary LIKE '%<img%'
+	`)
+	if err != nil {
+		return fmt.Errorf("backfill items.has_image: %w", err)
+	}
+
+	return nil
+}
+
+func ensureItemCategoriesColumn(db *sql.DB) error {
+	var count int
+
+	err := db.QueryRowContext(context.Background(), `
+SELECT COUNT(*)
+FROM pragma_table_info('items')
+WHERE name = 'categories'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check items.categories column: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.ExecContext(context.Background(), "ALTER TABLE items ADD COLUMN categories TEXT")
+	if err != nil {
+		return fmt.Errorf("add items.categories column: %w", err)
+	}
+
+	return nil
+}
+
+func ensureItemDismissedAtColumn(db *sql.DB) error {
+	var count int
+
+	err := db.QueryRowContext(context.Background(), `
+SELECT COUNT(*)
+FROM pragma_table_info('items')
+WHERE name = 'dismissed_at'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check items.dismissed_at column: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.ExecContext(context.Background(), "ALTER TABLE items ADD COLUMN dismissed_at DATETIME")
+	if err != nil {
+		return fmt.Errorf("add items.dismissed_at column: %w", err)
+	}
+
+	return nil
+}
+
+func ensureFeedColumn(db *sql.DB, column string) error {
+	var count int
+
+	err := db.QueryRowContext(
+		context.Background(),
+		`SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = ?`,
+		column,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds.%s column: %w", column, err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	statement, err := feedAlterColumnStatement(column)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(context.Background(), statement)
+	if err != nil {
+		return fmt.Errorf("add feeds.%s column: %w", column, err)
+	}
+
+	return nil
+}
+
+func feedAlterColumnStatement(column string) (string, error) {
+	switch column {
+	case "description":
+		return "ALTER TABLE feeds ADD COLUMN description TEXT", nil
+	case "site_url":
+		return "ALTER TABLE feeds ADD COLUMN site_url TEXT", nil
+	case "user_agent":
+		return "ALTER TABLE feeds ADD COLUMN user_agent TEXT", nil
+	case "http_proxy":
+		return "ALTER TABLE feeds ADD COLUMN http_proxy TEXT", nil
+	case "https_only":
+		return "ALTER TABLE feeds ADD COLUMN https_only INTEGER NOT NULL DEFAULT 0", nil
+	case "last_visited_at":
+		return "ALTER TABLE feeds ADD COLUMN last_visited_at DATETIME", nil
+	case "suppress_duplicate_titles":
+		return "ALTER TABLE feeds ADD COLUMN suppress_duplicate_titles INTEGER NOT NULL DEFAULT 0", nil
+	case "summarize_in_list":
+		return "ALTER TABLE feeds ADD COLUMN summarize_in_list INTEGER NOT NULL DEFAULT 0", nil
+	case "language":
+		return "ALTER TABLE feeds ADD COLUMN language TEXT", nil
+	case "consecutive_errors":
+		return "ALTER TABLE feeds ADD COLUMN consecutive_errors INTEGER NOT NULL DEFAULT 0", nil
+	case "last_error_code":
+		return "ALTER TABLE feeds ADD COLUMN last_error_code INTEGER", nil
+	case "last_content_at":
+		return "ALTER TABLE feeds ADD COLUMN last_content_at DATETIME", nil
+	case "strip_leading_image":
+		return "ALTER TABLE feeds ADD COLUMN strip_leading_image INTEGER NOT NULL DEFAULT 0", nil
+	case "ingest_token":
+		return "ALTER TABLE feeds ADD COLUMN ingest_token TEXT", nil
+	case "last_error_at":
+		return "ALTER TABLE feeds ADD COLUMN last_error_at DATETIME", nil
+	case "images_only":
+		return "ALTER TABLE feeds ADD COLUMN images_only INTEGER NOT NULL DEFAULT 0", nil
+	case "image_referrer":
+		return "ALTER TABLE feeds ADD COLUMN image_referrer TEXT", nil
+	case "avg_fetch_ms":
+		return "ALTER TABLE feeds ADD COLUMN avg_fetch_ms INTEGER", nil
+	case "color":
+		return "ALTER TABLE feeds ADD COLUMN color TEXT", nil
+	case "cache_enclosures_at":
+		return "ALTER TABLE feeds ADD COLUMN cache_enclosures_at DATETIME", nil
+	case "skip_tombstones":
+		return "ALTER TABLE feeds ADD COLUMN skip_tombstones INTEGER NOT NULL DEFAULT 0", nil
+	case "secret_url":
+		return "ALTER TABLE feeds ADD COLUMN secret_url INTEGER NOT NULL DEFAULT 0", nil
+	case "timezone":
+		return "ALTER TABLE feeds ADD COLUMN timezone TEXT", nil
+	case "share_token":
+		return "ALTER TABLE feeds ADD COLUMN share_token TEXT", nil
+	case "stable_guids":
+		return "ALTER TABLE feeds ADD COLUMN stable_guids INTEGER NOT NULL DEFAULT 0", nil
+	case "digest_items":
+		return "ALTER TABLE feeds ADD COLUMN digest_items INTEGER NOT NULL DEFAULT 0", nil
+	case "archived_at":
+		return "ALTER TABLE feeds ADD COLUMN archived_at DATETIME", nil
+	case "min_age_minutes":
+		return "ALTER TABLE feeds ADD COLUMN min_age_minutes INTEGER NOT NULL DEFAULT 0", nil
+	case "sort_by_updated":
+		return "ALTER TABLE feeds ADD COLUMN sort_by_updated INTEGER NOT NULL DEFAULT 0", nil
+	default:
+		return "", fmt.Errorf("%w %q", errUnsupportedFeedColumn, column)
+	}
+}
+
+func ensureItemColumn(db *sql.DB, column string) error {
+	var count int
+
+	err := db.QueryRowContext(
+		context.Background(),
+		`SELECT COUNT(*) FROM pragma_table_info('items') WHERE name = ?`,
+		column,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check items.%s column: %w", column, err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	statement, err := itemAlterColumnStatement(column)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(context.Background(), statement)
+	if err != nil {
+		return fmt.Errorf("add items.%s column: %w", column, err)
+	}
+
+	return nil
+}
+
+func itemAlterColumnStatement(column string) (string, error) {
+	switch column {
+	case "enclosure_url":
+		return "ALTER TABLE items ADD COLUMN enclosure_url TEXT", nil
+	case "enclosure_type":
+		return "ALTER TABLE items ADD COLUMN enclosure_type TEXT", nil
+	case "enclosure_path":
+		return "ALTER TABLE items ADD COLUMN enclosure_path TEXT", nil
+	case "enclosure_accessed_at":
+		return "ALTER TABLE items ADD COLUMN enclosure_accessed_at DATETIME", nil
+	case "image_url":
+		return "ALTER TABLE items ADD COLUMN image_url TEXT", nil
+	case "translation_lang":
+		return "ALTER TABLE items ADD COLUMN translation_lang TEXT", nil
+	case "translated_title":
+		return "ALTER TABLE items ADD COLUMN translated_title TEXT", nil
+	case "translated_content":
+		return "ALTER TABLE items ADD COLUMN translated_content TEXT", nil
+	case "translated_at":
+		return "ALTER TABLE items ADD COLUMN translated_at DATETIME", nil
+	case "updated_at":
+		return "ALTER TABLE items ADD COLUMN updated_at DATETIME", nil
+	default:
+		return "", fmt.Errorf("%w %q", errUnsupportedItemColumn, column)
+	}
+}
+
 func contextOrBackground(ctx context.Context) context.Context {
 	if ctx == nil {
 		return context.Background()
@@ -4,8 +4,11 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,188 +51,1955 @@ func TestUpsertFeedCustomTitlePreserved(t *testing.T) {
 	}
 }
 
+func TestUpsertFeedStopsAtFeedLimit(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	_, err := EnsureSavedFeed(ctx, db)
+	if err != nil {
+		t.Fatalf("EnsureSavedFeed: %v", err)
+	}
+
+	for _, feedURL := range []string{"http://example.com/one", "http://example.com/two"} {
+		_, err = upsertFeed(ctx, db, feedURL, feedURL, 2)
+		if err != nil {
+			t.Fatalf("upsertFeed %s: %v", feedURL, err)
+		}
+	}
+
+	_, err = upsertFeed(ctx, db, "http://example.com/three", "Three", 2)
+	if !errors.Is(err, ErrFeedLimitReached) {
+		t.Fatalf("expected feed limit error, got %v", err)
+	}
+
+	_, err = upsertFeed(ctx, db, "http://example.com/two", "Two renamed", 2)
+	if err != nil {
+		t.Fatalf("expected an existing feed to update at the limit: %v", err)
+	}
+
+	feeds, err := ListFeeds(ctx, db)
+	if err != nil {
+		t.Fatalf("ListFeeds: %v", err)
+	}
+
+	if len(feeds) != 3 {
+		t.Fatalf("expected two feeds plus the saved feed, got %d", len(feeds))
+	}
+}
+
 func TestUpdateFeedOrderPersistsListOrder(t *testing.T) {
 	t.Parallel()
 
 	db := openTestDB(t)
 
-	firstID := mustUpsertFeed(t, db, "http://example.com/first", "First")
-	secondID := mustUpsertFeed(t, db, "http://example.com/second", "Second")
-	thirdID := mustUpsertFeed(t, db, "http://example.com/third", "Third")
+	firstID := mustUpsertFeed(t, db, "http://example.com/first", "First")
+	secondID := mustUpsertFeed(t, db, "http://example.com/second", "Second")
+	thirdID := mustUpsertFeed(t, db, "http://example.com/third", "Third")
+
+	err := UpdateFeedOrder(context.Background(), db, []int64{thirdID, firstID, secondID})
+	if err != nil {
+		t.Fatalf("UpdateFeedOrder: %v", err)
+	}
+
+	feeds := mustListFeeds(t, db)
+
+	if len(feeds) != 3 {
+		t.Fatalf("expected 3 feeds, got %d", len(feeds))
+	}
+
+	assertFeedOrderIDs(t, feeds, thirdID, firstID, secondID)
+}
+
+func TestInitAddsFeedSortOrderToExistingSchema(t *testing.T) {
+	t.Parallel()
+
+	db := openLegacySchemaDB(t)
+	mustInsertLegacyFeeds(t, db)
+
+	initErr := Init(db)
+	if initErr != nil {
+		t.Fatalf("Init: %v", initErr)
+	}
+
+	assertHasSortOrderColumn(t, db)
+
+	feeds := mustListFeeds(t, db)
+
+	if len(feeds) != 2 {
+		t.Fatalf("expected 2 feeds, got %d", len(feeds))
+	}
+
+	if feeds[0].Title != "Alpha" || feeds[1].Title != "Bravo" {
+		t.Fatalf(
+			"expected legacy feeds to be initialized in title order, got %q then %q",
+			feeds[0].Title,
+			feeds[1].Title,
+		)
+	}
+}
+
+func TestItemLimitAndTombstones(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Feed")
+
+	_, upsertErr := UpsertItems(context.Background(), db, feedID, sequentialItems(210))
+	if upsertErr != nil {
+		t.Fatalf("UpsertItems: %v", upsertErr)
+	}
+
+	enforceErr := EnforceItemLimit(context.Background(), db, feedID)
+	if enforceErr != nil {
+		t.Fatalf("EnforceItemLimit: %v", enforceErr)
+	}
+
+	itemsInDB, err := ListItems(context.Background(), db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	if len(itemsInDB) != 200 {
+		t.Fatalf("expected 200 items, got %d", len(itemsInDB))
+	}
+
+	assertGUIDRangeDeletedAndTombstoned(t, db, feedID, 0, 10)
+}
+
+func TestUpsertItemsDisambiguatesDuplicateGUIDs(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Reused GUID Feed")
+	published := time.Now().UTC().Add(-time.Hour)
+
+	items := []*gofeed.Item{
+		newGofeedItem("First", "http://example.com/1", "same", "<p>One</p>", &published),
+		newGofeedItem("Second", "http://example.com/2", "same", "<p>Two</p>", &published),
+		newGofeedItem("Third", "", "same", "<p>Three</p>", &published),
+		newGofeedItem("First", "http://example.com/1", "same", "<p>One again</p>", &published),
+	}
+
+	inserted, err := UpsertItems(context.Background(), db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	if inserted != 3 {
+		t.Fatalf("expected 3 inserted items, got %d", inserted)
+	}
+
+	for _, guid := range []string{
+		"same", "same#http://example.com/2", "same#" + itemEntryHash(items[2]),
+	} {
+		if !existsByGUID(t, db, feedID, guid) {
+			t.Fatalf("expected item with guid %q", guid)
+		}
+	}
+
+	inserted, err = UpsertItems(context.Background(), db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems repeat: %v", err)
+	}
+
+	if inserted != 0 {
+		t.Fatalf("expected repeat payload to insert nothing, got %d", inserted)
+	}
+}
+
+func TestUpsertItemsKeepsStoredItemOnBareGUID(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Reused GUID Feed")
+	published := time.Now().UTC().Add(-time.Hour)
+
+	original := newGofeedItem("Original", "http://example.com/1", "same", "<p>One</p>", &published)
+	_, err := UpsertItems(context.Background(), db, feedID, []*gofeed.Item{original})
+	if err != nil {
+		t.Fatalf("UpsertItems original: %v", err)
+	}
+
+	newer := published.Add(time.Hour)
+	items := []*gofeed.Item{
+		newGofeedItem("Newcomer", "http://example.com/2", "same", "<p>Two</p>", &newer),
+		original,
+	}
+
+	inserted, err := UpsertItems(context.Background(), db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	if inserted != 1 {
+		t.Fatalf("expected only the newcomer inserted, got %d", inserted)
+	}
+
+	for _, guid := range []string{"same", "same#http://example.com/2"} {
+		if !existsByGUID(t, db, feedID, guid) {
+			t.Fatalf("expected item with guid %q", guid)
+		}
+	}
+
+	edited := []*gofeed.Item{
+		newGofeedItem("Newcomer", "http://example.com/2", "same", "<p>Two, edited</p>", &newer),
+		newGofeedItem("Original", "http://example.com/1", "same", "<p>One, edited</p>", &published),
+		newGofeedItem("Linkless", "", "same", "<p>Three</p>", &published),
+	}
+
+	inserted, err = UpsertItems(context.Background(), db, feedID, edited)
+	if err != nil {
+		t.Fatalf("UpsertItems edited: %v", err)
+	}
+
+	if inserted != 1 {
+		t.Fatalf("expected only the linkless entry inserted, got %d", inserted)
+	}
+
+	edited[2].Description = "<p>Three, edited</p>"
+
+	inserted, err = UpsertItems(context.Background(), db, feedID, edited)
+	if err != nil {
+		t.Fatalf("UpsertItems edited again: %v", err)
+	}
+
+	if inserted != 0 {
+		t.Fatalf("expected edits to update in place, got %d inserted", inserted)
+	}
+
+	var summary string
+
+	err = db.QueryRowContext(context.Background(),
+		"SELECT summary FROM items WHERE feed_id = ? AND guid = ?", feedID, "same").Scan(&summary)
+	if err != nil {
+		t.Fatalf("load original item: %v", err)
+	}
+
+	if summary != "<p>One, edited</p>" {
+		t.Fatalf("expected the stored item updated in place, got %q", summary)
+	}
+}
+
+func TestListFeedsRecentUnreadOrder(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	firstID := mustUpsertFeed(t, db, "http://example.com/first", "First")
+	secondID := mustUpsertFeed(t, db, "http://example.com/second", "Second")
+	thirdID := mustUpsertFeed(t, db, "http://example.com/third", "Third")
+
+	older := time.Now().UTC().Add(-2 * time.Hour)
+	newer := time.Now().UTC().Add(-time.Hour)
+
+	for feedID, published := range map[int64]time.Time{secondID: older, thirdID: newer} {
+		_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{newGofeedItem("Unread", "", "u", "", &published)})
+		if err != nil {
+			t.Fatalf("UpsertItems: %v", err)
+		}
+	}
+
+	order, err := GetFeedOrder(ctx, db)
+	if err != nil || order != FeedOrderManual {
+		t.Fatalf("expected manual default order, got %q (%v)", order, err)
+	}
+
+	assertFeedOrderIDs(t, mustListFeeds(t, db), firstID, secondID, thirdID)
+
+	err = SetFeedOrder(ctx, db, FeedOrderRecentUnread)
+	if err != nil {
+		t.Fatalf("SetFeedOrder: %v", err)
+	}
+
+	assertFeedOrderIDs(t, mustListFeeds(t, db), thirdID, secondID, firstID)
+
+	err = MarkAllRead(ctx, db, thirdID)
+	if err != nil {
+		t.Fatalf("MarkAllRead: %v", err)
+	}
+
+	assertFeedOrderIDs(t, mustListFeeds(t, db), secondID, firstID, thirdID)
+
+	err = SetFeedOrder(ctx, db, "alphabetical")
+	if !errors.Is(err, ErrInvalidFeedOrder) {
+		t.Fatalf("expected ErrInvalidFeedOrder, got %v", err)
+	}
+}
+
+func TestListFeedsOrderedByDateAdded(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	oldestID := mustUpsertFeed(t, db, "http://example.com/oldest", "Oldest")
+	newestID := mustUpsertFeed(t, db, "http://example.com/newest", "Newest")
+	middleID := mustUpsertFeed(t, db, "http://example.com/middle", "Middle")
+
+	now := time.Now().UTC()
+
+	ages := map[int64]time.Duration{oldestID: 72 * time.Hour, newestID: time.Hour, middleID: 24 * time.Hour}
+
+	for feedID, age := range ages {
+		_, err := db.ExecContext(ctx, "UPDATE feeds SET created_at = ? WHERE id = ?", now.Add(-age), feedID)
+		if err != nil {
+			t.Fatalf("set created_at: %v", err)
+		}
+	}
+
+	err := SetFeedOrder(ctx, db, FeedOrderAdded)
+	if err != nil {
+		t.Fatalf("SetFeedOrder: %v", err)
+	}
+
+	feeds := mustListFeeds(t, db)
+	assertFeedOrderIDs(t, feeds, newestID, middleID, oldestID)
+
+	if feeds[2].AddedDisplay != "3d ago" {
+		t.Fatalf("expected oldest feed added 3d ago, got %q", feeds[2].AddedDisplay)
+	}
+
+	feed, err := GetFeed(ctx, db, newestID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+
+	if !feed.CreatedAt.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("expected created_at to be exposed, got %v", feed.CreatedAt)
+	}
+}
+
+func TestRecordFetchDurationKeepsRollingAverage(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "http://example.com/slow", "Slow")
+
+	for _, duration := range []time.Duration{8 * time.Second, 16 * time.Second} {
+		err := RecordFetchDuration(ctx, db, feedID, duration)
+		if err != nil {
+			t.Fatalf("RecordFetchDuration: %v", err)
+		}
+	}
+
+	var avgFetchMillis int64
+
+	err := db.QueryRowContext(ctx, "SELECT avg_fetch_ms FROM feeds WHERE id = ?", feedID).Scan(&avgFetchMillis)
+	if err != nil {
+		t.Fatalf("load avg_fetch_ms: %v", err)
+	}
+
+	if avgFetchMillis != 10000 {
+		t.Fatalf("expected rolling average of 10000ms, got %d", avgFetchMillis)
+	}
+}
+
+func TestNextFeedWithUnreadWrapsAround(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	firstID := mustUpsertFeed(t, db, "http://example.com/first", "First")
+	secondID := mustUpsertFeed(t, db, "http://example.com/second", "Second")
+	thirdID := mustUpsertFeed(t, db, "http://example.com/third", "Third")
+
+	for _, feedID := range []int64{firstID, thirdID} {
+		_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{newGofeedItem("Unread", "", "u", "", nil)})
+		if err != nil {
+			t.Fatalf("UpsertItems: %v", err)
+		}
+	}
+
+	cases := []struct {
+		after    int64
+		expected int64
+	}{
+		{after: 0, expected: firstID},
+		{after: firstID, expected: thirdID},
+		{after: secondID, expected: thirdID},
+		{after: thirdID, expected: firstID},
+	}
+
+	for _, tc := range cases {
+		got, err := NextFeedWithUnread(ctx, db, tc.after)
+		if err != nil {
+			t.Fatalf("NextFeedWithUnread(%d): %v", tc.after, err)
+		}
+
+		if got != tc.expected {
+			t.Fatalf("NextFeedWithUnread(%d) = %d, want %d", tc.after, got, tc.expected)
+		}
+	}
+
+	for _, feedID := range []int64{firstID, thirdID} {
+		err := MarkAllRead(ctx, db, feedID)
+		if err != nil {
+			t.Fatalf("MarkAllRead: %v", err)
+		}
+	}
+
+	got, err := NextFeedWithUnread(ctx, db, firstID)
+	if err != nil {
+		t.Fatalf("NextFeedWithUnread all read: %v", err)
+	}
+
+	if got != 0 {
+		t.Fatalf("expected no unread feed, got %d", got)
+	}
+}
+
+func TestDeleteFeedKeepingHistoryRestoresReadStateOnResubscribe(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedURL := "http://example.com/rss"
+	items := []*gofeed.Item{
+		newGofeedItem("Read", "http://example.com/read", "read", "", nil),
+		newGofeedItem("Unread", "http://example.com/unread", "unread", "", nil),
+		newGofeedItem("Swept", "http://example.com/swept", "swept", "", nil),
+	}
+
+	feedID := mustUpsertFeed(t, db, feedURL, "History Feed")
+
+	_, err := UpsertItems(ctx, db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	_, err = db.ExecContext(ctx,
+		"UPDATE items SET read_at = ? WHERE feed_id = ? AND guid IN ('read', 'swept')",
+		time.Now().UTC(),
+		feedID,
+	)
+	if err != nil {
+		t.Fatalf("set read_at: %v", err)
+	}
+
+	_, err = db.ExecContext(ctx, "DELETE FROM items WHERE feed_id = ? AND guid = 'swept'", feedID)
+	if err != nil {
+		t.Fatalf("delete swept item: %v", err)
+	}
+
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO tombstones (feed_id, guid, deleted_at) VALUES (?, 'swept', ?)",
+		feedID,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		t.Fatalf("insert tombstone: %v", err)
+	}
+
+	err = DeleteFeedKeepingHistory(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("DeleteFeedKeepingHistory: %v", err)
+	}
+
+	newFeedID := mustUpsertFeed(t, db, feedURL, "History Feed")
+
+	_, err = UpsertItems(ctx, db, newFeedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems after resubscribe: %v", err)
+	}
+
+	if existsByGUID(t, db, newFeedID, "swept") {
+		t.Fatal("expected tombstoned item to stay removed after resubscribe")
+	}
+
+	feed, err := GetFeed(ctx, db, newFeedID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+
+	if feed.ItemCount != 2 || feed.UnreadCount != 1 {
+		t.Fatalf("expected 2 items with 1 unread, got %d items and %d unread", feed.ItemCount, feed.UnreadCount)
+	}
+}
+
+func TestDeleteFeedDiscardsReadState(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedURL := "http://example.com/rss"
+	items := []*gofeed.Item{newGofeedItem("Read", "http://example.com/read", "read", "", nil)}
+
+	feedID := mustUpsertFeed(t, db, feedURL, "History Feed")
+
+	_, err := UpsertItems(ctx, db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	err = MarkAllRead(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("MarkAllRead: %v", err)
+	}
+
+	err = DeleteFeedKeepingHistory(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("DeleteFeedKeepingHistory: %v", err)
+	}
+
+	feedID = mustUpsertFeed(t, db, feedURL, "History Feed")
+
+	err = DeleteFeed(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("DeleteFeed: %v", err)
+	}
+
+	feedID = mustUpsertFeed(t, db, feedURL, "History Feed")
+
+	_, err = UpsertItems(ctx, db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems after resubscribe: %v", err)
+	}
+
+	feed, err := GetFeed(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+
+	if feed.UnreadCount != 1 {
+		t.Fatalf("expected destructive delete to discard read state, got %d unread", feed.UnreadCount)
+	}
+}
+
+func TestUpsertItemsKeepsDisambiguatedGUIDsWhenFeedPrepends(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Linkless Feed")
+	published := time.Now().UTC().Add(-time.Hour)
+
+	items := []*gofeed.Item{
+		newGofeedItem("First", "", "same", "<p>One</p>", &published),
+		newGofeedItem("Second", "", "same", "<p>Two</p>", &published),
+	}
+
+	_, err := UpsertItems(context.Background(), db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	_, err = db.ExecContext(context.Background(), "UPDATE items SET read_at = ? WHERE feed_id = ?", published, feedID)
+	if err != nil {
+		t.Fatalf("mark items read: %v", err)
+	}
+
+	newer := published.Add(30 * time.Minute)
+	prepended := append([]*gofeed.Item{newGofeedItem("Zeroth", "", "same", "<p>Zero</p>", &newer)}, items...)
+
+	inserted, err := UpsertItems(context.Background(), db, feedID, prepended)
+	if err != nil {
+		t.Fatalf("UpsertItems prepended: %v", err)
+	}
+
+	if inserted != 1 {
+		t.Fatalf("expected only the prepended item inserted, got %d", inserted)
+	}
+
+	var count, unread int
+
+	err = db.QueryRowContext(context.Background(),
+		"SELECT COUNT(*), COUNT(*) FILTER (WHERE read_at IS NULL) FROM items WHERE feed_id = ?",
+		feedID).Scan(&count, &unread)
+	if err != nil {
+		t.Fatalf("count items: %v", err)
+	}
+
+	if count != 3 || unread != 1 {
+		t.Fatalf("expected only the prepended item added unread, got %d rows with %d unread", count, unread)
+	}
+
+	_, err = UpsertItems(context.Background(), db, feedID, prepended)
+	if err != nil {
+		t.Fatalf("UpsertItems repeat: %v", err)
+	}
+
+	err = db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM items WHERE feed_id = ?", feedID).Scan(&count)
+	if err != nil {
+		t.Fatalf("recount items: %v", err)
+	}
+
+	if count != 3 {
+		t.Fatalf("expected a repeat refresh to add no rows, got %d", count)
+	}
+}
+
+func TestGetItemShowsContentOnceForExcerptSummary(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Excerpt Feed")
+
+	item := newGofeedItem("Vote", "http://example.com/vote",
+		"vote", "<p>The council voted to approve the new transit plan, officials said. "+
+			`<a href="http://example.com/vote">Continue reading</a></p>`, nil)
+	item.Content = "<p>The council voted to approve the new transit plan on Tuesday, officials said.</p>" +
+		"<p>Work begins in May.</p>"
+
+	_, err := UpsertItems(context.Background(), db, feedID, []*gofeed.Item{item})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	items, err := ListItems(context.Background(), db, feedID)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("ListItems: %v (%d items)", err, len(items))
+	}
+
+	got, err := GetItem(context.Background(), db, items[0].ID)
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+
+	body := string(got.SummaryHTML)
+	if strings.Contains(body, "Continue reading") || !strings.Contains(body, "Work begins in May.") ||
+		strings.Count(body, "The council voted") != 1 {
+		t.Fatalf("expected only the content for an excerpt summary, got %q", body)
+	}
+}
+
+func TestSweepReadItems(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+
+	feedID, err := UpsertFeed(context.Background(), db, "http://example.com/rss", "Sweep Feed")
+	if err != nil {
+		t.Fatalf("UpsertFeed: %v", err)
+	}
+
+	_, upsertErr := UpsertItems(context.Background(), db, feedID, []*gofeed.Item{{
+		Title:           "Keep me",
+		Link:            "http://example.com/1",
+		GUID:            "1",
+		Description:     "<p>Summary</p>",
+		PublishedParsed: new(time.Now().Add(-time.Hour)),
+	}, {
+		Title:           "Sweep me A",
+		Link:            "http://example.com/2",
+		GUID:            "2",
+		Description:     "<p>Summary</p>",
+		PublishedParsed: new(time.Now().Add(-2 * time.Hour)),
+	}})
+	if upsertErr != nil {
+		t.Fatalf("UpsertItems: %v", upsertErr)
+	}
+
+	now := time.Now().UTC()
+
+	_, err = db.ExecContext(
+		context.Background(),
+		"UPDATE items SET read_at = ? WHERE feed_id = ? AND guid = ?",
+		now,
+		feedID,
+		"2",
+	)
+	if err != nil {
+		t.Fatalf("set read_at: %v", err)
+	}
+
+	deleted, err := SweepReadItems(context.Background(), db, feedID)
+	if err != nil {
+		t.Fatalf("SweepReadItems: %v", err)
+	}
+
+	if deleted != 1 {
+		t.Fatalf("expected 1 deleted item, got %d", deleted)
+	}
+
+	if existsByGUID(t, db, feedID, "2") {
+		t.Fatal("expected read item to be deleted")
+	}
+
+	if !existsInTombstones(t, db, feedID, "2") {
+		t.Fatal("expected deleted item to be tombstoned")
+	}
+}
+
+func TestSkipTombstonesLetsSweptItemsReturn(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Returning Feed")
+	items := []*gofeed.Item{{Title: "Swept", Link: "http://example.com/1", GUID: "1"}}
+
+	for _, guid := range []string{"before", "1"} {
+		_, err := db.ExecContext(ctx,
+			"INSERT INTO tombstones (feed_id, guid, deleted_at) VALUES (?, ?, ?)", feedID, guid, time.Now().UTC())
+		if err != nil {
+			t.Fatalf("insert tombstone: %v", err)
+		}
+	}
+
+	err := UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{SkipTombstones: true})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	if existsInTombstones(t, db, feedID, "before") {
+		t.Fatal("expected opting out to clear existing tombstones")
+	}
+
+	_, err = UpsertItems(ctx, db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	_, err = db.ExecContext(ctx, "UPDATE items SET read_at = ? WHERE feed_id = ?", time.Now().UTC(), feedID)
+	if err != nil {
+		t.Fatalf("set read_at: %v", err)
+	}
+
+	_, err = SweepReadItems(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("SweepReadItems: %v", err)
+	}
+
+	if existsInTombstones(t, db, feedID, "1") {
+		t.Fatal("expected no tombstone for a feed that skips them")
+	}
+
+	_, err = UpsertItems(ctx, db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	if !existsByGUID(t, db, feedID, "1") {
+		t.Fatal("expected swept item to return on the next refresh")
+	}
+
+	feed, err := GetFeed(ctx, db, feedID)
+	if err != nil || !feed.SkipTombstones {
+		t.Fatalf("expected GetFeed to report skipped tombstones, got %+v (%v)", feed, err)
+	}
+}
+
+func TestStableGUIDsIgnoreChangingTrackingParams(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	published := time.Date(2024, time.March, 4, 8, 0, 0, 0, time.UTC)
+	fetches := [][]*gofeed.Item{
+		{newGofeedItem("Weekly Notes", "https://example.com/notes?utm_source=a", "", "body", &published)},
+		{newGofeedItem("Weekly Notes", "https://example.com/notes?utm_source=b", "", "body", &published)},
+	}
+
+	for _, stable := range []bool{false, true} {
+		db := openTestDB(t)
+		feedID := mustUpsertFeed(t, db, "https://example.com/feed.xml", "Tracked Feed")
+
+		err := UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{StableGUIDs: stable})
+		if err != nil {
+			t.Fatalf("UpdateFeedFetchSettings: %v", err)
+		}
+
+		for _, items := range fetches {
+			_, err = UpsertItems(ctx, db, feedID, items)
+			if err != nil {
+				t.Fatalf("UpsertItems: %v", err)
+			}
+		}
+
+		var count int
+
+		err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM items WHERE feed_id = ?", feedID).Scan(&count)
+		if err != nil {
+			t.Fatalf("count items: %v", err)
+		}
+
+		expected := 2
+		if stable {
+			expected = 1
+		}
+
+		if count != expected {
+			t.Fatalf("stable GUIDs %v: expected %d items, got %d", stable, expected, count)
+		}
+	}
+}
+
+func TestDigestItemsCombineEachDaysNewItems(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "https://example.com/commits.xml", "Commits")
+	morning := time.Date(2024, time.March, 4, 8, 0, 0, 0, time.UTC)
+	noon := morning.Add(4 * time.Hour)
+	nextDay := morning.Add(24 * time.Hour)
+
+	err := UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{DigestItems: true})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	first := []*gofeed.Item{
+		newGofeedItem("Fix parser", "https://example.com/c/1", "c1", "<p>parser</p>", &morning),
+		newGofeedItem("Bump deps", "https://example.com/c/2", "c2", "<p>deps</p>", &noon),
+		newGofeedItem("Tag release", "https://example.com/c/3", "c3", "<p>tag</p>", &nextDay),
+	}
+
+	_, err = UpsertItems(ctx, db, feedID, first)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	items, err := ListItems(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	if len(items) != 2 || items[0].Title != "Updates for March 5, 2024" || items[1].Title != "Updates for March 4, 2024" {
+		t.Fatalf("expected one digest per day, got %+v", items)
+	}
+
+	later := noon.Add(time.Hour)
+
+	_, err = UpsertItems(ctx, db, feedID, append(first, newGofeedItem("Fix typo", "", "c4", "<p>typo</p>", &later)))
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	var summary string
+
+	err = db.QueryRowContext(ctx, "SELECT summary FROM items WHERE guid = 'digest:2024-03-04'").Scan(&summary)
+	if err != nil {
+		t.Fatalf("load digest: %v", err)
+	}
+
+	if summary != "Fix parser; Bump deps; Fix typo" {
+		t.Fatalf("expected later items appended to the day's digest, got %q", summary)
+	}
+
+	err = UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{DigestItems: false})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	_, err = UpsertItems(ctx, db, feedID, []*gofeed.Item{newGofeedItem("Revert", "", "c5", "", &nextDay)})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	items, err = ListItems(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("expected items listed individually once digests are off, got %d", len(items))
+	}
+}
+
+func TestArchivedFeedsKeepItemsOutOfUnreadAndCleanup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "https://example.com/finished.xml", "Finished Series")
+
+	_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{
+		newGofeedItem("Unread part", "https://example.com/1", "1", "", nil),
+		newGofeedItem("Read part", "https://example.com/2", "2", "", nil),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	_, err = db.ExecContext(ctx, "UPDATE items SET read_at = ? WHERE guid = '2'", time.Now().UTC().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+
+	err = ArchiveFeed(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("ArchiveFeed: %v", err)
+	}
+
+	unread, err := CountUnreadItems(ctx, db)
+	if err != nil || unread != 0 {
+		t.Fatalf("expected archived items out of the unread total, got %d (%v)", unread, err)
+	}
+
+	due, err := ListDueFeeds(db, time.Now().UTC(), 10)
+	if err != nil || len(due) != 0 {
+		t.Fatalf("expected archived feed not to refresh, got %v (%v)", due, err)
+	}
+
+	_, err = cleanupReadItemsBefore(ctx, db, time.Now().UTC(), nil)
+	if err != nil {
+		t.Fatalf("cleanupReadItemsBefore: %v", err)
+	}
+
+	if !existsByGUID(t, db, feedID, "2") {
+		t.Fatal("expected cleanup to keep the archived feed's read item")
+	}
+
+	feed, err := GetFeed(ctx, db, feedID)
+	if err != nil || !feed.Archived || feed.UnreadCount != 0 || feed.ItemCount != 2 {
+		t.Fatalf("expected archived feed with both items and no unread, got %+v (%v)", feed, err)
+	}
+
+	err = UnarchiveFeed(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("UnarchiveFeed: %v", err)
+	}
+
+	unread, err = CountUnreadItems(ctx, db)
+	if err != nil || unread != 1 {
+		t.Fatalf("expected unread item counted again after unarchiving, got %d (%v)", unread, err)
+	}
+}
+
+func TestMinAgeHoldsBackNewItems(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "https://example.com/hasty.xml", "Hasty Blog")
+
+	err := UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{MinAgeMinutes: 30})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	hourAgo := time.Now().UTC().Add(-time.Hour)
+	justNow := time.Now().UTC().Add(-time.Minute)
+
+	_, err = UpsertItems(ctx, db, feedID, []*gofeed.Item{
+		newGofeedItem("Settled post", "https://example.com/settled", "settled", "", &hourAgo),
+		newGofeedItem("Fresh post", "https://example.com/fresh", "fresh", "", &justNow),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	items, err := ListItems(ctx, db, feedID)
+	if err != nil || len(items) != 1 || items[0].Title != "Settled post" {
+		t.Fatalf("expected only the settled post listed, got %+v (%v)", items, err)
+	}
+
+	feed, err := GetFeed(ctx, db, feedID)
+	if err != nil || feed.MinAgeMinutes != 30 || feed.UnreadCount != 1 || feed.ItemCount != 2 {
+		t.Fatalf("expected one unread of two stored items, got %+v (%v)", feed, err)
+	}
+
+	unread, err := CountUnreadItems(ctx, db)
+	if err != nil || unread != 1 {
+		t.Fatalf("expected the fresh post out of the unread total, got %d (%v)", unread, err)
+	}
+
+	err = MarkAllRead(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("MarkAllRead: %v", err)
+	}
+
+	err = UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	feed, err = GetFeed(ctx, db, feedID)
+	if err != nil || feed.UnreadCount != 1 {
+		t.Fatalf("expected the held-back post to stay unread once shown, got %+v (%v)", feed, err)
+	}
+}
+
+func TestItemsKeepPublishedAndUpdatedApart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "https://example.com/evergreen.xml", "Evergreen Blog")
+
+	now := time.Now().UTC()
+	oldPublished := now.Add(-72 * time.Hour)
+	recentPublished := now.Add(-24 * time.Hour)
+	republished := now.Add(-time.Hour)
+
+	evergreen := newGofeedItem("Evergreen guide", "https://example.com/guide", "guide", "", &oldPublished)
+	evergreen.UpdatedParsed = &republished
+	news := newGofeedItem("Recent news", "https://example.com/news", "news", "", &recentPublished)
+
+	_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{evergreen, news})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	items, err := ListItems(ctx, db, feedID)
+	if err != nil || len(items) != 2 || items[0].Title != "Recent news" {
+		t.Fatalf("expected items in published order, got %+v (%v)", items, err)
+	}
+
+	if items[1].UpdatedDisplay != view.FormatTime(republished) || items[0].UpdatedDisplay != "" {
+		t.Fatalf("expected only the republished guide to show its update, got %q / %q",
+			items[0].UpdatedDisplay, items[1].UpdatedDisplay)
+	}
+
+	err = UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{SortByUpdated: true})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	items, err = ListItems(ctx, db, feedID)
+	if err != nil || len(items) != 2 || items[0].Title != "Evergreen guide" {
+		t.Fatalf("expected the updated guide first when sorting by update, got %+v (%v)", items, err)
+	}
+
+	// A later <updated> alone, with the text unchanged, still moves the item.
+	updatedAgain := now.Add(-time.Minute)
+	news.UpdatedParsed = &updatedAgain
+
+	_, err = UpsertItems(ctx, db, feedID, []*gofeed.Item{evergreen, news})
+	if err != nil {
+		t.Fatalf("UpsertItems again: %v", err)
+	}
+
+	items, err = ListItems(ctx, db, feedID)
+	if err != nil || len(items) != 2 || items[0].Title != "Recent news" || items[0].IsUpdated {
+		t.Fatalf("expected the news item first after its update, unflagged as edited, got %+v (%v)", items, err)
+	}
+}
+
+func TestSetTombstoneRetention(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Retention Feed")
+	now := time.Now().UTC()
+
+	insertTombstone := func(guid string, deletedAt time.Time) {
+		t.Helper()
+
+		_, err := db.ExecContext(ctx,
+			"INSERT INTO tombstones (feed_id, guid, deleted_at) VALUES (?, ?, ?)", feedID, guid, deletedAt)
+		if err != nil {
+			t.Fatalf("insert tombstone %s: %v", guid, err)
+		}
+	}
+
+	insertTombstone("old", now.Add(-3*time.Hour))
+	insertTombstone("recent", now.Add(-time.Minute))
+
+	err := SetTombstoneRetention(ctx, db, time.Hour)
+	if err != nil {
+		t.Fatalf("SetTombstoneRetention: %v", err)
+	}
+
+	if existsInTombstones(t, db, feedID, "old") || !existsInTombstones(t, db, feedID, "recent") {
+		t.Fatal("expected only tombstones older than the retention to be removed")
+	}
+
+	insertTombstone("older", now.Add(-2*time.Hour))
+
+	if existsInTombstones(t, db, feedID, "older") {
+		t.Fatal("expected the prune trigger to use the new retention")
+	}
+
+	err = SetTombstoneRetention(ctx, db, 0)
+	if err != nil {
+		t.Fatalf("SetTombstoneRetention: %v", err)
+	}
+
+	insertTombstone("new", now)
+
+	if existsInTombstones(t, db, feedID, "recent") || existsInTombstones(t, db, feedID, "new") {
+		t.Fatal("expected zero retention to keep no tombstones")
+	}
+}
+
+func TestFeedHistoryPruneTriggerRemovesOldHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	now := time.Now().UTC()
+
+	insertHistory := func(guid string, deletedAt time.Time) {
+		t.Helper()
+
+		_, err := db.ExecContext(ctx,
+			"INSERT INTO feed_history (feed_url, guid, read_at, deleted_at) VALUES (?, ?, NULL, ?)",
+			"http://example.com/rss", guid, deletedAt)
+		if err != nil {
+			t.Fatalf("insert feed history %s: %v", guid, err)
+		}
+	}
+
+	insertHistory("old", now.Add(-31*24*time.Hour))
+	insertHistory("recent", now.Add(-time.Hour))
+
+	rows, err := db.QueryContext(ctx, "SELECT guid FROM feed_history ORDER BY guid")
+	if err != nil {
+		t.Fatalf("list feed history: %v", err)
+	}
+
+	defer closeRows(rows)
+
+	var guids []string
+
+	for rows.Next() {
+		var guid string
+
+		err = rows.Scan(&guid)
+		if err != nil {
+			t.Fatalf("scan feed history: %v", err)
+		}
+
+		guids = append(guids, guid)
+	}
+
+	if len(guids) != 1 || guids[0] != "recent" {
+		t.Fatalf("expected only history younger than 30 days kept, got %v", guids)
+	}
+}
+
+func TestCleanupReadItems(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+
+	feedID, err := UpsertFeed(context.Background(), db, "http://example.com/rss", "Cleanup Feed")
+	if err != nil {
+		t.Fatalf("UpsertFeed: %v", err)
+	}
+
+	_, upsertErr := UpsertItems(context.Background(), db, feedID, []*gofeed.Item{{
+		Title:           "Old Read",
+		Link:            "http://example.com/old",
+		GUID:            "old",
+		Description:     "<p>Summary</p>",
+		PublishedParsed: new(time.Now().Add(-2 * time.Hour)),
+	}})
+	if upsertErr != nil {
+		t.Fatalf("UpsertItems: %v", upsertErr)
+	}
+
+	past := time.Now().UTC().Add(-31 * time.Minute)
+
+	_, err = db.ExecContext(
+		context.Background(),
+		"UPDATE items SET read_at = ? WHERE feed_id = ? AND guid = ?",
+		past,
+		feedID,
+		"old",
+	)
+	if err != nil {
+		t.Fatalf("set read_at: %v", err)
+	}
+
+	cleanupErr := CleanupReadItems(db, nil)
+	if cleanupErr != nil {
+		t.Fatalf("CleanupReadItems: %v", cleanupErr)
+	}
+
+	if existsByGUID(t, db, feedID, "old") {
+		t.Fatal("expected old read item to be deleted")
+	}
+
+	if !existsInTombstones(t, db, feedID, "old") {
+		t.Fatal("expected old read item to be tombstoned")
+	}
+}
+
+func TestCleanupReadItemsKeepsActiveItems(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Active Feed")
+
+	_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{
+		newGofeedItem("Open", "http://example.com/open", "open", "", nil),
+		newGofeedItem("Done", "http://example.com/done", "done", "", nil),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	readAt := time.Now().UTC().Add(-time.Hour)
+
+	_, err = db.ExecContext(ctx, "UPDATE items SET read_at = ? WHERE feed_id = ?", readAt, feedID)
+	if err != nil {
+		t.Fatalf("set read_at: %v", err)
+	}
+
+	var openID int64
+
+	err = db.QueryRowContext(ctx, "SELECT id FROM items WHERE guid = ?", "open").Scan(&openID)
+	if err != nil {
+		t.Fatalf("load item id: %v", err)
+	}
+
+	err = CleanupReadItems(db, []int64{openID})
+	if err != nil {
+		t.Fatalf("CleanupReadItems: %v", err)
+	}
+
+	if !existsByGUID(t, db, feedID, "open") || existsInTombstones(t, db, feedID, "open") {
+		t.Fatal("expected the active item to be kept and not tombstoned")
+	}
+
+	if existsByGUID(t, db, feedID, "done") {
+		t.Fatal("expected the other read item to be deleted")
+	}
+}
+
+func TestDismissedItemsHiddenUnreadAndCleanedUp(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Dismiss Feed")
+
+	_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{
+		newGofeedItem("Keep", "http://example.com/keep", "keep", "", nil),
+		newGofeedItem("Clickbait", "http://example.com/bait", "bait", "", nil),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	var baitID int64
+
+	err = db.QueryRowContext(ctx, "SELECT id FROM items WHERE guid = ?", "bait").Scan(&baitID)
+	if err != nil {
+		t.Fatalf("load item id: %v", err)
+	}
+
+	err = DismissItem(ctx, db, baitID)
+	if err != nil {
+		t.Fatalf("DismissItem: %v", err)
+	}
+
+	items, err := ListItems(ctx, db, feedID)
+	if err != nil || len(items) != 1 || items[0].Title != "Keep" {
+		t.Fatalf("expected only the kept item listed, got %+v (%v)", items, err)
+	}
+
+	unread, err := CountUnreadItems(ctx, db)
+	if err != nil || unread != 1 {
+		t.Fatalf("expected dismissed item left out of the unread count, got %d (%v)", unread, err)
+	}
+
+	bait, err := GetItem(ctx, db, baitID)
+	if err != nil || bait.IsRead {
+		t.Fatalf("expected dismissed item to stay unread, got %+v (%v)", bait, err)
+	}
+
+	dismissedAt := time.Now().UTC().Add(-31 * time.Minute)
+
+	_, err = db.ExecContext(ctx, "UPDATE items SET dismissed_at = ? WHERE id = ?", dismissedAt, baitID)
+	if err != nil {
+		t.Fatalf("backdate dismissed_at: %v", err)
+	}
+
+	err = CleanupReadItems(db, nil)
+	if err != nil {
+		t.Fatalf("CleanupReadItems: %v", err)
+	}
+
+	if existsByGUID(t, db, feedID, "bait") || !existsByGUID(t, db, feedID, "keep") {
+		t.Fatal("expected cleanup to delete the dismissed item and keep the unread one")
+	}
+}
+
+func TestCleanupReadItemsKeepsSavedLinks(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+
+	savedID, err := EnsureSavedFeed(context.Background(), db)
+	if err != nil {
+		t.Fatalf("EnsureSavedFeed: %v", err)
+	}
+
+	againID, err := EnsureSavedFeed(context.Background(), db)
+	if err != nil || againID != savedID {
+		t.Fatalf("expected saved feed %d to be reused, got %d (%v)", savedID, againID, err)
+	}
+
+	_, err = UpsertItems(context.Background(), db, savedID, []*gofeed.Item{
+		newGofeedItem("Saved", "https://example.com/saved", "https://example.com/saved", "", nil),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	_, err = db.ExecContext(context.Background(), "UPDATE items SET read_at = ? WHERE feed_id = ?",
+		time.Now().UTC().Add(-time.Hour), savedID)
+	if err != nil {
+		t.Fatalf("set read_at: %v", err)
+	}
+
+	err = CleanupReadItems(db, nil)
+	if err != nil {
+		t.Fatalf("CleanupReadItems: %v", err)
+	}
+
+	if !existsByGUID(t, db, savedID, "https://example.com/saved") {
+		t.Fatal("expected read saved link to be kept")
+	}
+
+	due, err := ListDueFeeds(db, time.Now().UTC(), 10)
+	if err != nil {
+		t.Fatalf("ListDueFeeds: %v", err)
+	}
+
+	if slices.Contains(due, savedID) {
+		t.Fatal("expected saved feed to never be due for refresh")
+	}
+}
+
+func TestMaintainReclaimsFreePages(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Maintain Feed")
+
+	items := make([]*gofeed.Item, 0, MaxItemsPerFeed)
+	for i := range MaxItemsPerFeed {
+		guid := fmt.Sprintf("item-%d", i)
+		items = append(items, newGofeedItem(guid, "http://example.com/"+guid, guid, strings.Repeat("x", 4096), nil))
+	}
+
+	_, err := UpsertItems(context.Background(), db, feedID, items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	err = DeleteFeed(context.Background(), db, feedID)
+	if err != nil {
+		t.Fatalf("DeleteFeed: %v", err)
+	}
+
+	before, err := databaseSizeBytes(context.Background(), db)
+	if err != nil {
+		t.Fatalf("databaseSizeBytes: %v", err)
+	}
+
+	err = Maintain(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Maintain: %v", err)
+	}
+
+	freePages, err := freelistCount(context.Background(), db)
+	if err != nil {
+		t.Fatalf("freelistCount: %v", err)
+	}
+
+	if freePages != 0 {
+		t.Fatalf("expected no free pages after maintenance, got %d", freePages)
+	}
+
+	after, err := databaseSizeBytes(context.Background(), db)
+	if err != nil {
+		t.Fatalf("databaseSizeBytes: %v", err)
+	}
+
+	if after >= before {
+		t.Fatalf("expected database to shrink, before=%d after=%d", before, after)
+	}
+}
+
+func TestMaintainLeavesLegacyDatabaseToExplicitConversion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite", path+"?_pragma=auto_vacuum(0)")
+	if err != nil {
+		t.Fatalf("open legacy database: %v", err)
+	}
+
+	_, err = legacy.ExecContext(ctx, "CREATE TABLE legacy (id INTEGER PRIMARY KEY)")
+	if err != nil {
+		t.Fatalf("create legacy table: %v", err)
+	}
+
+	err = legacy.Close()
+	if err != nil {
+		t.Fatalf("close legacy database: %v", err)
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	t.Cleanup(func() {
+		closeErr := db.Close()
+		if closeErr != nil {
+			t.Errorf("db.Close: %v", closeErr)
+		}
+	})
+
+	err = Init(db)
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	err = Maintain(ctx, db)
+	if err != nil {
+		t.Fatalf("Maintain: %v", err)
+	}
+
+	if mode, modeErr := autoVacuumMode(ctx, db); modeErr != nil || mode == autoVacuumIncremental {
+		t.Fatalf("expected maintenance to leave the auto_vacuum mode alone, got %d (%v)", mode, modeErr)
+	}
+
+	converted, err := ConvertToIncrementalVacuum(ctx, db)
+	if err != nil || !converted {
+		t.Fatalf("expected the legacy database to be converted, got %v (%v)", converted, err)
+	}
+
+	if mode, modeErr := autoVacuumMode(ctx, db); modeErr != nil || mode != autoVacuumIncremental {
+		t.Fatalf("expected incremental auto_vacuum after conversion, got %d (%v)", mode, modeErr)
+	}
+
+	converted, err = ConvertToIncrementalVacuum(ctx, db)
+	if err != nil || converted {
+		t.Fatalf("expected a converted database to be left alone, got %v (%v)", converted, err)
+	}
+}
+
+func TestOpenWithPragmasAppliesSettings(t *testing.T) {
+	t.Parallel()
+
+	db, err := OpenWithPragmas(filepath.Join(t.TempDir(), "pragmas.db"), Pragmas{
+		Synchronous:   "full",
+		CacheSizeKiB:  4096,
+		MmapSizeBytes: 0,
+	})
+	if err != nil {
+		t.Fatalf("OpenWithPragmas: %v", err)
+	}
+
+	t.Cleanup(func() {
+		closeErr := db.Close()
+		if closeErr != nil {
+			t.Errorf("db.Close: %v", closeErr)
+		}
+	})
+
+	const synchronousFull = 2
+
+	want := map[string]int64{
+		"synchronous": synchronousFull,
+		"cache_size":  -4096,
+		"mmap_size":   0,
+	}
+
+	for pragma, wantValue := range want {
+		var got int64
+
+		err = db.QueryRowContext(context.Background(), "PRAGMA "+pragma).Scan(&got)
+		if err != nil {
+			t.Fatalf("PRAGMA %s: %v", pragma, err)
+		}
+
+		if got != wantValue {
+			t.Fatalf("PRAGMA %s = %d, want %d", pragma, got, wantValue)
+		}
+	}
+}
+
+func TestUpsertItemsSuppressesDuplicateTitlesWhenEnabled(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	quietID := mustUpsertFeed(t, db, "http://example.com/quiet.xml", "Quiet Feed")
+	loudID := mustUpsertFeed(t, db, "http://example.com/loud.xml", "Loud Feed")
+
+	err := UpdateFeedFetchSettings(ctx, db, quietID, FeedFetchSettings{SuppressDuplicateTitles: true})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	for _, feedID := range []int64{quietID, loudID} {
+		_, err = UpsertItems(ctx, db, feedID, []*gofeed.Item{
+			newGofeedItem("Breaking", "http://example.com/a", "a", "", nil),
+		})
+		if err != nil {
+			t.Fatalf("UpsertItems first: %v", err)
+		}
+
+		// Simulate the original being read and cleaned up before the repost.
+		_, err = db.ExecContext(ctx, "DELETE FROM items WHERE feed_id = ?", feedID)
+		if err != nil {
+			t.Fatalf("delete items: %v", err)
+		}
+
+		_, err = UpsertItems(ctx, db, feedID, []*gofeed.Item{
+			newGofeedItem("Breaking", "http://example.com/b", "b", "", nil),
+			newGofeedItem("Fresh", "http://example.com/c", "c", "", nil),
+		})
+		if err != nil {
+			t.Fatalf("UpsertItems repost: %v", err)
+		}
+	}
+
+	assertUnreadTitles(t, db, quietID, "Fresh")
+	assertUnreadTitles(t, db, loudID, "Breaking,Fresh")
+}
+
+func assertUnreadTitles(t *testing.T, db *sql.DB, feedID int64, want string) {
+	t.Helper()
+
+	items, err := ListItems(context.Background(), db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	var unread []string
+
+	for _, item := range items {
+		if !item.IsRead {
+			unread = append(unread, item.Title)
+		}
+	}
+
+	slices.Sort(unread)
+
+	if got := strings.Join(unread, ","); got != want {
+		t.Fatalf("feed %d unread titles = %q, want %q", feedID, got, want)
+	}
+}
+
+func TestListItemsIncludesPreviewWhenSummarizing(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Preview Feed")
+
+	_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{
+		newGofeedItem("Item", "http://example.com/1", "1", "<p>Short <b>body</b></p>", nil),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	items, err := ListItems(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Preview != "" {
+		t.Fatalf("expected no preview before enabling, got %+v", items)
+	}
+
+	err = UpdateFeedFetchSettings(ctx, db, feedID, FeedFetchSettings{SummarizeInList: true})
+	if err != nil {
+		t.Fatalf("UpdateFeedFetchSettings: %v", err)
+	}
+
+	items, err = ListItems(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Preview != "Short body" {
+		t.Fatalf("expected plain-text preview, got %+v", items)
+	}
+
+	feed, err := GetFeed(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+
+	if !feed.SummarizeInList {
+		t.Fatal("expected feed to report summarize in list")
+	}
+}
+
+func TestUpsertItemsFlagsEditedItems(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Edited Feed")
+
+	upsert := func(description string) {
+		t.Helper()
 
-	err := UpdateFeedOrder(context.Background(), db, []int64{thirdID, firstID, secondID})
+		_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{
+			newGofeedItem("Item", "http://example.com/1", "1", description, nil),
+		})
+		if err != nil {
+			t.Fatalf("UpsertItems: %v", err)
+		}
+	}
+
+	upsert("<p>Original</p>")
+	upsert("<p>Original</p>")
+
+	// Backdate the insert so the edit lands strictly after it.
+	_, err := db.ExecContext(ctx, "UPDATE items SET created_at = ?", time.Now().UTC().Add(-time.Hour))
 	if err != nil {
-		t.Fatalf("UpdateFeedOrder: %v", err)
+		t.Fatalf("backdate item: %v", err)
 	}
 
-	feeds := mustListFeeds(t, db)
+	items, err := ListItems(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
 
-	if len(feeds) != 3 {
-		t.Fatalf("expected 3 feeds, got %d", len(feeds))
+	if len(items) != 1 || items[0].IsUpdated {
+		t.Fatalf("expected unchanged item not to be flagged, got %+v", items)
 	}
 
-	assertFeedOrderIDs(t, feeds, thirdID, firstID, secondID)
+	err = ToggleRead(ctx, db, items[0].ID)
+	if err != nil {
+		t.Fatalf("ToggleRead: %v", err)
+	}
+
+	upsert("<p>Corrected</p>")
+
+	item, err := GetItem(ctx, db, items[0].ID)
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+
+	if !item.IsUpdated || !item.IsRead {
+		t.Fatalf("expected edited item to be flagged and stay read, got %+v", item)
+	}
+
+	if !strings.Contains(string(item.SummaryHTML), "Corrected") {
+		t.Fatalf("expected edited summary to be stored, got %q", item.SummaryHTML)
+	}
 }
 
-func TestInitAddsFeedSortOrderToExistingSchema(t *testing.T) {
+func TestUpdateFeedDetailsStoresLanguage(t *testing.T) {
 	t.Parallel()
 
-	db := openLegacySchemaDB(t)
-	mustInsertLegacyFeeds(t, db)
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Language Feed")
 
-	initErr := Init(db)
-	if initErr != nil {
-		t.Fatalf("Init: %v", initErr)
+	err := UpdateFeedDetails(ctx, db, feedID, "Nachrichten", "http://example.com", "de-DE")
+	if err != nil {
+		t.Fatalf("UpdateFeedDetails: %v", err)
 	}
 
-	assertHasSortOrderColumn(t, db)
+	_, err = UpsertItems(ctx, db, feedID, []*gofeed.Item{
+		newGofeedItem("Hallo", "http://example.com/1", "1", "", nil),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
 
-	feeds := mustListFeeds(t, db)
+	feed, err := GetFeed(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
 
-	if len(feeds) != 2 {
-		t.Fatalf("expected 2 feeds, got %d", len(feeds))
+	feeds, err := ListFeeds(ctx, db)
+	if err != nil {
+		t.Fatalf("ListFeeds: %v", err)
 	}
 
-	if feeds[0].Title != "Alpha" || feeds[1].Title != "Bravo" {
-		t.Fatalf(
-			"expected legacy feeds to be initialized in title order, got %q then %q",
-			feeds[0].Title,
-			feeds[1].Title,
-		)
+	items, err := ListItems(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	if feed.Language != "de-DE" || len(feeds) != 1 || feeds[0].Language != "de-DE" {
+		t.Fatalf("expected feed language de-DE, got %q / %+v", feed.Language, feeds)
+	}
+
+	if len(items) != 1 || items[0].Language != "de-DE" {
+		t.Fatalf("expected item language de-DE, got %+v", items)
+	}
+
+	err = UpdateFeedDetails(ctx, db, feedID, "", "", "")
+	if err != nil {
+		t.Fatalf("UpdateFeedDetails clear: %v", err)
+	}
+
+	feed, err = GetFeed(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+
+	if feed.Language != "" {
+		t.Fatalf("expected empty language when undeclared, got %q", feed.Language)
 	}
 }
 
-func TestItemLimitAndTombstones(t *testing.T) {
+func TestNextUnreadItemFollowsListOrderAndWraps(t *testing.T) {
 	t.Parallel()
 
 	db := openTestDB(t)
-	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Feed")
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Next Feed")
+	base := time.Now().UTC().Add(-time.Hour)
+
+	_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{
+		newGofeedItem("Newest", "http://example.com/1", "1", "", new(base)),
+		newGofeedItem("Middle", "http://example.com/2", "2", "", new(base.Add(-time.Minute))),
+		newGofeedItem("Oldest", "http://example.com/3", "3", "", new(base.Add(-2*time.Minute))),
+	})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
 
-	_, upsertErr := UpsertItems(context.Background(), db, feedID, sequentialItems(210))
-	if upsertErr != nil {
-		t.Fatalf("UpsertItems: %v", upsertErr)
+	items, err := ListItems(ctx, db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
 	}
 
-	enforceErr := EnforceItemLimit(context.Background(), db, feedID)
-	if enforceErr != nil {
-		t.Fatalf("EnforceItemLimit: %v", enforceErr)
+	newest, middle, oldest := items[0], items[1], items[2]
+
+	next, err := NextUnreadItem(ctx, db, newest.ID)
+	if err != nil || next.ID != middle.ID {
+		t.Fatalf("expected middle after newest, got %d (%v)", next.ID, err)
 	}
 
-	itemsInDB, err := ListItems(context.Background(), db, feedID)
+	next, err = NextUnreadItem(ctx, db, oldest.ID)
+	if err != nil || next.ID != newest.ID {
+		t.Fatalf("expected wrap to newest after oldest, got %d (%v)", next.ID, err)
+	}
+
+	for _, item := range items {
+		err = MarkRead(ctx, db, item.ID)
+		if err != nil {
+			t.Fatalf("MarkRead: %v", err)
+		}
+	}
+
+	_, err = NextUnreadItem(ctx, db, newest.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected no rows once all items are read, got %v", err)
+	}
+}
+
+func TestFeedTags(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "http://example.com/rss", "Tagged Feed")
+
+	for _, tag := range []string{"Security", "tech", "tech"} {
+		err := AddFeedTag(ctx, db, feedID, tag)
+		if err != nil {
+			t.Fatalf("AddFeedTag(%q): %v", tag, err)
+		}
+	}
+
+	err := AddFeedTag(ctx, db, feedID, "no spaces!")
+	if !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected ErrInvalidTag, got %v", err)
+	}
+
+	feed, err := GetFeed(ctx, db, feedID)
 	if err != nil {
-		t.Fatalf("ListItems: %v", err)
+		t.Fatalf("GetFeed: %v", err)
 	}
 
-	if len(itemsInDB) != 200 {
-		t.Fatalf("expected 200 items, got %d", len(itemsInDB))
+	if got := strings.Join(feed.Tags, ","); got != "security,tech" {
+		t.Fatalf("expected sorted unique tags, got %q", got)
 	}
 
-	assertGUIDRangeDeletedAndTombstoned(t, db, feedID, 0, 10)
+	err = RemoveFeedTag(ctx, db, feedID, "TECH")
+	if err != nil {
+		t.Fatalf("RemoveFeedTag: %v", err)
+	}
+
+	feeds, err := ListFeedsByTag(ctx, db, "tech")
+	if err != nil {
+		t.Fatalf("ListFeedsByTag: %v", err)
+	}
+
+	if len(feeds) != 0 {
+		t.Fatalf("expected no feeds tagged tech, got %d", len(feeds))
+	}
+
+	feeds, err = ListFeedsByTag(ctx, db, "security")
+	if err != nil {
+		t.Fatalf("ListFeedsByTag: %v", err)
+	}
+
+	if len(feeds) != 1 || feeds[0].ID != feedID {
+		t.Fatalf("expected tagged feed, got %+v", feeds)
+	}
 }
 
-func TestSweepReadItems(t *testing.T) {
+func TestNormalizeTag(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"  Tech ":                           "tech",
+		"Machine Learning":                  "machine-learning",
+		"c_sharp":                           "c_sharp",
+		"caf\u00e9":                         "caf\u00e9",
+		"":                                  "",
+		"a,b":                               "",
+		strings.Repeat("x", maxTagLength+1): "",
+	}
+
+	for raw, want := range cases {
+		got, ok := NormalizeTag(raw)
+		if got != want || ok != (want != "") {
+			t.Fatalf("NormalizeTag(%q) = %q, %v; want %q", raw, got, ok, want)
+		}
+	}
+}
+
+func TestSetFeedColor(t *testing.T) {
 	t.Parallel()
 
 	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "https://example.com/feed.xml", "Feed")
 
-	feedID, err := UpsertFeed(context.Background(), db, "http://example.com/rss", "Sweep Feed")
+	err := SetFeedColor(context.Background(), db, feedID, " Teal ")
 	if err != nil {
-		t.Fatalf("UpsertFeed: %v", err)
+		t.Fatalf("SetFeedColor: %v", err)
 	}
 
-	_, upsertErr := UpsertItems(context.Background(), db, feedID, []*gofeed.Item{{
-		Title:           "Keep me",
-		Link:            "http://example.com/1",
-		GUID:            "1",
-		Description:     "<p>Summary</p>",
-		PublishedParsed: new(time.Now().Add(-time.Hour)),
-	}, {
-		Title:           "Sweep me A",
-		Link:            "http://example.com/2",
-		GUID:            "2",
-		Description:     "<p>Summary</p>",
-		PublishedParsed: new(time.Now().Add(-2 * time.Hour)),
-	}})
-	if upsertErr != nil {
-		t.Fatalf("UpsertItems: %v", upsertErr)
+	feeds := mustListFeeds(t, db)
+	if feeds[0].Color != "teal" {
+		t.Fatalf("expected normalized color teal, got %q", feeds[0].Color)
 	}
 
-	now := time.Now().UTC()
+	err = SetFeedColor(context.Background(), db, feedID, "#ff0000")
+	if !errors.Is(err, ErrInvalidFeedColor) {
+		t.Fatalf("expected ErrInvalidFeedColor, got %v", err)
+	}
 
-	_, err = db.ExecContext(
-		context.Background(),
-		"UPDATE items SET read_at = ? WHERE feed_id = ? AND guid = ?",
-		now,
-		feedID,
-		"2",
-	)
+	err = SetFeedColor(context.Background(), db, feedID, "")
 	if err != nil {
-		t.Fatalf("set read_at: %v", err)
+		t.Fatalf("clear color: %v", err)
 	}
 
-	deleted, err := SweepReadItems(context.Background(), db, feedID)
+	feed, err := GetFeed(context.Background(), db, feedID)
 	if err != nil {
-		t.Fatalf("SweepReadItems: %v", err)
+		t.Fatalf("GetFeed: %v", err)
 	}
 
-	if deleted != 1 {
-		t.Fatalf("expected 1 deleted item, got %d", deleted)
+	if feed.Color != "" {
+		t.Fatalf("expected cleared color, got %q", feed.Color)
 	}
+}
 
-	if existsByGUID(t, db, feedID, "2") {
-		t.Fatal("expected read item to be deleted")
+func TestFeedTimezoneAppliesToZonelessDates(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "https://example.com/feed.xml", "Feed")
+
+	err := SetFeedTimezone(context.Background(), db, feedID, "Mars/Olympus")
+	if !errors.Is(err, ErrInvalidFeedTimezone) {
+		t.Fatalf("expected ErrInvalidFeedTimezone, got %v", err)
 	}
 
-	if !existsInTombstones(t, db, feedID, "2") {
-		t.Fatal("expected deleted item to be tombstoned")
+	err = SetFeedTimezone(context.Background(), db, feedID, " America/New_York ")
+	if err != nil {
+		t.Fatalf("SetFeedTimezone: %v", err)
+	}
+
+	wallClock := time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC)
+	naive := newGofeedItem("Naive", "https://example.com/naive", "naive", "", &wallClock)
+	naive.Published = "2024-01-02 10:00:00"
+	dated := newGofeedItem("Dated", "https://example.com/dated", "dated", "", &wallClock)
+	dated.Published = "Tue, 02 Jan 2024 10:00:00 +0000"
+
+	_, err = UpsertItems(context.Background(), db, feedID, []*gofeed.Item{naive, dated})
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	expected := map[string]time.Time{
+		"naive": wallClock.Add(5 * time.Hour),
+		"dated": wallClock,
+	}
+
+	for guid, want := range expected {
+		var published time.Time
+
+		err = db.QueryRowContext(context.Background(),
+			"SELECT published_at FROM items WHERE guid = ?", guid).Scan(&published)
+		if err != nil {
+			t.Fatalf("read %s published_at: %v", guid, err)
+		}
+
+		if !published.Equal(want) {
+			t.Fatalf("expected %s published at %s, got %s", guid, want, published)
+		}
+	}
+
+	feed, err := GetFeed(context.Background(), db, feedID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+
+	if feed.Timezone != "America/New_York" {
+		t.Fatalf("expected saved timezone, got %q", feed.Timezone)
 	}
 }
 
-func TestCleanupReadItems(t *testing.T) {
+func TestVerifyConsistencyRemovesOrphans(t *testing.T) {
 	t.Parallel()
 
 	db := openTestDB(t)
+	ctx := context.Background()
+	feedID := mustUpsertFeed(t, db, "https://example.com/feed.xml", "Feed")
 
-	feedID, err := UpsertFeed(context.Background(), db, "http://example.com/rss", "Cleanup Feed")
+	_, err := UpsertItems(ctx, db, feedID, []*gofeed.Item{newGofeedItem("Live", "", "live", "", nil)})
 	if err != nil {
-		t.Fatalf("UpsertFeed: %v", err)
+		t.Fatalf("UpsertItems: %v", err)
 	}
 
-	_, upsertErr := UpsertItems(context.Background(), db, feedID, []*gofeed.Item{{
-		Title:           "Old Read",
-		Link:            "http://example.com/old",
-		GUID:            "old",
-		Description:     "<p>Summary</p>",
-		PublishedParsed: new(time.Now().Add(-2 * time.Hour)),
-	}})
-	if upsertErr != nil {
-		t.Fatalf("UpsertItems: %v", upsertErr)
+	// Write the rows foreign keys would reject, as an older database might hold.
+	for _, stmt := range []string{
+		"PRAGMA foreign_keys = OFF",
+		"INSERT INTO items (feed_id, guid, title, link, created_at) VALUES (999, 'gone', 'Gone', '', datetime('now'))",
+		"INSERT INTO tombstones (feed_id, guid, deleted_at) VALUES (999, 'gone', datetime('now'))",
+		"INSERT INTO feed_tags (feed_id, tag) VALUES (999, 'news')",
+		"PRAGMA foreign_keys = ON",
+	} {
+		_, err = db.ExecContext(ctx, stmt)
+		if err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
 	}
 
-	past := time.Now().UTC().Add(-31 * time.Minute)
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO tombstones (feed_id, guid, deleted_at) VALUES (?, 'live', datetime('now'))", feedID)
+	if err != nil {
+		t.Fatalf("insert shadowed tombstone: %v", err)
+	}
 
-	_, err = db.ExecContext(
-		context.Background(),
-		"UPDATE items SET read_at = ? WHERE feed_id = ? AND guid = ?",
-		past,
-		feedID,
-		"old",
-	)
+	report, err := VerifyConsistency(ctx, db)
 	if err != nil {
-		t.Fatalf("set read_at: %v", err)
+		t.Fatalf("VerifyConsistency: %v", err)
 	}
 
-	cleanupErr := CleanupReadItems(db)
-	if cleanupErr != nil {
-		t.Fatalf("CleanupReadItems: %v", cleanupErr)
+	want := ConsistencyReport{OrphanItems: 1, OrphanTombstones: 1, ShadowedTombstones: 1, OrphanTags: 1}
+	if report != want {
+		t.Fatalf("expected %+v, got %+v", want, report)
 	}
 
-	if existsByGUID(t, db, feedID, "old") {
-		t.Fatal("expected old read item to be deleted")
+	if !existsByGUID(t, db, feedID, "live") {
+		t.Fatal("expected the live item to be kept")
 	}
 
-	if !existsInTombstones(t, db, feedID, "old") {
-		t.Fatal("expected old read item to be tombstoned")
+	report, err = VerifyConsistency(ctx, db)
+	if err != nil || report.Total() != 0 {
+		t.Fatalf("expected a clean second pass, got %+v (%v)", report, err)
 	}
 }
 
@@ -449,3 +2219,79 @@ func newGofeedItem(title, link, guid, description string, published *time.Time)
 
 	return item
 }
+
+func TestItemImageURLPrefersMediaRSS(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := gofeed.NewParser().ParseString(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+  <title>Media Feed</title>
+  <id>urn:media-feed</id>
+  <entry>
+    <title>Grouped thumbnail</title>
+    <id>urn:grouped</id>
+    <content type="html">&lt;img src="https://example.com/inline.png"&gt;</content>
+    <media:group>
+      <media:thumbnail url="https://i.example.com/grouped.jpg" width="480" height="360"/>
+    </media:group>
+  </entry>
+  <entry>
+    <title>Media content</title>
+    <id>urn:content</id>
+    <media:content url="https://example.com/clip.mp4" type="video/mp4"/>
+    <media:content url="https://example.com/photo.jpg" medium="image"/>
+  </entry>
+  <entry>
+    <title>Inline only</title>
+    <id>urn:inline</id>
+    <summary type="html">&lt;p&gt;Hi&lt;/p&gt;&lt;img src="https://example.com/inline.png"&gt;</summary>
+  </entry>
+  <entry>
+    <title>No image</title>
+    <id>urn:none</id>
+    <summary>Plain text</summary>
+  </entry>
+</feed>`)
+	if err != nil {
+		t.Fatalf("parse feed: %v", err)
+	}
+
+	want := []string{
+		"https://i.example.com/grouped.jpg",
+		"https://example.com/photo.jpg",
+		"https://example.com/inline.png",
+		"",
+	}
+
+	for idx, item := range parsed.Items {
+		got := itemImageURL(item, strings.TrimSpace(item.Description), strings.TrimSpace(item.Content))
+		if got != want[idx] {
+			t.Fatalf("item %q: expected image %q, got %q", item.Title, want[idx], got)
+		}
+	}
+
+	db := openTestDB(t)
+	feedID := mustUpsertFeed(t, db, "https://example.com/media.xml", "Media Feed")
+
+	_, err = UpsertItems(context.Background(), db, feedID, parsed.Items)
+	if err != nil {
+		t.Fatalf("UpsertItems: %v", err)
+	}
+
+	items, err := ListItems(context.Background(), db, feedID)
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+
+	thumbnails := 0
+
+	for _, item := range items {
+		if strings.HasPrefix(item.ThumbnailURL, "/image-proxy?url=") {
+			thumbnails++
+		}
+	}
+
+	if thumbnails != 3 {
+		t.Fatalf("expected three proxied thumbnails, got %+v", items)
+	}
+}
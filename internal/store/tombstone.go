@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// DefaultTombstoneRetention is how long a removed item's tombstone keeps the
+// feed from storing it again, unless SetTombstoneRetention picks another.
+const DefaultTombstoneRetention = 30 * 24 * time.Hour
+
+// ensureTombstoneSkipTrigger drops tombstones for feeds with skip_tombstones
+// set as they are written, so every path that removes items honors the flag.
+func ensureTombstoneSkipTrigger(db *sql.DB) error {
+	_, err := db.ExecContext(context.Background(), `
+CREATE TRIGGER IF NOT EXISTS tombstones_skip_feed
+BEFORE INSERT ON tombstones
+WHEN (SELECT skip_tombstones FROM feeds WHERE id = NEW.feed_id) = 1
+BEGIN
+	SELECT RAISE(IGNORE);
+END`)
+	if err != nil {
+		return fmt.Errorf("create tombstone skip trigger: %w", err)
+	}
+
+	return nil
+}
+
+// SetTombstoneRetention recreates the tombstones_prune trigger to keep
+// tombstones for retention and removes those already older. A retention of
+// zero keeps none, so removed items come back on the feed's next refresh.
+func SetTombstoneRetention(ctx context.Context, db *sql.DB, retention time.Duration) error {
+	ctx = contextOrBackground(ctx)
+	retention = max(retention, 0)
+	window := fmt.Sprintf("-%d seconds", int64(retention.Seconds()))
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tombstone retention transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			rollbackTx(tx)
+		}
+	}()
+
+	// Trigger bodies cannot take bound parameters; window is built from an
+	// integer above, never from user text. deleted_at holds Go's time format,
+	// which datetime() only parses once the zone suffix is cut; it is UTC.
+	for _, statement := range []string{
+		`DROP TRIGGER IF EXISTS tombstones_prune`,
+		`
+CREATE TRIGGER tombstones_prune
+AFTER INSERT ON tombstones
+BEGIN
+	DELETE FROM tombstones
+	WHERE datetime(substr(deleted_at, 1, 19)) <= datetime('now', '` + window + `');
+END`,
+	} {
+		_, err = tx.ExecContext(ctx, statement)
+		if err != nil {
+			return fmt.Errorf("replace tombstone prune trigger: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+DELETE FROM tombstones
+WHERE deleted_at <= ?`, time.Now().UTC().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("prune expired tombstones: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit tombstone retention transaction: %w", err)
+	}
+
+	slog.Info("db tombstone retention", "retention", retention)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"rss/internal/content"
+	"rss/internal/view"
+)
+
+// itemTranslation is the translation cached on an item, if any.
+type itemTranslation struct {
+	lang         sql.NullString
+	title        sql.NullString
+	body         sql.NullString
+	translatedAt sql.NullTime
+}
+
+// fresh reports whether the cached translation is into lang and was made
+// after the item's last edit, so a changed item is translated again.
+func (t itemTranslation) fresh(lang string, createdAt time.Time, lastUpdated sql.NullTime) bool {
+	if lang == "" || t.lang.String != lang || !t.translatedAt.Valid {
+		return false
+	}
+
+	editedAt := createdAt
+	if lastUpdated.Valid {
+		editedAt = lastUpdated.Time
+	}
+
+	return !t.translatedAt.Time.Before(editedAt)
+}
+
+// GetItemTranslationSource returns the text a translation of the item starts
+// from: its title and its content, or its summary when it has no content.
+func GetItemTranslationSource(ctx context.Context, db *sql.DB, itemID int64) (string, string, error) {
+	ctx = contextOrBackground(ctx)
+
+	var title, body string
+
+	err := db.QueryRowContext(ctx, `
+SELECT title, COALESCE(NULLIF(TRIM(content), ''), summary, '')
+FROM items
+WHERE id = ?
+	`, itemID).Scan(&title, &body)
+	if err != nil {
+		return "", "", fmt.Errorf("load item %d for translation: %w", itemID, err)
+	}
+
+	return title, content.UnwrapXHTML(body), nil
+}
+
+// SaveItemTranslation caches a translation of the item into lang, replacing
+// any earlier one. The body comes from an outside service, so it is
+// sanitized like an email body before it is stored.
+func SaveItemTranslation(ctx context.Context, db *sql.DB, itemID int64, lang, title, body string) error {
+	ctx = contextOrBackground(ctx)
+
+	_, err := db.ExecContext(ctx, `
+UPDATE items
+SET translation_lang = ?, translated_title = ?, translated_content = ?, translated_at = ?
+WHERE id = ?
+	`, lang, strings.TrimSpace(title), content.SanitizeHTML(body), time.Now().UTC(), itemID)
+	if err != nil {
+		return fmt.Errorf("save translation of item %d: %w", itemID, err)
+	}
+
+	return nil
+}
+
+// GetTranslatedItem returns the item like GetItem, with its title and body
+// replaced by the cached translation into lang. Translated is false when no
+// current translation into lang is cached, and the item is then untranslated.
+func GetTranslatedItem(ctx context.Context, db *sql.DB, itemID int64, lang string) (view.ItemView, error) {
+	return getItem(ctx, db, itemID, lang)
+}
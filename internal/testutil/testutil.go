@@ -21,8 +21,9 @@ var errUnexpectedFeedURL = errors.New("unexpected feed url")
 
 // FeedServer serves mutable feed XML for HTTP-based tests.
 type FeedServer struct {
-	feedXML string
-	mu      sync.RWMutex
+	feedXML  string
+	redirect string
+	mu       sync.RWMutex
 }
 
 var (
@@ -76,6 +77,15 @@ func (f *FeedServer) SetFeedXML(xml string) {
 	f.feedXML = xml
 }
 
+// SetRedirect makes this test feed server answer with a redirect to location
+// instead of its XML. An empty location serves the XML again.
+func (f *FeedServer) SetRedirect(location string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.redirect = location
+}
+
 func installFeedTransport() {
 	feedTransportOnce.Do(func() {
 		feedTransportBase = http.DefaultTransport
@@ -99,6 +109,13 @@ func installFeedTransport() {
 				resp.Body = io.NopCloser(strings.NewReader(server.feedXML))
 				resp.Request = req
 
+				if server.redirect != "" {
+					resp.StatusCode = http.StatusFound
+					resp.Status = "302 Found"
+					resp.Header = http.Header{"Location": []string{server.redirect}}
+					resp.Body = http.NoBody
+				}
+
 				return resp, nil
 			}
 
@@ -0,0 +1,144 @@
+// Package translate sends item text to a LibreTranslate-compatible HTTP
+// endpoint for on-demand translation.
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// maxResponseBytes bounds how much of the endpoint's answer is read; an
+	// item body is far smaller.
+	maxResponseBytes = 4 << 20
+	// maxErrorBytes bounds how much of a failed response ends up in the error.
+	maxErrorBytes = 256
+	// translatedTexts is how many texts each request sends: title and body.
+	translatedTexts = 2
+)
+
+var (
+	// ErrInvalidLanguage reports a target that is not a language code such as
+	// "en" or "pt-BR".
+	ErrInvalidLanguage = errors.New("invalid target language")
+	errBadResponse     = errors.New("translation endpoint returned an unexpected response")
+	errEndpointFailed  = errors.New("translation endpoint failed")
+)
+
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{2,4})?$`)
+
+// Client translates through one endpoint, such as a LibreTranslate server's
+// /translate URL.
+type Client struct {
+	client   *http.Client
+	endpoint string
+	apiKey   string
+}
+
+// translateRequest is LibreTranslate's request body. Sending the title and
+// body together as a list keeps a translation to a single call.
+type translateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type translateResponse struct {
+	TranslatedText []string `json:"translatedText"`
+	Error          string   `json:"error"`
+}
+
+// NewClient returns a client for endpoint, sending apiKey with each request
+// when it is set.
+func NewClient(endpoint, apiKey string) *Client {
+	client := new(Client)
+	client.client = new(http.Client)
+	client.client.Timeout = requestTimeout
+	client.endpoint = endpoint
+	client.apiKey = apiKey
+
+	return client
+}
+
+// ValidLanguage reports whether lang is a language code Translate accepts.
+func ValidLanguage(lang string) bool {
+	return languagePattern.MatchString(lang)
+}
+
+// Translate returns title, plain text, and body, HTML, translated into
+// target. The source language is detected by the endpoint.
+func (c *Client) Translate(ctx context.Context, target, title, body string) (string, string, error) {
+	if !ValidLanguage(target) {
+		return "", "", fmt.Errorf("%w %q", ErrInvalidLanguage, target)
+	}
+
+	// Both texts go as HTML, so the title is escaped on the way out and
+	// unescaped on the way back.
+	payload, err := json.Marshal(translateRequest{
+		Q:      []string{html.EscapeString(title), body},
+		Source: "auto",
+		Target: target,
+		Format: "html",
+		APIKey: c.apiKey,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("encode translation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", "", fmt.Errorf("build translation request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("send translation request: %w", err)
+	}
+
+	defer func() {
+		closeErr := resp.Body.Close()
+		if closeErr != nil {
+			slog.Warn("translation response close failed", "err", closeErr)
+		}
+	}()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return "", "", fmt.Errorf("read translation response: %w", err)
+	}
+
+	var decoded translateResponse
+
+	decodeErr := json.Unmarshal(raw, &decoded)
+
+	if resp.StatusCode != http.StatusOK {
+		detail := decoded.Error
+		if decodeErr != nil || detail == "" {
+			detail = strings.TrimSpace(string(raw[:min(len(raw), maxErrorBytes)]))
+		}
+
+		return "", "", fmt.Errorf("%w: %s: %s", errEndpointFailed, resp.Status, detail)
+	}
+
+	if decodeErr != nil || len(decoded.TranslatedText) != translatedTexts {
+		return "", "", errBadResponse
+	}
+
+	return html.UnescapeString(decoded.TranslatedText[0]), decoded.TranslatedText[1], nil
+}
@@ -0,0 +1,75 @@
+package translate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rss/internal/translate"
+)
+
+func TestTranslateSendsTitleAndBodyTogether(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&got)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"translatedText":["Cats &amp; dogs","<p>Hello</p>"]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := translate.NewClient(server.URL, "secret")
+
+	title, body, err := client.Translate(context.Background(), "en", "Katzen & Hunde", "<p>Hallo</p>")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	if title != "Cats & dogs" || body != "<p>Hello</p>" {
+		t.Fatalf("unexpected translation %q / %q", title, body)
+	}
+
+	if got["target"] != "en" || got["source"] != "auto" || got["format"] != "html" || got["api_key"] != "secret" {
+		t.Fatalf("unexpected request %v", got)
+	}
+
+	texts, ok := got["q"].([]any)
+	if !ok || len(texts) != 2 || texts[0] != "Katzen &amp; Hunde" || texts[1] != "<p>Hallo</p>" {
+		t.Fatalf("expected escaped title and body sent together, got %v", got["q"])
+	}
+}
+
+func TestTranslateReportsEndpointErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"Invalid API key"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := translate.NewClient(server.URL, "")
+
+	_, _, err := client.Translate(context.Background(), "en", "Titel", "Text")
+	if err == nil || !strings.Contains(err.Error(), "Invalid API key") {
+		t.Fatalf("expected the endpoint's error, got %v", err)
+	}
+
+	_, _, err = client.Translate(context.Background(), "english", "Titel", "Text")
+	if !errors.Is(err, translate.ErrInvalidLanguage) {
+		t.Fatalf("expected invalid language error, got %v", err)
+	}
+}
@@ -2,10 +2,16 @@
 package view
 
 import (
+	"cmp"
 	"database/sql"
 	"fmt"
 	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"rss/internal/content"
@@ -14,9 +20,86 @@ import (
 const (
 	hoursPerDay = 24
 	daysPerYear = 365
+
+	// previewLength is the rune budget for list previews.
+	previewLength = 300
+
+	// wordsPerMinute is the reading speed behind ReadingTime estimates.
+	wordsPerMinute = 200
+
+	// DefaultUnreadBadgeCap is the largest unread count shown as-is in the
+	// feed list; larger counts render as "999+".
+	DefaultUnreadBadgeCap = 999
 )
 
-// BuildFeedView builds a FeedView from feed row values.
+// Feed health table sort keys accepted by SortFeedHealth.
+const (
+	HealthSortTitle   = "title"
+	HealthSortChecked = "checked"
+	HealthSortContent = "content"
+	HealthSortErrors  = "errors"
+	HealthSortNext    = "next"
+	HealthSortRate    = "rate"
+	HealthSortAdded   = "added"
+)
+
+// Feed error severities set by SetErrorSeverity, from least to most urgent.
+// A transient error, such as a timeout or a 5xx, usually clears on its own;
+// an auth error needs the reader to fix access; a gone feed should be removed.
+const (
+	FeedErrorTransient = "transient"
+	FeedErrorAuth      = "auth"
+	FeedErrorGone      = "gone"
+
+	// goneAfterErrors is how many failed fetches in a row turn a 404 from
+	// a passing glitch into a feed that has gone away.
+	goneAfterErrors = 5
+)
+
+var (
+	unreadBadgeCap    atomic.Int64
+	translationTarget atomic.Value
+)
+
+// SetUnreadBadgeCap sets the largest unread count shown as-is in feed badges.
+// A non-positive limit restores DefaultUnreadBadgeCap.
+func SetUnreadBadgeCap(limit int) {
+	unreadBadgeCap.Store(int64(limit))
+}
+
+// SetTranslationTarget sets the language expanded items offer a translation
+// into. Empty, the default, offers none.
+func SetTranslationTarget(lang string) {
+	translationTarget.Store(lang)
+}
+
+func currentTranslationTarget() string {
+	lang, _ := translationTarget.Load().(string)
+
+	return lang
+}
+
+// FormatUnreadCount renders an unread count for a badge, showing counts above
+// limit as "limit+" so large numbers do not stretch the sidebar.
+func FormatUnreadCount(count, limit int) string {
+	if limit > 0 && count > limit {
+		return strconv.Itoa(limit) + "+"
+	}
+
+	return strconv.Itoa(count)
+}
+
+func currentUnreadBadgeCap() int {
+	limit := int(unreadBadgeCap.Load())
+	if limit <= 0 {
+		return DefaultUnreadBadgeCap
+	}
+
+	return limit
+}
+
+// BuildFeedView builds a FeedView from feed row values. UnreadCount keeps the
+// true count for sorting; UnreadDisplay is capped for rendering.
 func BuildFeedView(
 	id int64,
 	title string,
@@ -42,14 +125,194 @@ func BuildFeedView(
 		Title:              title,
 		OriginalTitle:      originalTitle,
 		URL:                url,
+		URLDisplay:         url,
 		ItemCount:          itemCount,
 		UnreadCount:        unreadCount,
+		UnreadDisplay:      FormatUnreadCount(unreadCount, currentUnreadBadgeCap()),
 		LastRefreshDisplay: refreshDisplay,
 		LastError:          errText,
 	}
 }
 
-// BuildItemView builds an ItemView from item row values.
+// SetLastErrorAt records when the feed's current run of errors began, so a
+// freshly broken feed can be told apart from one that has been down for days.
+func (f *FeedView) SetLastErrorAt(lastErrorAt sql.NullTime, now time.Time) {
+	if !lastErrorAt.Valid {
+		return
+	}
+
+	f.LastErrorAt = lastErrorAt.Time
+	f.FailingSince = "failing since " + FormatRelativeShort(lastErrorAt.Time, now) + " ago"
+}
+
+// SetErrorSeverity classifies the feed's current error by the HTTP status it
+// failed with and how many fetches in a row have failed, so the feed list can
+// show how urgent it is. A feed without an error has no severity.
+func (f *FeedView) SetErrorSeverity(errorCode sql.NullInt64, consecutiveErrors int) {
+	f.ErrorSeverity = ""
+	f.ErrorHint = ""
+
+	if f.LastError == "" {
+		return
+	}
+
+	status := int(errorCode.Int64)
+
+	switch {
+	case status == http.StatusGone,
+		status == http.StatusNotFound && consecutiveErrors >= goneAfterErrors:
+		f.ErrorSeverity = FeedErrorGone
+		f.ErrorHint = fmt.Sprintf("Feed is gone (HTTP %d); consider removing it", status)
+	case status == http.StatusUnauthorized, status == http.StatusForbidden,
+		status == http.StatusProxyAuthRequired:
+		f.ErrorSeverity = FeedErrorAuth
+		f.ErrorHint = fmt.Sprintf("Access denied (HTTP %d); check the feed's URL, user agent, or proxy", status)
+	default:
+		f.ErrorSeverity = FeedErrorTransient
+		f.ErrorHint = "Refresh failing; will retry"
+	}
+}
+
+// MaskSecretURL hides a feed URL that embeds a secret token: URLDisplay keeps
+// only its scheme and host, and the URL is cut out of LastError, which quotes
+// it when a fetch fails. URL itself is left for fetching.
+func (f *FeedView) MaskSecretURL() {
+	f.SecretURL = true
+	f.URLDisplay = "(hidden)"
+
+	if parsed, err := url.Parse(f.URL); err == nil && parsed.Host != "" {
+		f.URLDisplay = parsed.Scheme + "://" + parsed.Host + "/\u2026"
+	}
+
+	if f.URL != "" {
+		f.LastError = strings.ReplaceAll(f.LastError, f.URL, f.URLDisplay)
+	}
+}
+
+// ItemIDs lists the IDs of the rendered items, comma-separated, so the page
+// can mark exactly the items it has shown read once they have all been seen.
+func (d *ItemListData) ItemIDs() string {
+	ids := make([]string, 0, len(d.Items))
+	for _, item := range d.Items {
+		ids = append(ids, strconv.FormatInt(item.ID, 10))
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// SetCreatedAt records when the feed was subscribed to, with a relative
+// "3d ago" display for the health table.
+func (f *FeedView) SetCreatedAt(createdAt, now time.Time) {
+	f.CreatedAt = createdAt
+	f.AddedDisplay = FormatRelativeShort(createdAt, now) + " ago"
+}
+
+// BuildFeedHealthView builds a FeedHealthView from a feed and its refresh
+// bookkeeping, formatting each timestamp relative to now.
+func BuildFeedHealthView(
+	feed FeedView,
+	lastChecked, lastContent, nextRefresh sql.NullTime,
+	consecutiveErrors int,
+	errorCode sql.NullInt64,
+	itemsPerDay float64,
+) FeedHealthView {
+	now := time.Now()
+	health := FeedHealthView{
+		Feed:              feed,
+		LastChecked:       "Never",
+		LastContent:       "Never",
+		NextRefresh:       "Not scheduled",
+		ItemsPerDay:       strconv.FormatFloat(itemsPerDay, 'f', 1, 64),
+		ItemsPerDayRate:   itemsPerDay,
+		ConsecutiveErrors: consecutiveErrors,
+	}
+
+	if lastChecked.Valid {
+		health.LastCheckedAt = lastChecked.Time
+		health.LastChecked = FormatRelativeShort(lastChecked.Time, now) + " ago"
+	}
+
+	if lastContent.Valid {
+		health.LastContentAt = lastContent.Time
+		health.LastContent = FormatRelativeShort(lastContent.Time, now) + " ago"
+	}
+
+	if nextRefresh.Valid {
+		health.NextRefreshAt = nextRefresh.Time
+		health.NextRefresh = "Due"
+
+		if nextRefresh.Time.After(now) {
+			health.NextRefresh = "in " + FormatRelativeShort(now, nextRefresh.Time)
+		}
+	}
+
+	if errorCode.Valid {
+		health.ErrorCode = strconv.FormatInt(errorCode.Int64, 10)
+	}
+
+	return health
+}
+
+// SortFeedHealth orders the health table by key, putting the rows most likely
+// to need attention first: stale checks and content, failing feeds, and the
+// noisiest feeds, or the newest subscriptions first. It returns the key applied, or "" for an unknown key, which
+// leaves the rows in sidebar order.
+func SortFeedHealth(rows []FeedHealthView, key string) string {
+	var compare func(a, b FeedHealthView) int
+
+	switch key {
+	case HealthSortTitle:
+		compare = func(a, b FeedHealthView) int {
+			return strings.Compare(strings.ToLower(a.Feed.Title), strings.ToLower(b.Feed.Title))
+		}
+	case HealthSortChecked:
+		compare = func(a, b FeedHealthView) int { return a.LastCheckedAt.Compare(b.LastCheckedAt) }
+	case HealthSortContent:
+		compare = func(a, b FeedHealthView) int { return a.LastContentAt.Compare(b.LastContentAt) }
+	case HealthSortErrors:
+		compare = func(a, b FeedHealthView) int { return cmp.Compare(b.ConsecutiveErrors, a.ConsecutiveErrors) }
+	case HealthSortNext:
+		compare = func(a, b FeedHealthView) int { return a.NextRefreshAt.Compare(b.NextRefreshAt) }
+	case HealthSortRate:
+		compare = func(a, b FeedHealthView) int { return cmp.Compare(b.ItemsPerDayRate, a.ItemsPerDayRate) }
+	case HealthSortAdded:
+		compare = func(a, b FeedHealthView) int { return b.Feed.CreatedAt.Compare(a.Feed.CreatedAt) }
+	default:
+		return ""
+	}
+
+	slices.SortStableFunc(rows, compare)
+
+	return key
+}
+
+// BuildItemCategories splits an item's stored comma-joined categories into
+// chips that filter feedID down to items sharing each one.
+func BuildItemCategories(feedID int64, raw sql.NullString) []ItemCategory {
+	if !raw.Valid || raw.String == "" {
+		return nil
+	}
+
+	names := strings.Split(raw.String, ",")
+	categories := make([]ItemCategory, 0, len(names))
+
+	for _, name := range names {
+		categories = append(categories, ItemCategory{
+			Name:       name,
+			FilterPath: fmt.Sprintf("/feeds/%d/items?category=%s", feedID, url.QueryEscape(name)),
+		})
+	}
+
+	return categories
+}
+
+// BuildItemView builds an ItemView from item row values. Items created after
+// the feed's previous visit are flagged IsNew; nothing is new before the first visit.
+// Items the feed edited after they were first stored are flagged IsUpdated.
+// stripLeadingImage drops an image that opens the body before any text, and
+// imageReferrer is the feed's referrer policy for its proxied images.
+//
+//nolint:revive // Parameters map one-to-one onto the item row columns.
 func BuildItemView(
 	id int64,
 	title string,
@@ -58,28 +321,60 @@ func BuildItemView(
 	contentText sql.NullString,
 	published sql.NullTime,
 	readAt sql.NullTime,
+	createdAt time.Time,
+	lastUpdated sql.NullTime,
+	lastVisited sql.NullTime,
+	stripLeadingImage bool,
+	imageReferrer string,
 ) ItemView {
-	summaryHTML := pickSummaryHTML(summary, contentText, link)
+	body := itemBodyHTML(summary, contentText)
+	if stripLeadingImage {
+		body = content.StripLeadingImage(body)
+	}
+
+	summaryHTML := pickSummaryHTML(body, link, imageReferrer)
+	wordCount := content.WordCount(body)
 	publishedDisplay := "Unpublished"
 	publishedCompact := "na"
 
+	var publishedAt time.Time
+
 	if published.Valid {
+		publishedAt = published.Time.UTC()
 		publishedDisplay = FormatTime(published.Time)
 		publishedCompact = FormatRelativeShort(published.Time, time.Now())
 	}
 
 	return ItemView{
+		PublishedAt:      publishedAt,
+		UpdatedAt:        time.Time{},
 		ID:               id,
 		Title:            title,
 		Link:             link,
 		SummaryHTML:      summaryHTML,
 		PublishedDisplay: publishedDisplay,
 		PublishedCompact: publishedCompact,
+		ReadingTime:      FormatReadingTime(wordCount),
+		WordCount:        wordCount,
 		IsRead:           readAt.Valid,
+		IsNew:            lastVisited.Valid && createdAt.After(lastVisited.Time),
+		IsUpdated:        lastUpdated.Valid && lastUpdated.Time.After(createdAt),
 		IsActive:         false,
+		TranslateTo:      currentTranslationTarget(),
 	}
 }
 
+// UpdatedDisplay formats when the feed last updated an item, for showing
+// beside its published time. It is empty unless the update came more than a
+// minute after publishing, since many feeds stamp both at once.
+func UpdatedDisplay(published, updated sql.NullTime) string {
+	if !published.Valid || !updated.Valid || updated.Time.Sub(published.Time) <= time.Minute {
+		return ""
+	}
+
+	return FormatTime(updated.Time)
+}
+
 // FormatTime formats timestamps for expanded item display.
 func FormatTime(t time.Time) string {
 	return t.UTC().Format("Jan 2, 2006 - 3:04 PM")
@@ -107,20 +402,53 @@ func FormatRelativeShort(t, now time.Time) string {
 	}
 }
 
-//nolint:gosec // Summary HTML is rewritten/sanitized before rendering in templates.
-func pickSummaryHTML(summary, contentText sql.NullString, baseURL string) template.HTML {
-	text := ""
-	if contentText.Valid && strings.TrimSpace(contentText.String) != "" {
-		text = contentText.String
-	} else if summary.Valid && strings.TrimSpace(summary.String) != "" {
-		text = summary.String
+// FormatReadingTime estimates reading time for a word count as "N min read",
+// rounding up to whole minutes. Items without words get no estimate.
+func FormatReadingTime(wordCount int) string {
+	if wordCount <= 0 {
+		return ""
 	}
 
+	minutes := (wordCount + wordsPerMinute - 1) / wordsPerMinute
+
+	return strconv.Itoa(minutes) + " min read"
+}
+
+// ItemPreview returns a short plain-text preview of an item for the collapsed
+// item list, preferring the same source as the expanded summary.
+func ItemPreview(summary, contentText sql.NullString) string {
+	return content.Truncate(itemBodyHTML(summary, contentText), previewLength)
+}
+
+//nolint:gosec // Summary HTML is rewritten/sanitized before rendering in templates.
+func pickSummaryHTML(text, baseURL, imageReferrer string) template.HTML {
 	if text == "" {
 		text = "<p>No summary available.</p>"
 	}
 
-	text = content.RewriteSummaryHTML(text, baseURL)
+	text = content.RewriteSummaryHTML(text, baseURL, imageReferrer)
 
 	return template.HTML(text)
 }
+
+// itemBodyHTML picks the item's content over its summary, which is kept ahead
+// of the content only when content.DistinctLead finds it a short note of its
+// own rather than an excerpt. Atom XHTML bodies are unwrapped to plain HTML so
+// rewriting and previews see ordinary markup.
+func itemBodyHTML(summary, contentText sql.NullString) string {
+	summaryHTML := ""
+	if summary.Valid && strings.TrimSpace(summary.String) != "" {
+		summaryHTML = content.UnwrapXHTML(summary.String)
+	}
+
+	if !contentText.Valid || strings.TrimSpace(contentText.String) == "" {
+		return summaryHTML
+	}
+
+	body := content.UnwrapXHTML(contentText.String)
+	if summaryHTML == "" || !content.DistinctLead(summaryHTML, body) {
+		return body
+	}
+
+	return summaryHTML + "\n" + body
+}
@@ -1,29 +1,104 @@
 package view
 
-import "html/template"
+import (
+	"html/template"
+	"time"
+)
 
 // FeedView is template data for one feed in the feed list.
 type FeedView struct {
-	Title              string
-	OriginalTitle      string
-	URL                string
-	LastRefreshDisplay string
-	LastError          string
-	ID                 int64
-	ItemCount          int
-	UnreadCount        int
+	LastErrorAt             time.Time
+	CreatedAt               time.Time
+	Title                   string
+	OriginalTitle           string
+	URL                     string
+	URLDisplay              string
+	LastRefreshDisplay      string
+	UnreadDisplay           string
+	LastError               string
+	FailingSince            string
+	ErrorSeverity           string
+	ErrorHint               string
+	AddedDisplay            string
+	Description             string
+	SiteURL                 string
+	Language                string
+	UserAgent               string
+	HTTPProxy               string
+	IngestToken             string
+	ImageReferrer           string
+	Color                   string
+	Timezone                string
+	ShareToken              string
+	Tags                    []string
+	ID                      int64
+	ItemCount               int
+	UnreadCount             int
+	MinAgeMinutes           int
+	HTTPSOnly               bool
+	SuppressDuplicateTitles bool
+	SummarizeInList         bool
+	StripLeadingImage       bool
+	ImagesOnly              bool
+	CacheEnclosures         bool
+	SkipTombstones          bool
+	SecretURL               bool
+	StableGUIDs             bool
+	DigestItems             bool
+	Archived                bool
+	SortByUpdated           bool
 }
 
-// ItemView is template data for one feed item row.
+// FeedHealthView is template data for one row of the feed health table.
+type FeedHealthView struct {
+	LastCheckedAt     time.Time
+	LastContentAt     time.Time
+	NextRefreshAt     time.Time
+	LastChecked       string
+	LastContent       string
+	NextRefresh       string
+	ErrorCode         string
+	ItemsPerDay       string
+	Feed              FeedView
+	ItemsPerDayRate   float64
+	ConsecutiveErrors int
+}
+
+// ItemView is template data for one feed item row. TranslateTo is the
+// language the expanded item offers a translation into, empty when
+// translation is off; Translated marks a view showing that translation.
 type ItemView struct {
+	PublishedAt      time.Time
+	UpdatedAt        time.Time
 	Title            string
 	Link             string
 	SummaryHTML      template.HTML
+	Categories       []ItemCategory
+	Preview          string
+	Language         string
+	ThumbnailURL     string
+	TranslateTo      string
 	PublishedDisplay string
 	PublishedCompact string
+	UpdatedDisplay   string
+	ReadingTime      string
 	ID               int64
+	FeedID           int64
+	WordCount        int
 	IsRead           bool
+	IsNew            bool
+	IsUpdated        bool
 	IsActive         bool
+	SwapOOB          bool
+	HasEnclosure     bool
+	Translated       bool
+}
+
+// ItemCategory is one of an item's own categories, with the path that lists
+// the feed's items sharing it.
+type ItemCategory struct {
+	Name       string
+	FilterPath string
 }
 
 // NewItemsData is template data for the new-items banner.
@@ -33,10 +108,50 @@ type NewItemsData struct {
 	SwapOOB bool
 }
 
+// RefreshStatusData is template data for the placeholder shown while a feed
+// refreshes.
+type RefreshStatusData struct {
+	FeedID     int64
+	Refreshing bool
+}
+
+// ItemPageData points a "Load more" control at the page of a feed's items
+// after BeforeID; a zero BeforeID means no items remain.
+type ItemPageData struct {
+	FeedID   int64
+	BeforeID int64
+}
+
 // ItemListData is template data for a feed and its item list.
 type ItemListData struct {
-	Items    []ItemView
-	Feed     FeedView
-	NewItems NewItemsData
-	NewestID int64
+	Items              []ItemView
+	FetchSettingsError string
+	Category           string
+	Feed               FeedView
+	NewItems           NewItemsData
+	NextPage           ItemPageData
+	NewestID           int64
+	Refresh            RefreshStatusData
+	ExpandedItemID     int64
+}
+
+// TodayItemListData is template data for the unread items published since
+// local midnight, grouped by feed.
+type TodayItemListData struct {
+	Groups []FeedItemGroup
+	Count  int
+}
+
+// FeedItemGroup is one feed's items within a view that spans feeds.
+type FeedItemGroup struct {
+	Title  string
+	Items  []ItemView
+	FeedID int64
+}
+
+// TagItemListData is template data for the items of every feed with a tag.
+type TagItemListData struct {
+	Tag   string
+	Feeds []FeedView
+	Items []ItemView
 }
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"errors"
@@ -10,28 +11,42 @@ import (
 	"io/fs"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"rss/internal/content"
+	"rss/internal/feed"
 	"rss/internal/server"
 	"rss/internal/store"
+	"rss/internal/translate"
+	"rss/internal/view"
 )
 
 const (
-	serverReadTimeout  = 10 * time.Second
-	serverWriteTimeout = 10 * time.Second
-	serverIdleTimeout  = 60 * time.Second
-	authSessionTTL     = 24 * time.Hour
-	authChallengeTTL   = 5 * time.Minute
+	serverReadTimeout      = 10 * time.Second
+	serverWriteTimeout     = 10 * time.Second
+	serverIdleTimeout      = 60 * time.Second
+	authSessionTTL         = 24 * time.Hour
+	authChallengeTTL       = 5 * time.Minute
+	dbMaintenanceInterval  = 24 * time.Hour
+	enclosureCacheMaxMB    = 2048
+	bytesPerMB             = 1 << 20
+	maxPort                = 65535
+	unixAddrPrefix         = "unix:"
+	defaultTranslateTarget = "en"
 )
 
 var (
 	errAuthRPIDRequired      = errors.New("AUTH_RP_ID is required when AUTH_ENABLED=true")
 	errAuthRPOriginRequired  = errors.New("AUTH_RP_ORIGIN is required when AUTH_ENABLED=true")
 	errAuthSetupTokenMissing = errors.New("AUTH_SETUP_TOKEN is required when AUTH_ENABLED=true")
+	errTranslateURLInvalid   = errors.New("TRANSLATE_URL must be an http or https URL")
+	errTranslateTargetBad    = errors.New("TRANSLATE_TARGET must be a language code such as en or pt-BR")
 )
 
 //go:embed templates/*.html templates/partials/*.html
@@ -71,13 +86,15 @@ func run() error {
 		return err
 	}
 
+	defer app.Close()
+
 	app.StartBackgroundLoops()
 
 	return serve(app)
 }
 
 func openInitializedDB(path string) (*sql.DB, error) {
-	db, err := store.Open(path)
+	db, err := store.OpenWithPragmas(path, resolveSQLitePragmas())
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -87,6 +104,24 @@ func openInitializedDB(path string) (*sql.DB, error) {
 		return nil, fmt.Errorf("initialize database: %w", err)
 	}
 
+	err = store.SetTombstoneRetention(context.Background(), db, resolveTombstoneRetention())
+	if err != nil {
+		return nil, fmt.Errorf("configure tombstone retention: %w", err)
+	}
+
+	// The conversion rewrites the whole database, so it only runs when asked
+	// for and before the server starts taking requests.
+	if envBool("DB_CONVERT_AUTO_VACUUM") {
+		converted, convertErr := store.ConvertToIncrementalVacuum(context.Background(), db)
+		if convertErr != nil {
+			return nil, fmt.Errorf("convert database to incremental auto_vacuum: %w", convertErr)
+		}
+
+		if converted {
+			slog.Info("database converted to incremental auto_vacuum")
+		}
+	}
+
 	return db, nil
 }
 
@@ -98,8 +133,36 @@ func closeDB(db *sql.DB) {
 }
 
 func configureApp(db *sql.DB, tmpl *template.Template, staticFS fs.FS) (*server.App, error) {
+	view.SetUnreadBadgeCap(resolveUnreadBadgeCap())
+	store.SetMaxFeeds(resolveMaxFeeds())
+	content.SetImageProxySecret(strings.TrimSpace(os.Getenv("IMAGE_PROXY_SECRET")))
+
 	app := server.New(db, tmpl)
 	app.SetStaticFS(staticFS)
+	app.SetKeepHistoryOnDelete(resolveKeepHistoryOnDelete())
+	app.SetKioskEnabled(resolveKioskEnabled())
+	app.SetImageHTTPSUpgrade(envBool("IMAGE_PROXY_HTTPS_UPGRADE"))
+	app.SetMaintenanceInterval(resolveMaintenanceInterval())
+	app.SetRequestLogLevel(resolveRequestLogLevel())
+	app.SetLocation(resolveLocation())
+	app.SetSubscribeTimeout(envDuration("SUBSCRIBE_TIMEOUT", 0))
+	app.SetActiveItemRetention(envDuration("ACTIVE_ITEM_RETENTION", 0))
+	app.SetItemPageSize(int(envInt64("ITEM_PAGE_SIZE", store.DefaultItemPageSize)))
+
+	enclosureCache, err := resolveEnclosureCache()
+	if err != nil {
+		return nil, err
+	}
+
+	app.SetEnclosureCache(enclosureCache)
+
+	translator, translateTarget, err := resolveTranslator()
+	if err != nil {
+		return nil, err
+	}
+
+	app.SetTranslator(translator)
+	view.SetTranslationTarget(translateTarget)
 
 	authCfg, err := resolveAuthConfig()
 	if err != nil {
@@ -115,16 +178,23 @@ func configureApp(db *sql.DB, tmpl *template.Template, staticFS fs.FS) (*server.
 }
 
 func serve(app *server.App) error {
+	network, addr := resolveListenAddr()
+
+	listener, err := listen(network, addr)
+	if err != nil {
+		return err
+	}
+
 	httpServer := new(http.Server)
-	httpServer.Addr = resolveAddr()
+	httpServer.Addr = addr
 	httpServer.Handler = app.Routes()
 	httpServer.ReadTimeout = serverReadTimeout
 	httpServer.WriteTimeout = serverWriteTimeout
 	httpServer.IdleTimeout = serverIdleTimeout
 
-	slog.Info("rss reader running", "addr", httpServer.Addr)
+	slog.Info("rss reader running", "network", network, "addr", addr)
 
-	err := httpServer.ListenAndServe()
+	err = httpServer.Serve(listener)
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("serve http: %w", err)
 	}
@@ -143,11 +213,17 @@ func setupLogging() {
 }
 
 func resolveLogLevel() slog.Level {
-	const defaultLevel = slog.LevelInfo
+	return envLogLevel("LOG_LEVEL", slog.LevelInfo)
+}
+
+func resolveRequestLogLevel() slog.Level {
+	return envLogLevel("REQUEST_LOG_LEVEL", slog.LevelInfo)
+}
 
-	raw := strings.TrimSpace(os.Getenv("LOG_LEVEL"))
+func envLogLevel(name string, fallback slog.Level) slog.Level {
+	raw := strings.TrimSpace(os.Getenv(name))
 	if raw == "" {
-		return defaultLevel
+		return fallback
 	}
 
 	normalized := strings.ToLower(raw)
@@ -159,14 +235,70 @@ func resolveLogLevel() slog.Level {
 
 	err := level.UnmarshalText([]byte(normalized))
 	if err != nil {
-		log.Printf("invalid LOG_LEVEL value; defaulting to %s", defaultLevel)
+		log.Printf("invalid %s value; defaulting to %s", name, fallback)
 
-		return defaultLevel
+		return fallback
 	}
 
 	return level
 }
 
+// listen opens the server's listener. A Unix socket left behind by an earlier
+// run is removed first; any other file at that path is left alone, so Listen
+// reports it.
+func listen(network, addr string) (net.Listener, error) {
+	if network == "unix" {
+		info, err := os.Lstat(addr)
+		if err == nil && info.Mode()&os.ModeSocket != 0 {
+			err = os.Remove(addr)
+			if err != nil {
+				return nil, fmt.Errorf("remove stale socket %s: %w", addr, err)
+			}
+		}
+	}
+
+	listener, err := new(net.ListenConfig).Listen(context.Background(), network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s %s: %w", network, addr, err)
+	}
+
+	return listener, nil
+}
+
+// resolveListenAddr returns the network and address to listen on. ADDR takes
+// a host:port, such as 0.0.0.0:8080 or [::1]:8080, or unix:/path/to.sock for
+// a Unix socket; when it is unset, PORT picks a port on loopback.
+func resolveListenAddr() (string, string) {
+	raw := strings.TrimSpace(os.Getenv("ADDR"))
+	if raw == "" {
+		return "tcp", resolveAddr()
+	}
+
+	if path, ok := strings.CutPrefix(raw, unixAddrPrefix); ok {
+		if path != "" {
+			return "unix", path
+		}
+	} else if validTCPAddr(raw) {
+		return "tcp", raw
+	}
+
+	addr := resolveAddr()
+	log.Printf("invalid ADDR value; defaulting to %s", addr)
+
+	return "tcp", addr
+}
+
+func validTCPAddr(addr string) bool {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	number, err := strconv.Atoi(port)
+
+	return err == nil && number > 0 && number <= maxPort
+}
+
 func resolveAddr() string {
 	port := strings.TrimSpace(os.Getenv("PORT"))
 	if port == "" {
@@ -194,6 +326,150 @@ func resolveDBPath() string {
 	return path
 }
 
+func resolveSQLitePragmas() store.Pragmas {
+	pragmas := store.DefaultPragmas()
+
+	if raw := strings.TrimSpace(os.Getenv("SQLITE_SYNCHRONOUS")); raw != "" {
+		pragmas.Synchronous = raw
+	}
+
+	pragmas.CacheSizeKiB = envInt64("SQLITE_CACHE_SIZE_KB", pragmas.CacheSizeKiB)
+	pragmas.MmapSizeBytes = envInt64("SQLITE_MMAP_SIZE", pragmas.MmapSizeBytes)
+
+	return pragmas
+}
+
+func envInt64(name string, fallback int64) int64 {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || parsed < 0 {
+		log.Printf("invalid %s value; defaulting to %d", name, fallback)
+
+		return fallback
+	}
+
+	return parsed
+}
+
+func resolveUnreadBadgeCap() int {
+	return int(envInt64("UNREAD_BADGE_CAP", view.DefaultUnreadBadgeCap))
+}
+
+// resolveMaxFeeds returns the MAX_FEEDS cap on subscriptions; 0, the
+// default, leaves them uncapped.
+func resolveMaxFeeds() int {
+	return int(envInt64("MAX_FEEDS", 0))
+}
+
+// resolveLocation returns the IANA time zone named by TIMEZONE, falling back
+// to the process's local time zone when unset or unknown.
+func resolveLocation() *time.Location {
+	raw := strings.TrimSpace(os.Getenv("TIMEZONE"))
+	if raw == "" {
+		return time.Local
+	}
+
+	location, err := time.LoadLocation(raw)
+	if err != nil {
+		log.Printf("invalid TIMEZONE value; defaulting to %s", time.Local)
+
+		return time.Local
+	}
+
+	return location
+}
+
+func resolveKeepHistoryOnDelete() bool {
+	if strings.TrimSpace(os.Getenv("KEEP_HISTORY_ON_DELETE")) == "" {
+		return false
+	}
+
+	return envBool("KEEP_HISTORY_ON_DELETE")
+}
+
+// resolveKioskEnabled reads KIOSK_ENABLED, which is off unless set.
+func resolveKioskEnabled() bool {
+	if strings.TrimSpace(os.Getenv("KIOSK_ENABLED")) == "" {
+		return false
+	}
+
+	return envBool("KIOSK_ENABLED")
+}
+
+// resolveEnclosureCache returns the cache feeds can opt in to for offline
+// enclosures, or nil when ENCLOSURE_CACHE_DIR is unset.
+func resolveEnclosureCache() (*feed.EnclosureCache, error) {
+	dir := strings.TrimSpace(os.Getenv("ENCLOSURE_CACHE_DIR"))
+	if dir == "" {
+		return nil, nil //nolint:nilnil // No directory means caching is off, which is not an error.
+	}
+
+	maxBytes := envInt64("ENCLOSURE_CACHE_MAX_MB", enclosureCacheMaxMB) * bytesPerMB
+
+	cache, err := feed.NewEnclosureCache(dir, maxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("configure enclosure cache: %w", err)
+	}
+
+	return cache, nil
+}
+
+// resolveTranslator returns the client for TRANSLATE_URL, a
+// LibreTranslate-compatible endpoint, and the language items are translated
+// into, or nil and "" when TRANSLATE_URL is unset.
+func resolveTranslator() (*translate.Client, string, error) {
+	endpoint := strings.TrimSpace(os.Getenv("TRANSLATE_URL"))
+	if endpoint == "" {
+		return nil, "", nil
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, "", errTranslateURLInvalid
+	}
+
+	target := strings.TrimSpace(os.Getenv("TRANSLATE_TARGET"))
+	if target == "" {
+		target = defaultTranslateTarget
+	}
+
+	if !translate.ValidLanguage(target) {
+		return nil, "", fmt.Errorf("%w: %q", errTranslateTargetBad, target)
+	}
+
+	return translate.NewClient(endpoint, strings.TrimSpace(os.Getenv("TRANSLATE_API_KEY"))), target, nil
+}
+
+func resolveMaintenanceInterval() time.Duration {
+	raw := strings.ToLower(strings.TrimSpace(os.Getenv("DB_MAINTENANCE_INTERVAL")))
+	switch raw {
+	case "":
+		return dbMaintenanceInterval
+	case "0", "off", "false":
+		return 0
+	default:
+		return envDuration("DB_MAINTENANCE_INTERVAL", dbMaintenanceInterval)
+	}
+}
+
+// resolveTombstoneRetention reads how long removed items stay tombstoned;
+// "off" or "0" keeps no tombstones, so removed items can return.
+func resolveTombstoneRetention() time.Duration {
+	raw := strings.ToLower(strings.TrimSpace(os.Getenv("TOMBSTONE_RETENTION")))
+	switch raw {
+	case "":
+		return store.DefaultTombstoneRetention
+	case "0", "off", "false":
+		return 0
+	default:
+		return envDuration("TOMBSTONE_RETENTION", store.DefaultTombstoneRetention)
+	}
+}
+
 func envBool(name string) bool {
 	raw := strings.ToLower(strings.TrimSpace(os.Getenv(name)))
 	switch raw {
@@ -210,7 +486,7 @@ func resolveAuthConfig() (server.AuthConfig, error) {
 	cfg := server.AuthConfig{
 		Enabled:      enabled,
 		RPID:         strings.TrimSpace(os.Getenv("AUTH_RP_ID")),
-		RPOrigin:     strings.TrimSpace(os.Getenv("AUTH_RP_ORIGIN")),
+		RPOrigins:    splitAuthOrigins(os.Getenv("AUTH_RP_ORIGIN")),
 		RPName:       strings.TrimSpace(os.Getenv("AUTH_RP_NAME")),
 		SetupToken:   strings.TrimSpace(os.Getenv("AUTH_SETUP_TOKEN")),
 		SessionTTL:   envDuration("AUTH_SESSION_TTL", authSessionTTL),
@@ -237,7 +513,7 @@ func resolveAuthConfig() (server.AuthConfig, error) {
 		return server.AuthConfig{}, errAuthRPIDRequired
 	}
 
-	if cfg.RPOrigin == "" {
+	if len(cfg.RPOrigins) == 0 {
 		return server.AuthConfig{}, errAuthRPOriginRequired
 	}
 
@@ -248,6 +524,20 @@ func resolveAuthConfig() (server.AuthConfig, error) {
 	return cfg, nil
 }
 
+// splitAuthOrigins reads AUTH_RP_ORIGIN as a comma-separated list, so one
+// instance can be reached at several hostnames under the same RP ID.
+func splitAuthOrigins(raw string) []string {
+	var origins []string
+
+	for origin := range strings.SplitSeq(raw, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return origins
+}
+
 func envDuration(name string, fallback time.Duration) time.Duration {
 	raw := strings.TrimSpace(os.Getenv(name))
 	if raw == "" {
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"errors"
 	"log/slog"
+	"net"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
+
+	"rss/internal/store"
+	"rss/internal/view"
 )
 
 func TestResolveAuthConfigDefaultsToSecureAuthSettings(t *testing.T) {
@@ -51,6 +58,95 @@ func TestResolveAuthConfigAllowsExplicitInsecureCookieOverride(t *testing.T) {
 	}
 }
 
+func TestResolveAuthConfigAcceptsSeveralOrigins(t *testing.T) {
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("AUTH_RP_ID", "example.com")
+	t.Setenv("AUTH_RP_ORIGIN", "https://rss.example.com, https://rss.lan.example.com,")
+	t.Setenv("AUTH_SETUP_TOKEN", "setup-token")
+
+	cfg, err := resolveAuthConfig()
+	if err != nil {
+		t.Fatalf("resolveAuthConfig: %v", err)
+	}
+
+	if !slices.Equal(cfg.RPOrigins, []string{"https://rss.example.com", "https://rss.lan.example.com"}) {
+		t.Fatalf("expected both origins, got %v", cfg.RPOrigins)
+	}
+}
+
+func TestResolveKeepHistoryOnDeleteDefaultsOff(t *testing.T) {
+	t.Setenv("KEEP_HISTORY_ON_DELETE", "")
+
+	if resolveKeepHistoryOnDelete() {
+		t.Fatal("expected history to be discarded on delete by default")
+	}
+
+	t.Setenv("KEEP_HISTORY_ON_DELETE", "true")
+
+	if !resolveKeepHistoryOnDelete() {
+		t.Fatal("expected KEEP_HISTORY_ON_DELETE=true to keep history")
+	}
+}
+
+func TestResolveMaintenanceInterval(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"":        dbMaintenanceInterval,
+		"6h":      6 * time.Hour,
+		"off":     0,
+		"0":       0,
+		"garbage": dbMaintenanceInterval,
+	}
+
+	for raw, want := range testCases {
+		t.Setenv("DB_MAINTENANCE_INTERVAL", raw)
+
+		if got := resolveMaintenanceInterval(); got != want {
+			t.Fatalf("DB_MAINTENANCE_INTERVAL=%q: got %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestResolveTombstoneRetention(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"":        store.DefaultTombstoneRetention,
+		"168h":    7 * 24 * time.Hour,
+		"off":     0,
+		"0":       0,
+		"garbage": store.DefaultTombstoneRetention,
+	}
+
+	for raw, want := range testCases {
+		t.Setenv("TOMBSTONE_RETENTION", raw)
+
+		if got := resolveTombstoneRetention(); got != want {
+			t.Fatalf("TOMBSTONE_RETENTION=%q: got %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestResolveSQLitePragmas(t *testing.T) {
+	t.Setenv("SQLITE_SYNCHRONOUS", "")
+	t.Setenv("SQLITE_CACHE_SIZE_KB", "")
+	t.Setenv("SQLITE_MMAP_SIZE", "")
+
+	if got := resolveSQLitePragmas(); got != store.DefaultPragmas() {
+		t.Fatalf("expected default pragmas, got %+v", got)
+	}
+
+	t.Setenv("SQLITE_SYNCHRONOUS", "FULL")
+	t.Setenv("SQLITE_CACHE_SIZE_KB", "2048")
+	t.Setenv("SQLITE_MMAP_SIZE", "not-a-number")
+
+	got := resolveSQLitePragmas()
+	if got.Synchronous != "FULL" || got.CacheSizeKiB != 2048 {
+		t.Fatalf("expected overrides to apply, got %+v", got)
+	}
+
+	if got.MmapSizeBytes != store.DefaultPragmas().MmapSizeBytes {
+		t.Fatalf("expected invalid mmap size to fall back, got %d", got.MmapSizeBytes)
+	}
+}
+
 func TestResolveLogLevel(t *testing.T) {
 	t.Setenv("LOG_LEVEL", "")
 
@@ -88,6 +184,68 @@ func TestResolveLogLevelInvalidValueFallsBackToInfo(t *testing.T) {
 	}
 }
 
+func TestResolveRequestLogLevel(t *testing.T) {
+	t.Setenv("REQUEST_LOG_LEVEL", "")
+
+	if got := resolveRequestLogLevel(); got != slog.LevelInfo {
+		t.Fatalf("expected default info level, got %s", got)
+	}
+
+	t.Setenv("REQUEST_LOG_LEVEL", "debug")
+
+	if got := resolveRequestLogLevel(); got != slog.LevelDebug {
+		t.Fatalf("expected REQUEST_LOG_LEVEL=debug to quiet access logs, got %s", got)
+	}
+}
+
+func TestResolveUnreadBadgeCap(t *testing.T) {
+	t.Setenv("UNREAD_BADGE_CAP", "")
+
+	if got := resolveUnreadBadgeCap(); got != view.DefaultUnreadBadgeCap {
+		t.Fatalf("expected default cap, got %d", got)
+	}
+
+	t.Setenv("UNREAD_BADGE_CAP", "99")
+
+	if got := resolveUnreadBadgeCap(); got != 99 {
+		t.Fatalf("expected UNREAD_BADGE_CAP=99, got %d", got)
+	}
+}
+
+func TestResolveMaxFeeds(t *testing.T) {
+	t.Setenv("MAX_FEEDS", "")
+
+	if got := resolveMaxFeeds(); got != 0 {
+		t.Fatalf("expected no cap by default, got %d", got)
+	}
+
+	t.Setenv("MAX_FEEDS", "50")
+
+	if got := resolveMaxFeeds(); got != 50 {
+		t.Fatalf("expected MAX_FEEDS=50, got %d", got)
+	}
+}
+
+func TestResolveLocation(t *testing.T) {
+	t.Setenv("TIMEZONE", "")
+
+	if got := resolveLocation(); got != time.Local {
+		t.Fatalf("expected local time zone by default, got %s", got)
+	}
+
+	t.Setenv("TIMEZONE", "America/New_York")
+
+	if got := resolveLocation(); got.String() != "America/New_York" {
+		t.Fatalf("expected TIMEZONE=America/New_York, got %s", got)
+	}
+
+	t.Setenv("TIMEZONE", "Not/AZone")
+
+	if got := resolveLocation(); got != time.Local {
+		t.Fatalf("expected unknown zone to fall back to local, got %s", got)
+	}
+}
+
 func TestResolveDBPath(t *testing.T) {
 	t.Run("defaults to rss.db when unset", func(t *testing.T) {
 		t.Setenv("DB_PATH", "")
@@ -113,3 +271,104 @@ func TestResolveDBPath(t *testing.T) {
 		}
 	})
 }
+
+func TestResolveListenAddr(t *testing.T) {
+	cases := []struct {
+		name        string
+		addr        string
+		port        string
+		wantNetwork string
+		wantAddr    string
+	}{
+		{name: "defaults to loopback", addr: "", port: "", wantNetwork: "tcp", wantAddr: "127.0.0.1:8080"},
+		{name: "falls back to PORT", addr: "", port: "9090", wantNetwork: "tcp", wantAddr: "127.0.0.1:9090"},
+		{name: "takes host and port", addr: "0.0.0.0:8081", port: "9090", wantNetwork: "tcp", wantAddr: "0.0.0.0:8081"},
+		{name: "takes IPv6 host", addr: "[::1]:8081", port: "", wantNetwork: "tcp", wantAddr: "[::1]:8081"},
+		{name: "takes bare port", addr: ":8081", port: "", wantNetwork: "tcp", wantAddr: ":8081"},
+		{name: "takes unix socket", addr: "unix:/run/pulse.sock", port: "", wantNetwork: "unix", wantAddr: "/run/pulse.sock"},
+		{name: "rejects missing port", addr: "localhost", port: "9090", wantNetwork: "tcp", wantAddr: "127.0.0.1:9090"},
+		{name: "rejects bad port", addr: "localhost:http", port: "", wantNetwork: "tcp", wantAddr: "127.0.0.1:8080"},
+		{name: "rejects empty socket path", addr: "unix:", port: "", wantNetwork: "tcp", wantAddr: "127.0.0.1:8080"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("ADDR", tc.addr)
+			t.Setenv("PORT", tc.port)
+
+			network, addr := resolveListenAddr()
+			if network != tc.wantNetwork || addr != tc.wantAddr {
+				t.Fatalf("expected %s %s, got %s %s", tc.wantNetwork, tc.wantAddr, network, addr)
+			}
+		})
+	}
+}
+
+func TestListenReplacesStaleUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pulse.sock")
+
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen on stale socket: %v", err)
+	}
+
+	unixListener, ok := stale.(*net.UnixListener)
+	if !ok {
+		t.Fatalf("expected a unix listener, got %T", stale)
+	}
+
+	unixListener.SetUnlinkOnClose(false)
+
+	err = stale.Close()
+	if err != nil {
+		t.Fatalf("close stale socket: %v", err)
+	}
+
+	listener, err := listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen over stale socket: %v", err)
+	}
+
+	err = listener.Close()
+	if err != nil {
+		t.Fatalf("close listener: %v", err)
+	}
+}
+
+func TestResolveTranslator(t *testing.T) {
+	t.Run("off when unset", func(t *testing.T) {
+		t.Setenv("TRANSLATE_URL", "")
+
+		client, target, err := resolveTranslator()
+		if err != nil || client != nil || target != "" {
+			t.Fatalf("expected translation off, got %v %q %v", client, target, err)
+		}
+	})
+
+	t.Run("defaults the target to English", func(t *testing.T) {
+		t.Setenv("TRANSLATE_URL", "http://127.0.0.1:5000/translate")
+		t.Setenv("TRANSLATE_TARGET", "")
+
+		client, target, err := resolveTranslator()
+		if err != nil || client == nil || target != "en" {
+			t.Fatalf("expected a client translating into en, got %v %q %v", client, target, err)
+		}
+	})
+
+	t.Run("rejects bad settings", func(t *testing.T) {
+		t.Setenv("TRANSLATE_URL", "ftp://example.com/translate")
+
+		_, _, err := resolveTranslator()
+		if !errors.Is(err, errTranslateURLInvalid) {
+			t.Fatalf("expected invalid URL error, got %v", err)
+		}
+
+		t.Setenv("TRANSLATE_URL", "https://example.com/translate")
+		t.Setenv("TRANSLATE_TARGET", "English")
+
+		_, _, err = resolveTranslator()
+		if !errors.Is(err, errTranslateTargetBad) {
+			t.Fatalf("expected invalid target error, got %v", err)
+		}
+	})
+}
@@ -27,6 +27,47 @@
     return (meta.getAttribute("content") || "").trim();
   };
 
+  // Fixed alternates that stay bound whatever the saved shortcut map says.
+  const fixedShortcutActions = {
+    arrowdown: "next_item",
+    arrowup: "previous_item",
+    arrowright: "expand_item",
+    arrowleft: "collapse_item",
+    enter: "open_article",
+  };
+
+  // readShortcutKeys inverts the server-provided action -> key map so key
+  // events can be looked up directly.
+  const readShortcutKeys = () => {
+    const keys = {};
+    const config = document.getElementById("shortcut-config");
+    if (!config) {
+      return keys;
+    }
+    try {
+      const actions = JSON.parse(config.textContent || "{}");
+      Object.keys(actions || {}).forEach((action) => {
+        keys[String(actions[action]).toLowerCase()] = action;
+      });
+    } catch (_err) {
+      return {};
+    }
+    return keys;
+  };
+
+  let shortcutKeys = null;
+  const shortcutAction = (key) => {
+    if (Object.prototype.hasOwnProperty.call(fixedShortcutActions, key)) {
+      return fixedShortcutActions[key];
+    }
+    if (!shortcutKeys) {
+      shortcutKeys = readShortcutKeys();
+    }
+    return Object.prototype.hasOwnProperty.call(shortcutKeys, key)
+      ? shortcutKeys[key]
+      : "";
+  };
+
   const isTopbarShortcutsOpen = () => {
     const button = getTopbarShortcutsButton();
     return Boolean(button && button.getAttribute("aria-expanded") === "true");
@@ -89,6 +130,9 @@
       if (!event || !event.detail || !event.detail.successful) {
         return;
       }
+      if (event.detail.elt !== form) {
+        return;
+      }
       form.reset();
     });
   };
@@ -353,6 +397,11 @@
     return Boolean(elt && elt.id === pending.sourceId);
   };
 
+  const isReadNextSwap = (event) => {
+    const config = event && event.detail ? event.detail.requestConfig : null;
+    return Boolean(config && /\/read-next$/.test(config.path || ""));
+  };
+
   const openActiveLink = () => {
     const current = ensureActive();
     if (!current) {
@@ -398,6 +447,138 @@
     }
   };
 
+  // markReadAbove marks the active item and everything listed above it read,
+  // replacing the list with the server's re-render.
+  const markReadAbove = () => {
+    const current = ensureActive();
+    const list = getItemList();
+    const itemID = current ? cardItemID(current) : null;
+    if (!itemID || !list || typeof htmx === "undefined" || !htmx.ajax) {
+      return;
+    }
+    htmx.ajax("POST", `/feeds/${list.dataset.feedId}/items/read-above`, {
+      target: list.closest("section"),
+      swap: "outerHTML",
+      values: { item_id: itemID },
+    });
+  };
+
+  // Unread items scrolled past in the open feed, collected while "mark read
+  // on leave" is on and marked read as the reader moves to another feed.
+  const seenState = {
+    list: null,
+    observer: null,
+    ids: new Set(),
+  };
+
+  const isMarkReadOnLeaveEnabled = () =>
+    document.body.dataset.markReadOnLeave === "true";
+
+  // seenItemsRoot returns the pane the item list scrolls in, or null when the
+  // whole page scrolls, as it does on narrow screens.
+  const seenItemsRoot = (list) => {
+    const pane = list.closest(".content-pane");
+    if (!pane || window.getComputedStyle(pane).overflowY !== "auto") {
+      return null;
+    }
+    return pane;
+  };
+
+  // observeSeenItems watches the current list's unread cards and records each
+  // one whose bottom scrolls above the top of the view. A new list, such as
+  // another feed's, starts a new record.
+  const observeSeenItems = () => {
+    const list = getItemList();
+    if (!list || !isMarkReadOnLeaveEnabled()) {
+      return;
+    }
+    if (typeof IntersectionObserver === "undefined") {
+      return;
+    }
+    if (seenState.list !== list) {
+      if (seenState.observer) {
+        seenState.observer.disconnect();
+      }
+      seenState.list = list;
+      seenState.ids = new Set();
+      seenState.observer = new IntersectionObserver((entries) => {
+        entries.forEach((entry) => {
+          const bounds = entry.rootBounds;
+          if (entry.isIntersecting || !bounds || !entry.target.isConnected) {
+            return;
+          }
+          if (entry.boundingClientRect.bottom > bounds.top) {
+            return;
+          }
+          const itemID = cardItemID(entry.target);
+          if (itemID) {
+            seenState.ids.add(itemID);
+          }
+        });
+      }, { root: seenItemsRoot(list) });
+    }
+    list.querySelectorAll(".item-card:not(.is-read)").forEach((card) => {
+      if (card.dataset.seenObserved === "true") {
+        return;
+      }
+      card.dataset.seenObserved = "true";
+      seenState.observer.observe(card);
+    });
+  };
+
+  // takeSeenItems returns the feed ID and item IDs to mark read when a
+  // request replaces the main content, or null when there are none or the
+  // request reopens the same feed. The record is cleared either way.
+  const takeSeenItems = (detail) => {
+    const list = seenState.list;
+    if (!list || !seenState.ids.size || !isMarkReadOnLeaveEnabled()) {
+      return null;
+    }
+    if (!detail.target || detail.target.id !== "main-content") {
+      return null;
+    }
+    const elt = detail.elt;
+    if (elt && elt.dataset && elt.dataset.feedId === list.dataset.feedId) {
+      return null;
+    }
+    const seen = {
+      feedID: list.dataset.feedId,
+      itemIDs: Array.from(seenState.ids),
+    };
+    seenState.ids = new Set();
+    return seen;
+  };
+
+  const postSeenItemsRead = (seen) => {
+    const body = new URLSearchParams();
+    seen.itemIDs.forEach((itemID) => {
+      body.append("item_id", itemID);
+    });
+    const headers = {};
+    const csrfToken = getCSRFToken();
+    if (csrfToken) {
+      headers["X-CSRF-Token"] = csrfToken;
+    }
+    return fetch(`/feeds/${seen.feedID}/items/read-seen`, {
+      method: "POST",
+      headers,
+      credentials: "same-origin",
+      body,
+    });
+  };
+
+  const openNextUnreadFeed = () => {
+    if (typeof htmx === "undefined" || !htmx.ajax) {
+      return;
+    }
+    const selectedFeedInput = getSelectedFeedInput();
+    const after = selectedFeedInput ? selectedFeedInput.value : "";
+    htmx.ajax("GET", `/feeds/next-unread?after=${encodeURIComponent(after)}`, {
+      target: "#main-content",
+      swap: "innerHTML",
+    });
+  };
+
   const shouldIgnore = (event) => {
     if (event.defaultPrevented) {
       return true;
@@ -559,6 +740,17 @@
     }
   });
 
+  document.addEventListener("click", (event) => {
+    const dismissButton = event.target.closest("[data-error-dismiss]");
+    if (!dismissButton) {
+      return;
+    }
+    const region = document.getElementById("app-error");
+    if (region) {
+      region.replaceChildren();
+    }
+  });
+
   document.addEventListener("click", (event) => {
     const feedButton = event.target.closest(".feed-link");
     if (!feedButton) {
@@ -694,48 +886,50 @@
       event.preventDefault();
     };
 
-    switch (key) {
-      case "j":
-      case "arrowdown":
+    if (key === "enter") {
+      const main = document.getElementById("main-content");
+      if (
+        main &&
+        event.target &&
+        event.target !== document.body &&
+        !main.contains(event.target)
+      ) {
+        return;
+      }
+    }
+
+    switch (shortcutAction(key)) {
+      case "next_item":
         prevent();
         moveActive(1);
         break;
-      case "k":
-      case "arrowup":
+      case "previous_item":
         prevent();
         moveActive(-1);
         break;
-      case "l":
-      case "arrowright":
+      case "expand_item":
         prevent();
         toggleExpanded(true);
         break;
-      case "h":
-      case "arrowleft":
+      case "collapse_item":
         prevent();
         toggleExpanded(false);
         break;
-      case "o":
+      case "open_article":
         prevent();
         openActiveLink();
         break;
-      case "enter": {
-        const main = document.getElementById("main-content");
-        if (
-          main &&
-          event.target &&
-          event.target !== document.body &&
-          !main.contains(event.target)
-        ) {
-          break;
-        }
+      case "toggle_read":
         prevent();
-        openActiveLink();
+        toggleRead();
         break;
-      }
-      case "r":
+      case "next_unread_feed":
         prevent();
-        toggleRead();
+        openNextUnreadFeed();
+        break;
+      case "mark_read_above":
+        prevent();
+        markReadAbove();
         break;
       default:
         break;
@@ -749,15 +943,37 @@
     bindItemCardClickGuards();
     syncTopbarShortcuts();
     syncFeedDeleteMarks();
+    observeSeenItems();
     if (isFeedEditMode()) {
       focusFeedEditTitleInput();
       return;
     }
+    const linkedItem = document.querySelector("#item-list .item-card.is-active");
+    if (linkedItem) {
+      setActive(linkedItem, { scroll: true });
+    }
     ensureActive();
     focusItemList();
   });
 
+  // Failed requests answer with an error partial retargeted to #app-error;
+  // htmx skips swapping error responses unless told otherwise.
+  document.body.addEventListener("htmx:beforeSwap", (event) => {
+    const detail = event && event.detail ? event.detail : null;
+    if (!detail || !detail.xhr || detail.xhr.status < 400) {
+      return;
+    }
+    if (detail.target && detail.target.id === "app-error") {
+      detail.shouldSwap = true;
+      detail.isError = false;
+    }
+  });
+
   document.body.addEventListener("htmx:afterSwap", (event) => {
+    const errorTarget = event && event.detail ? event.detail.target : null;
+    if (errorTarget && errorTarget.id === "app-error") {
+      return;
+    }
     clearFeedDragState();
     bindTopbarShortcuts();
     bindSubscribeForm();
@@ -765,6 +981,7 @@
     bindItemCardClickGuards();
     syncTopbarShortcuts();
     syncFeedDeleteMarks();
+    observeSeenItems();
     const swapTarget = event && event.detail ? event.detail.target : null;
     if (swapTarget && swapTarget.id === "feed-list" && isFeedEditMode()) {
       focusFeedEditTitleInput();
@@ -776,6 +993,14 @@
         isPendingReadSwap(event, state.pendingReadShortcut)
       ) {
         applyPendingReadShortcut();
+      } else if (isReadNextSwap(event)) {
+        const list = getItemList();
+        const next = list.querySelector(".item-card.is-active");
+        if (next) {
+          setActive(next, { scroll: true });
+        } else {
+          ensureActive();
+        }
       } else {
         ensureActive();
       }
@@ -786,6 +1011,26 @@
     }
   });
 
+  // Leaving a feed holds the request that replaces it until the items
+  // scrolled past in it are marked read, so the next feed's response already
+  // counts them. A failed mark still lets the reader move on.
+  document.body.addEventListener("htmx:confirm", (event) => {
+    const detail = event ? event.detail : null;
+    if (!detail || typeof detail.issueRequest !== "function") {
+      return;
+    }
+    const seen = takeSeenItems(detail);
+    if (!seen) {
+      return;
+    }
+    event.preventDefault();
+    postSeenItemsRead(seen)
+      .catch(() => null)
+      .finally(() => {
+        detail.issueRequest(true);
+      });
+  });
+
   document.body.addEventListener("htmx:configRequest", (event) => {
     if (!event || !event.detail || !event.detail.parameters) {
       return;
@@ -804,11 +1049,18 @@
       if (sourceCard && sourceCard.id) {
         event.detail.parameters.selected_item_id = sourceCard.id;
         state.activeId = sourceCard.id;
-        return;
-      }
-      if (state.activeId) {
+      } else if (state.activeId) {
         event.detail.parameters.selected_item_id = state.activeId;
       }
     }
+    // Reporting the selected item on every request, polls included, keeps
+    // the server's read cleanup from deleting it while it is open.
+    const activeItem = event.detail.parameters.selected_item_id;
+    if (activeItem) {
+      if (!event.detail.headers) {
+        event.detail.headers = {};
+      }
+      event.detail.headers["X-Active-Item"] = String(activeItem);
+    }
   });
 })();